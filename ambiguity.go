@@ -0,0 +1,56 @@
+package sqlmock
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// RejectAmbiguousCallsOption sets whether an Exec or Query call fails,
+// which more than one pending expectation accepts. That may happen in
+// unordered mode or within an InAnyOrder group, when expectations with
+// the same regex and args may be matched at the same time. By default
+// the one declared first is matched, so the test silently depends on
+// the order of the declarations
+func RejectAmbiguousCallsOption(reject bool) Option {
+	return func(m *MockDB) {
+		m.conn.noAmbiguity = reject
+	}
+}
+
+// the pending expectations, which accept the call of the query,
+// without binding them. In unordered mode only the expectations
+// which may match the query are looked up
+func (c *conn) accepting(query string, accepts func(expectation) bool) (es []expectation) {
+	expectations := c.expectations
+	if c.unordered {
+		expectations = c.index.lookup(query)
+	}
+	current := c.current()
+	for _, e := range expectations {
+		if e.fulfilled() || !ready(e) || !c.onCaller(e) || (current != nil && outermost(e) != current) {
+			continue
+		}
+		if accepts(e) {
+			es = append(es, e)
+		}
+	}
+	return es
+}
+
+// fails the call, which more than one pending expectation
+// accepts, if ambiguous calls are rejected
+func (c *conn) checkAmbiguity(call, query string, accepts func(expectation) bool) error {
+	if !c.noAmbiguity {
+		return nil
+	}
+	es := c.accepting(query, accepts)
+	if len(es) < 2 {
+		return nil
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s is ambiguous, %d expectations accept it:", call, len(es))
+	for _, e := range es {
+		fmt.Fprintf(&buf, "\n  %s, declared at %s", e, e.common().declared)
+	}
+	return fmt.Errorf("%s", buf.String())
+}
@@ -0,0 +1,59 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRejectAmbiguousCalls(t *testing.T) {
+	db, m, err := NewMock(RejectAmbiguousCallsOption(true), MatchExpectationsInOrderOption(false))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectExec("UPDATE orders").WithArgs(1).WillReturnRowsAffected(1)
+	m.ExpectExec("UPDATE orders").WithArgs(2).WillReturnRowsAffected(1)
+	m.ExpectQuery("SELECT (.+) FROM users").WillReturnRow([]string{"name"}, "gedi")
+	m.ExpectQuery("SELECT name FROM users").WillReturnRow([]string{"name"}, "john")
+
+	// the args tell the updates apart
+	for _, id := range []int{2, 1} {
+		if _, err = db.Exec("UPDATE orders SET status = 'shipped' WHERE id = ?", id); err != nil {
+			t.Errorf("error '%s' was not expected while updating order %d", err, id)
+		}
+	}
+
+	var name string
+	err = db.QueryRow("SELECT name FROM users").Scan(&name)
+	if err == nil || !strings.HasPrefix(err.Error(), "call to query 'SELECT name FROM users' with args [] is ambiguous, 2 expectations accept it:\n"+
+		"  query 'SELECT (.+) FROM users', declared at ambiguity_test.go:16\n"+
+		"  query 'SELECT name FROM users', declared at ambiguity_test.go:17") {
+		t.Errorf("expected an error, since both queries accept the call, but got: %v", err)
+	}
+	err = m.ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "SELECT") {
+		t.Errorf("expected the queries not to be matched by the ambiguous call, but got: %v", err)
+	}
+	m.Reset()
+
+	// in ordered mode the calls are not ambiguous
+	m.MatchExpectationsInOrder(true)
+	m.ExpectExec("UPDATE orders").WillReturnRowsAffected(1)
+	m.ExpectExec("UPDATE orders").WillReturnRowsAffected(1)
+	for i := 0; i < 2; i++ {
+		if _, err = db.Exec("UPDATE orders SET status = 'shipped'"); err != nil {
+			t.Errorf("error '%s' was not expected while updating orders", err)
+		}
+	}
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	m.InAnyOrder(m.ExpectExec("UPDATE orders"), m.ExpectExec("UPDATE orders"))
+	_, err = db.Exec("UPDATE orders SET status = 'shipped'")
+	if err == nil || !strings.Contains(err.Error(), "is ambiguous, 2 expectations accept it") {
+		t.Errorf("expected an error, since both updates of the group accept the call, but got: %v", err)
+	}
+	m.Reset()
+	db.Close()
+}
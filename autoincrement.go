@@ -0,0 +1,55 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+)
+
+// AutoIncrement makes matched execs of INSERT and REPLACE statements
+// return incrementing LastInsertId values, like an auto-increment
+// column does, so that tests inserting many rows, like parents and
+// their children, need no NewResult bookkeeping. Ids start at start
+// and advance by step for every inserted row, the first id of the
+// rows is returned, like MySQL does. Results set with an insert id,
+// or an error, are returned as they are, execs without a result set
+// affect a single row. The sequence starts over as the connection
+// is closed, a zero step disables it
+func AutoIncrement(start, step int64) {
+	mock.AutoIncrement(start, step)
+}
+
+// AutoIncrement makes inserts return incrementing last insert ids
+func (m *MockDB) AutoIncrement(start, step int64) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.autoStart, m.conn.autoStep, m.conn.autoNext = start, step, start
+}
+
+// whether the query is an insert, which auto-increment ids are returned by
+func (c *conn) increments(query string) bool {
+	if c.autoStep == 0 {
+		return false
+	}
+	ts := tokenize(query)
+	return len(ts) > 0 && (ts[0].is("INSERT") || ts[0].is("REPLACE"))
+}
+
+// the result of a matched exec, with the next auto-increment
+// id if the query is an insert and the result has no id set
+func (c *conn) autoIncrement(query string, res driver.Result) driver.Result {
+	if !c.increments(query) {
+		return res
+	}
+	rows := int64(1)
+	if res != nil {
+		r, ok := res.(*result)
+		if !ok || r.insertID != 0 || r.err != nil {
+			return res
+		}
+		rows = r.rowsAffected
+	}
+	id := c.autoNext
+	if rows > 0 {
+		c.autoNext += rows * c.autoStep
+	}
+	return NewResult(id, rows)
+}
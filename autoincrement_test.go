@@ -0,0 +1,64 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestAutoIncrement(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	AutoIncrement(100, 10)
+	defer AutoIncrement(0, 0)
+
+	ExpectExec("INSERT INTO users")
+	ExpectBulkInsert("orders", []string{"user_id"}, [][]interface{}{{100}, {100}})
+	ExpectExec("INSERT INTO orders").WillReturnResult(NewResult(7, 1))
+	ExpectExec("INSERT INTO orders")
+	ExpectExec("UPDATE users").WillReturnRowsAffected(1)
+
+	expected := []struct {
+		query    string
+		args     []interface{}
+		id, rows int64
+	}{
+		{"INSERT INTO users (name) VALUES (?)", []interface{}{"gedi"}, 100, 1},
+		{"INSERT INTO orders (user_id) VALUES (?), (?)", []interface{}{100, 100}, 110, 2},
+		{"INSERT INTO orders (user_id) VALUES (?)", []interface{}{100}, 7, 1},
+		{"INSERT INTO orders (user_id) VALUES (?)", []interface{}{100}, 130, 1},
+		{"UPDATE users SET name = ?", []interface{}{"pieter"}, 0, 1},
+	}
+	for _, e := range expected {
+		res, err := db.Exec(e.query, e.args...)
+		if err != nil {
+			t.Fatalf("error '%s' was not expected for '%s'", err, e.query)
+		}
+		id, _ := res.LastInsertId()
+		rows, _ := res.RowsAffected()
+		if id != e.id || rows != e.rows {
+			t.Errorf("expected insert id %d and %d rows affected for '%s', but got %d and %d", e.id, e.rows, e.query, id, rows)
+		}
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+
+	db, err = sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	ExpectExec("INSERT INTO users")
+	res, err := db.Exec("INSERT INTO users (name) VALUES (?)", "gedi")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while inserting", err)
+	}
+	if id, _ := res.LastInsertId(); id != 100 {
+		t.Errorf("expected the sequence to start over on a new connection, but got id %d", id)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
@@ -0,0 +1,87 @@
+package sqlmock
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strings"
+)
+
+// BroadPatterns defines how expectations are treated, whose regexes
+// match the empty string or any statement of a kind, like ".*" or
+// "SELECT", so that they silently take wrong queries as well
+type BroadPatterns int
+
+const (
+	// AllowBroadPatterns does not check the regexes of expectations
+	AllowBroadPatterns BroadPatterns = iota
+	// WarnBroadPatterns makes Lint warn about broad regexes
+	WarnBroadPatterns
+	// RejectBroadPatterns makes the declaration of an
+	// expectation with a broad regex panic
+	RejectBroadPatterns
+)
+
+// words which follow the words statements start with, a regex of
+// such words and wildcards only matches any statement of the kind,
+// rather than a particular one
+var leadingWords = map[string]bool{
+	"INTO": true, "FROM": true, "WHERE": true, "ALL": true, "DISTINCT": true,
+}
+
+// BroadPatternsOption sets how expectations with overly broad
+// regexes are treated, by default they are allowed
+func BroadPatternsOption(check BroadPatterns) Option {
+	return func(m *MockDB) {
+		m.conn.broadRegexes = check
+	}
+}
+
+// checks whether the regex of the query based expectation is overly
+// broad, it returns an error describing why, if it is
+func checkBroadPattern(e expectation) error {
+	qe := queryBased(e)
+	if qe == nil || qe.sqlRegex == nil {
+		return nil
+	}
+	if qe.sqlRegex.MatchString("") {
+		return fmt.Errorf("expectation %s, declared at %s, is overly broad, it matches the empty string", e, e.common().declared)
+	}
+	re, err := syntax.Parse(qe.sqlRegex.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	var words []string
+	if !wildcards(re.Simplify(), &words) {
+		return nil
+	}
+	for _, w := range words {
+		if w = strings.ToUpper(w); !statementWords[w] && !leadingWords[w] {
+			return nil
+		}
+	}
+	return fmt.Errorf("expectation %s, declared at %s, is overly broad, it matches any %s statement", e, e.common().declared, strings.ToUpper(strings.Join(words, " ")))
+}
+
+// whether the regex consists of literal words and wildcards only,
+// the words are appended in order
+func wildcards(re *syntax.Regexp, words *[]string) bool {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpAnyChar, syntax.OpAnyCharNotNL, syntax.OpCharClass:
+		return true
+	case syntax.OpLiteral:
+		*words = append(*words, strings.Fields(string(re.Rune))...)
+		return true
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat, syntax.OpCapture:
+		sub := re.Sub[0]
+		return sub.Op != syntax.OpLiteral && wildcards(sub, words)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !wildcards(sub, words) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWarnBroadPatterns(t *testing.T) {
+	_, m, err := NewMock(BroadPatternsOption(WarnBroadPatterns))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectQuery(".*")
+	m.ExpectQuery("^SELECT")
+	m.ExpectExec("(?i)insert into (.+)")
+	m.ExpectExec(`^\s*DELETE\s+FROM\s+.*$`)
+	m.ExpectQuery("SELECT (.+) FROM users")
+	m.ExpectExec("UPDATE orders SET status")
+	m.ExpectExec("INSERT|UPDATE")
+
+	warnings := m.Lint()
+	expected := []string{
+		"expectation query '.*', declared at broad_test.go:14, is overly broad, it matches the empty string",
+		"expectation query '^SELECT', declared at broad_test.go:15, is overly broad, it matches any SELECT statement",
+		"expectation exec '(?i)insert into (.+)', declared at broad_test.go:16, is overly broad, it matches any INSERT INTO statement",
+		"expectation exec '^\\s*DELETE\\s+FROM\\s+.*$', declared at broad_test.go:17, is overly broad, it matches any DELETE FROM statement",
+	}
+	if len(warnings) != len(expected) {
+		t.Fatalf("expected %d warnings, but got %v", len(expected), warnings)
+	}
+	for i := range expected {
+		if warnings[i] != expected[i] {
+			t.Errorf("expected warning '%s', but got '%s'", expected[i], warnings[i])
+		}
+	}
+	m.Reset()
+
+	if warnings = Lint(); len(warnings) != 0 {
+		t.Errorf("expected broad patterns to be allowed by default, but got %v", warnings)
+	}
+}
+
+func TestRejectBroadPatterns(t *testing.T) {
+	_, m, err := NewMock(BroadPatternsOption(RejectBroadPatterns))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	m.ExpectQuery("SELECT (.+) FROM users")
+
+	defer func() {
+		r := recover()
+		if r == nil || !strings.Contains(r.(string), "is overly broad, it matches any SELECT statement") {
+			t.Errorf("expected a panic, since the regex is overly broad, but got: %v", r)
+		}
+		if n := len(m.(*MockDB).conn.expectations); n != 1 {
+			t.Errorf("expected the broad expectation not to be declared, but got %d expectations", n)
+		}
+	}()
+	m.ExpectQuery("SELECT")
+}
@@ -0,0 +1,72 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// a placeholder of any style
+const anyPlaceholder = `(\?|\$\d+|[:@]\w+)`
+
+// ExpectBulkInsert expects a multi-row INSERT into the table and columns,
+// which inserts the rows with placeholders in a single statement, like
+// INSERT INTO t (a, b) VALUES (?, ?), (?, ?). Both the number of rows of
+// VALUES and the args, flattened row by row, must match. The statement
+// returns a result with as many rows affected as there are rows, unless
+// another result is set. Panics if a row has not as many values as there
+// are columns
+func ExpectBulkInsert(table string, columns []string, rows [][]interface{}) Mock {
+	return mock.ExpectBulkInsert(table, columns, rows)
+}
+
+// ExpectBulkInsert expects a multi-row INSERT of the rows
+func (m *MockDB) ExpectBulkInsert(table string, columns []string, rows [][]interface{}) Mock {
+	return m.conn.expect(bulkInsertExpectation(table, columns, rows))
+}
+
+// ExpectBulkInsert expects a multi-row INSERT of the rows
+func (s *ExpectationSet) ExpectBulkInsert(table string, columns []string, rows [][]interface{}) Mock {
+	return s.recorder.expect(bulkInsertExpectation(table, columns, rows))
+}
+
+func bulkInsertExpectation(table string, columns []string, rows [][]interface{}) *expectedExec {
+	e := &expectedExec{result: NewResult(0, int64(len(rows)))}
+	e.what = fmt.Sprintf("bulk insert into %s %v of %d rows", table, columns, len(rows))
+	e.sqlRegex = bulkInsertRegex(table, columns, len(rows))
+	e.args = []driver.Value{}
+	for i, row := range rows {
+		if len(row) != len(columns) {
+			panic(fmt.Sprintf("row %d of the bulk insert into %s has %d values, but there are %d columns", i, table, len(row), len(columns)))
+		}
+		for _, v := range row {
+			e.args = append(e.args, v)
+		}
+	}
+	return e
+}
+
+// builds a regex matching an INSERT of the number of rows of placeholders
+// into the table and columns, where identifiers may be quoted
+func bulkInsertRegex(table string, columns []string, rows int) *regexp.Regexp {
+	ident := func(name string) string {
+		return "[\"`\\[]?" + regexp.QuoteMeta(name) + "[\"`\\]]?"
+	}
+
+	parts := strings.Split(table, ".")
+	for i, p := range parts {
+		parts[i] = ident(p)
+	}
+
+	cols := make([]string, len(columns))
+	for i, c := range columns {
+		cols[i] = ident(c)
+	}
+
+	row := `\(\s*` + strings.TrimSuffix(strings.Repeat(anyPlaceholder+`\s*,\s*`, len(columns)), `\s*,\s*`) + `\s*\)`
+	values := strings.TrimSuffix(strings.Repeat(row+`\s*,\s*`, rows), `\s*,\s*`)
+
+	return regexp.MustCompile(`(?i)^\s*INSERT\s+INTO\s+` + strings.Join(parts, `\.`) +
+		`\s*\(\s*` + strings.Join(cols, `\s*,\s*`) + `\s*\)\s*VALUES\s*` + values + `\s*;?\s*$`)
+}
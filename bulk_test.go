@@ -0,0 +1,66 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestBulkInsertRegex(t *testing.T) {
+	re := bulkInsertRegex("public.users", []string{"name", "age"}, 2)
+	matching := []string{
+		"INSERT INTO public.users (name, age) VALUES (?, ?), (?, ?)",
+		`insert into "public"."users"("name","age") values ($1,$2),($3,$4);`,
+		"INSERT INTO public.users (name, age) VALUES (:name1, :age1),\n(:name2, :age2)",
+	}
+	for _, q := range matching {
+		if !re.MatchString(q) {
+			t.Errorf("expected '%s' to match the bulk insert", q)
+		}
+	}
+	other := []string{
+		"INSERT INTO public.users (name, age) VALUES (?, ?)",
+		"INSERT INTO public.users (name, age) VALUES (?, ?), (?, ?), (?, ?)",
+		"INSERT INTO public.users (age, name) VALUES (?, ?), (?, ?)",
+		"INSERT INTO public.users (name, age) VALUES (?, 1), (?, 2)",
+	}
+	for _, q := range other {
+		if re.MatchString(q) {
+			t.Errorf("expected '%s' not to match the bulk insert", q)
+		}
+	}
+}
+
+func TestExpectBulkInsert(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rows := [][]interface{}{{"gedi", 30}, {"pieter", 25}}
+	ExpectBulkInsert("users", []string{"name", "age"}, rows)
+	ExpectBulkInsert("users", []string{"name", "age"}, rows)
+
+	res, err := db.Exec("INSERT INTO users (name, age) VALUES (?, ?), (?, ?)", "gedi", 30, "pieter", 25)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while inserting users", err)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Errorf("expected a row affected by every inserted row, but got %d", n)
+	}
+
+	_, err = db.Exec("INSERT INTO users (name, age) VALUES (?, ?), (?, ?)", "gedi", "pieter", 30, 25)
+	if err == nil || !strings.Contains(err.Error(), "does not match expected") {
+		t.Errorf("expected an error, since the args are ordered by column instead of by row, but got: %v", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic, since a row lacks a value")
+		}
+	}()
+	ExpectBulkInsert("users", []string{"name", "age"}, [][]interface{}{{"gedi"}})
+}
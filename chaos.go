@@ -0,0 +1,26 @@
+package sqlmock
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// ErrChaos is returned by driver calls failed by the chaos mode,
+// unless other errors were given
+var ErrChaos = fmt.Errorf("chaos: simulated intermittent database failure")
+
+// randomly fails a fraction of matched driver calls
+type chaos struct {
+	rnd      *rand.Rand
+	fraction float64
+	errs     []error
+}
+
+// returns an error for the driver call if it was chosen
+// to fail, the chaos mode may be off if it is nil
+func (c *chaos) fail() error {
+	if c == nil || c.rnd.Float64() >= c.fraction {
+		return nil
+	}
+	return c.errs[c.rnd.Intn(len(c.errs))]
+}
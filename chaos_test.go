@@ -0,0 +1,53 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestChaosIsReproducible(t *testing.T) {
+	run := func() (failed []int) {
+		db, err := sql.Open("mock", "")
+		if err != nil {
+			t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+		}
+
+		Chaos(42, 0.5)
+		defer Chaos(0, 0)
+
+		for i := 0; i < 20; i++ {
+			ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, 1))
+		}
+
+		for i := 0; i < 20; i++ {
+			_, err := db.Exec("UPDATE articles SET title = ?", "hello")
+			switch err {
+			case nil:
+			case ErrChaos:
+				failed = append(failed, i)
+			default:
+				t.Errorf("expected no error or chaos error, but got: %s", err)
+			}
+		}
+
+		if err = db.Close(); err != nil {
+			t.Errorf("error '%s' was not expected while closing the database", err)
+		}
+		return
+	}
+
+	first, second := run(), run()
+	if len(first) == 0 || len(first) == 20 {
+		t.Fatalf("expected about half of the calls to fail, but %d of 20 failed", len(first))
+	}
+
+	if len(first) != len(second) {
+		t.Fatalf("expected the same calls to fail with the same seed, but got %v and %v", first, second)
+	}
+
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("expected the same calls to fail with the same seed, but got %v and %v", first, second)
+		}
+	}
+}
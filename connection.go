@@ -1,141 +1,1007 @@
 package sqlmock
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"fmt"
+	"math/rand"
 	"reflect"
+	"regexp"
+	"sync"
+	"time"
 )
 
 type conn struct {
+	mu sync.Mutex
+
 	expectations []expectation
-	active       expectation
+	index        index          // of query based expectations, used in unordered mode
+	transactions []*transaction // begun since the last reset
+	statements   []*statement   // prepared since the last reset
+	unordered    bool
+	placeholders PlaceholderStyle
+	matching     QueryMatching
+	invalid      bool
+	monitorPings bool                  // whether pings are matched against expectations
+	noDirect     bool                  // whether calls must be made through prepared statements
+	broadRegexes BroadPatterns         // how expectations with overly broad regexes are treated
+	noAmbiguity  bool                  // whether calls several expectations accept fail
+	format       SQLFormat             // of queries in failure messages
+	verbosity    Verbosity             // of failure messages
+	converter    driver.ValueConverter // of arguments of statements, if not the default
+	caller       *session              // making the driver call being handled
+	stackDepth   int                   // of the stacks of driver calls recorded, none if zero
+
+	conns []*expectedConn // dedicated connections expected
+
+	stats           CallStats
+	tracer          func(Span) // of driver calls, if any
+	unexpectedCalls []string   // the most recent calls none of the expectations matched
+	history         []RecordedCall
+
+	// policies
+	readOnly   bool
+	forbidden  []*regexp.Regexp
+	allowed    []*regexp.Regexp
+	violations []error
+	schema     *Schema    // statements are validated against, if set
+	syntax     SQLDialect // statements are parsed as, if set
+
+	noMoreInteractions bool
+	stmtsMustBeClosed  bool
+
+	compare comparison
+
+	faults *faults // set by FailAfter, until a connection takes it over
+
+	chaos *chaos
+
+	permissive *permissive // when set, expectations are not matched
+
+	// latency of every driver call
+	latency time.Duration
+	jitter  time.Duration
+
+	// connections expire after, if set
+	connLifetime time.Duration
+	connCalls    int
+
+	// opening more connections than allowed fails, if set
+	maxConns    int
+	maxConnsErr error
+	openConns   int
+	shared      *pool // of connections opened without a connector
+
+	tables      map[string]*Table // answering statements no expectation matches
+	passthrough *sql.DB           // delegated calls nothing else matches, if set
+
+	// ids returned by inserts, if the step is set
+	autoStart, autoStep int64
+	autoNext            int64
+}
+
+// registers an expectation, the returned mock
+// allows it to be detailed further
+func (c *conn) expect(e expectation) Mock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e.common().declared = callSite()
+	if qe := queryBased(e); qe != nil {
+		c.adaptRegex(qe)
+	}
+	if c.broadRegexes == RejectBroadPatterns {
+		if err := checkBroadPattern(e); err != nil {
+			panic(err.Error())
+		}
+	}
+	c.index.add(len(c.expectations), e)
+	c.expectations = append(c.expectations, e)
+	return &mockedExpectation{c, e}
+}
+
+// normalizes expected regexes of all expectations
+// declared so far, after the query matching changed
+func (c *conn) adapt() {
+	c.index = index{}
+	for i, e := range c.expectations {
+		if qe := queryBased(e); qe != nil {
+			c.adaptRegex(qe)
+		}
+		c.index.add(i, e)
+	}
+}
+
+// normalizes the expected regex like queries are
+func (c *conn) adaptRegex(qe *queryBasedExpectation) {
+	m := c.matching
+	if qe.unanchored {
+		m &^= AnchorRegexes
+	}
+	qe.regex = m.adapt(qe.sqlRegex)
+}
+
+// the expectation declared last
+func (c *conn) last() expectation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.expectations[len(c.expectations)-1]
+}
+
+// number of expectations declared
+func (c *conn) declared() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.expectations)
+}
+
+// sleeps for the configured latency with a random
+// jitter, before the driver call is handled
+func (c *conn) wait() {
+	c.mu.Lock()
+	d := c.latency
+	if c.jitter > 0 {
+		d += time.Duration(rand.Int63n(int64(c.jitter)))
+	}
+	c.mu.Unlock()
+	time.Sleep(d)
 }
 
 // Close a mock database driver connection. It should
 // be always called to ensure that all expectations
 // were met successfully. Returns error if there is any
-func (c *conn) Close() (err error) {
-	for _, e := range mock.conn.expectations {
+func (s *session) Close() (err error) {
+	c := s.conn
+	s.lock()
+	defer c.mu.Unlock()
+
+	c.openConns--
+	s.pool.open--
+
+	s.leased = false
+	if s.bad {
+		// database/sql discards connections which returned driver.ErrBadConn
+		// or were invalidated and continues on a fresh one, expectations
+		// must remain as they are
+		return nil
+	}
+	if s.pool.deferred || s.pool.closed || s.pool.open > 0 {
+		// database/sql closes idle connections beyond its limit on its own,
+		// the expectations are verified as the database is closed or, if
+		// it does not tell, once the last connection of it is closed
+		return nil
+	}
+
+	err = c.verify()
+	c.reset()
+	return err
+}
+
+// checks whether all expectations were met, rows returned were
+// consumed as required and no policies were violated
+func (c *conn) verify() (err error) {
+	for _, e := range c.expectations {
+		if common := e.common(); !e.fulfilled() && common.matched > 0 {
+			err = fmt.Errorf("there is a remaining expectation %s, declared at %s, which was matched only %d of %d times", e, common.declared, common.matched, common.times)
+			break
+		}
 		if !e.fulfilled() {
-			err = fmt.Errorf("there is a remaining expectation %T which was not matched yet", e)
+			err = fmt.Errorf("there is a remaining expectation %s, declared at %s, which was not matched yet", e, e.common().declared)
 			break
 		}
 	}
-	mock.conn.expectations = []expectation{}
-	mock.conn.active = nil
+	for _, tx := range c.transactions {
+		if err == nil && !tx.done {
+			err = fmt.Errorf("transaction begun at %s was left open, it was neither committed nor rolled back", tx.begun)
+		}
+	}
+	if err == nil {
+		err = c.verifyConns()
+	}
+	for _, stmt := range c.statements {
+		if err == nil && c.stmtsMustBeClosed && !stmt.closed {
+			err = fmt.Errorf("statement '%s' prepared at %s was never closed", stmt.query, stmt.created)
+		}
+	}
+	for _, e := range c.expectations {
+		if err != nil {
+			break
+		}
+		switch e := e.(type) {
+		case *expectedQuery:
+			err = e.leak(e)
+		case *expectedCall:
+			err = e.leak(e)
+		}
+	}
+	for _, e := range c.expectations {
+		if err == nil && e.common().reuse {
+			err = reused(e)
+		}
+	}
+	if len(c.violations) > 0 {
+		err = violationsError(c.violations)
+	}
 	return err
 }
 
-func (c *conn) Begin() (driver.Tx, error) {
-	e := c.next()
+// clears expectations and policies with their violations
+func (c *conn) reset() {
+	c.expectations = []expectation{}
+	c.index = index{}
+	c.transactions = nil
+	c.statements = nil
+	c.conns = nil
+	c.readOnly, c.forbidden, c.allowed, c.violations = false, nil, nil, nil
+	c.schema, c.syntax = nil, 0
+	c.noMoreInteractions = false
+	c.stmtsMustBeClosed = false
+	c.compare = comparison{}
+	c.stats = CallStats{}
+	c.history = nil
+	c.unexpectedCalls = nil
+	c.autoNext = c.autoStart
+	c.tables = nil
+	c.faults = nil
+}
+
+func (s *session) Begin() (driver.Tx, error) {
+	return s.begin(nil)
+}
+
+// begins a transaction, which may be canceled by ctx
+func (s *session) begin(ctx canceler) (_ driver.Tx, err error) {
+	defer s.trace("sql.begin", "", nil)(&err, nil)
+	c := s.conn
+	c.wait()
+	s.lock()
+	defer c.mu.Unlock()
+	c.stats.Transactions++
+
+	if c.permissive != nil {
+		return &transaction{conn: s, done: true}, nil
+	}
+
+	call := "call to begin transaction"
+	if err := c.checkInteraction(call); err != nil {
+		return nil, err
+	}
+	if err := c.interrupt(call); err != nil {
+		return nil, err
+	}
+
+	e := c.next(ofType(&expectedBegin{}))
 	if e == nil {
-		return nil, fmt.Errorf("all expectations were already fulfilled, call to begin transaction was not expected")
+		return nil, c.unexpected("call to begin transaction")
 	}
 
 	etb, ok := e.(*expectedBegin)
 	if !ok {
-		return nil, fmt.Errorf("call to begin transaction, was not expected, next expectation is %T as %+v", e, e)
+		return nil, fmt.Errorf("call to begin transaction, was not expected, next expectation is %s", e)
+	}
+	trigger(etb, call)
+	if err := checkDeadline(etb, ctx, call); err != nil {
+		return nil, err
+	}
+	if err := checkContext(etb, ctx, call); err != nil {
+		return nil, err
+	}
+	if err := s.delay(etb, ctx); err != nil {
+		return nil, err
+	}
+	err = etb.err
+	if err == nil {
+		err = c.chaos.fail()
+	}
+	if err != nil {
+		return nil, c.badConn(err)
+	}
+	tx := &transaction{conn: s, begun: callSite(), ctx: ctx}
+	c.transactions = append(c.transactions, tx)
+	return tx, nil
+}
+
+// pings the connection, which is matched against expectations
+// only when pings are monitored, otherwise it always succeeds
+func (s *session) ping(ctx canceler) (err error) {
+	defer s.trace("sql.ping", "", nil)(&err, nil)
+	c := s.conn
+	c.wait()
+	s.lock()
+	defer c.mu.Unlock()
+
+	if !c.monitorPings || c.permissive != nil {
+		return nil
+	}
+
+	call := "call to ping"
+	if err := c.checkInteraction(call); err != nil {
+		return err
+	}
+	if err := c.interrupt(call); err != nil {
+		return err
+	}
+
+	e := c.next(ofType(&expectedPing{}))
+	if e == nil {
+		return c.unexpected(call)
+	}
+
+	ep, ok := e.(*expectedPing)
+	if !ok {
+		return fmt.Errorf("call to ping, was not expected, next expectation is %s", e)
+	}
+	trigger(ep, call)
+	if err := checkDeadline(ep, ctx, call); err != nil {
+		return err
+	}
+	if err := checkContext(ep, ctx, call); err != nil {
+		return err
+	}
+	if err := s.delay(ep, ctx); err != nil {
+		return err
+	}
+	err = ep.err
+	if err == nil {
+		err = c.chaos.fail()
+	}
+	return c.badConn(err)
+}
+
+// resets the session if it is the next expectation, otherwise
+// ignores it for backwards compatibility like Prepare does
+func (s *session) resetSession() error {
+	c := s.conn
+	s.lock()
+	defer c.mu.Unlock()
+	s.leased = true // taken from the pool
+	if s.expired() {
+		s.bad = true
+		return driver.ErrBadConn
+	}
+
+	e, ok := c.next(ofType(&expectedResetSession{})).(*expectedResetSession)
+	if !ok {
+		return nil
+	}
+	trigger(e, "call to reset session")
+	return c.badConn(e.err)
+}
+
+// reports whether the connection is valid, an invalidated
+// connection is discarded by database/sql like a bad one
+func (s *session) valid() bool {
+	c := s.conn
+	s.lock()
+	defer c.mu.Unlock()
+
+	s.leased = false // returned to the pool
+	if c.invalid || s.expired() {
+		c.invalid = false
+		s.bad = true
+		return false
+	}
+	return true
+}
+
+// remembers that the connection was reported as bad
+// by a mocked error, so that it is not asserted on close
+func (c *conn) badConn(err error) error {
+	if err == driver.ErrBadConn {
+		c.caller.bad = true
+	}
+	return err
+}
+
+// fails the driver call before it is matched against expectations,
+// if the connection is past its fault schedule or a bad connection
+// is expected next
+func (c *conn) interrupt(call string) error {
+	if err := c.fault(); err != nil {
+		return err
+	}
+	if c.nextIsBadConn(call) {
+		return driver.ErrBadConn
+	}
+	return nil
+}
+
+// counts the driver call and fails it if the connection is past
+// its fault schedule. A schedule set by FailAfter is taken over by
+// the connection making the next call, so that it is the one failing
+func (c *conn) fault() error {
+	s := c.caller
+	if c.faults != nil {
+		s.faults, c.faults = c.faults, nil
+	}
+	if s.faults == nil || s.faults.err == nil {
+		return nil
+	}
+	s.faults.calls++
+	if s.faults.calls > s.faults.after {
+		return c.badConn(s.faults.err)
+	}
+	return nil
+}
+
+// triggers the next expectation if it is a bad connection,
+// which any driver call may match
+func (c *conn) nextIsBadConn(call string) bool {
+	e, ok := c.next(ofType(&expectedBadConn{})).(*expectedBadConn)
+	if ok {
+		trigger(e, call)
+		c.caller.bad = true
+	}
+	return ok
+}
+
+// get next unfulfilled expectation, which is ready to be matched
+// and accepts the call. In ordered mode, unless a group is to be
+// matched, the next one is returned even if it does not accept
+// the call, so the mismatch is reported
+func (c *conn) next(accepts func(expectation) bool) expectation {
+	return c.nextOf(c.expectations, accepts)
+}
+
+// get next expectation, like next does, for a query. In unordered
+// mode only the expectations which may match it are looked up
+func (c *conn) nextQuery(query string, accepts func(expectation) bool) expectation {
+	if c.unordered {
+		return c.nextOf(c.index.lookup(query), accepts)
+	}
+	return c.next(accepts)
+}
+
+// get next expectation among the given ones
+func (c *conn) nextOf(expectations []expectation, accepts func(expectation) bool) expectation {
+	var first expectation
+	current := c.current()
+	for _, e := range expectations {
+		if e.fulfilled() || !ready(e) || !c.onCaller(e) || (current != nil && outermost(e) != current) {
+			continue
+		}
+		if accepts(e) {
+			c.bind(e)
+			return e
+		}
+		if first == nil {
+			first = e
+		}
+	}
+	if _, ok := current.(*expectedGroup); ok || c.unordered || first == nil {
+		return nil
+	}
+	c.bind(first)
+	return first
+}
+
+// in ordered mode, the expectation or outermost group, which is
+// declared first among the ones not fulfilled yet
+func (c *conn) current() expectation {
+	if c.unordered {
+		return nil
+	}
+	seen := make(map[expectation]bool)
+	for _, e := range c.expectations {
+		if e = outermost(e); !seen[e] && !e.fulfilled() {
+			return e
+		}
+		seen[e] = true
+	}
+	return nil
+}
+
+// checks whether the expectations, which the given one must be matched
+// after, are fulfilled. So must be the ones preceding it in an ordered
+// group and the ones its groups must be matched after
+func ready(e expectation) bool {
+	for {
+		common := e.common()
+		for _, other := range common.after {
+			if !other.fulfilled() {
+				return false
+			}
+		}
+		g := common.group
+		if g == nil {
+			return true
+		}
+		if g.ordered {
+			for _, member := range g.members {
+				if member == e {
+					break
+				}
+				if !member.fulfilled() {
+					return false
+				}
+			}
+		}
+		e = g
+	}
+}
+
+// the outermost group the expectation belongs to, or itself
+func outermost(e expectation) expectation {
+	for g := e.common().group; g != nil; g = g.common().group {
+		e = g
+	}
+	return e
+}
+
+// groups expectations of the given mocks,
+// which may be groups themselves
+func (c *conn) group(ordered bool, mocks []Mock) Mock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	g := &expectedGroup{ordered: ordered}
+	for _, m := range mocks {
+		me, ok := m.(*mockedExpectation)
+		if !ok || me.conn != c {
+			panic(fmt.Sprintf("only expectations of the same mock may be grouped, but got %T", m))
+		}
+		common := me.e.common()
+		if common.group != nil {
+			panic(fmt.Sprintf("expectation %T is already in a group", me.e))
+		}
+		common.group = g
+		g.members = append(g.members, me.e)
+	}
+	return &mockedExpectation{c, g}
+}
+
+// groups expectations of every lane in order, while
+// the lanes may interleave in any order
+func (c *conn) lanes(lanes [][]Mock) Mock {
+	groups := make([]Mock, len(lanes))
+	for i, lane := range lanes {
+		groups[i] = c.group(true, lane)
+	}
+	return c.group(false, groups)
+}
+
+// accepts expectations of the same type as the given one
+func ofType(expected expectation) func(expectation) bool {
+	return func(e expectation) bool {
+		return reflect.TypeOf(e) == reflect.TypeOf(expected)
 	}
-	etb.triggered = true
-	return &transaction{c}, etb.err
 }
 
-// get next unfulfilled expectation
-func (c *conn) next() (e expectation) {
-	for _, e = range c.expectations {
+// error of a call, which none of the expectations was left for
+func (c *conn) unexpected(call string) error {
+	if c.unexpectedCalls = append(c.unexpectedCalls, call); len(c.unexpectedCalls) > recentUnexpectedCalls {
+		c.unexpectedCalls = c.unexpectedCalls[1:]
+	}
+	for _, e := range c.expectations {
 		if !e.fulfilled() {
-			return
+			return fmt.Errorf("%s was not expected, none of the remaining expectations may match it%s", call, c.details(nil))
 		}
 	}
-	return nil // all expectations were fulfilled
+	return fmt.Errorf("all expectations were already fulfilled, %s was not expected%s", call, c.details(nil))
+}
+
+func (s *session) Exec(query string, args []driver.Value) (driver.Result, error) {
+	if s.skipped() {
+		return nil, driver.ErrSkip
+	}
+	return s.exec(nil, query, args)
 }
 
-func (c *conn) Exec(query string, args []driver.Value) (res driver.Result, err error) {
-	e := c.next()
-	query = stripQuery(query)
+// execs the query, the call may be canceled by ctx
+func (s *session) exec(ctx canceler, query string, args []driver.Value) (res driver.Result, err error) {
+	defer s.trace("sql.exec", query, args)(&err, &res)
+	c := s.conn
+	c.wait()
+	s.lock()
+	defer c.mu.Unlock()
+	c.stats.Execs++
+
+	if c.permissive != nil {
+		return c.permissive.exec(), nil
+	}
+
+	call := fmt.Sprintf("call to exec query '%s' with args %+v", stripQuery(query), args)
+	if err = c.checkInteraction(call); err != nil {
+		return nil, err
+	}
+	if err := c.interrupt(call); err != nil {
+		return nil, err
+	}
+
+	stmt := stripQuery(query)
+	if err = c.checkPolicies(stmt); err != nil {
+		return nil, err
+	}
+	query = c.matching.normalize(stmt)
+
+	accepts := func(e expectation) bool {
+		switch e := e.(type) {
+		case *expectedExec:
+			return e.matches(query, args, c.compare) && e.placeholdersMatch(query, c.placeholders)
+		case *expectedCall:
+			return e.matches(query, args, c.compare) && e.placeholdersMatch(query, c.placeholders)
+		}
+		return false
+	}
+	if c.fallsBack() && !c.accepted(query, accepts) {
+		if res, ok, err := c.fake(stmt, args); ok {
+			if err != nil {
+				return nil, err
+			}
+			if rs, isRows := res.(driver.Rows); isRows {
+				rs.Close()
+				return NewResult(0, 0), nil // a query executed
+			}
+			return res.(driver.Result), nil
+		}
+		if c.passthrough != nil {
+			return c.passExec(stmt, args)
+		}
+	}
+
+	if err = c.checkAmbiguity(call, query, accepts); err != nil {
+		return nil, err
+	}
+	e := c.nextQuery(query, accepts)
 	if e == nil {
-		return nil, fmt.Errorf("all expectations were already fulfilled, call to exec '%s' query with args %+v was not expected", query, args)
+		return nil, c.unexpected(fmt.Sprintf("call to exec %s query with args %s%s", c.sql(stmt), c.printArgs(args), c.preview(stmt, args)))
+	}
+
+	if ec, ok := e.(*expectedCall); ok {
+		if err = c.call(ec, call, stmt, query, args); err != nil {
+			return nil, err
+		}
+		if err = checkDeadline(ec, ctx, call); err != nil {
+			return nil, err
+		}
+		if err = checkContext(ec, ctx, call); err != nil {
+			return nil, err
+		}
+		if err = checkTransaction(ec, s, call); err != nil {
+			return nil, err
+		}
+		if err = checkStatement(ec, s, call); err != nil {
+			return nil, err
+		}
+		ec.stmts = append(ec.stmts, s.stmt)
+		if err = s.delay(ec, ctx); err != nil {
+			return nil, err
+		}
+		ec.queries = append(ec.queries, stmt)
+		if ec.result == nil {
+			return &result{}, nil
+		}
+		return ec.result, nil
+	}
+
+	if eq, ok := e.(*expectedQuery); ok && eq.returning != "" {
+		return nil, fmt.Errorf("call to exec query %s with args %s, was not expected, %s returns rows, so it must be made with Query or QueryRow", c.sql(stmt), c.printArgs(args), eq)
 	}
 
 	eq, ok := e.(*expectedExec)
 	if !ok {
-		return nil, fmt.Errorf("call to exec query '%s' with args %+v, was not expected, next expectation is %T as %+v", query, args, e, e)
+		return nil, fmt.Errorf("call to exec query %s with args %s%s, was not expected, next expectation is %s%s", c.sql(stmt), c.printArgs(args), c.preview(stmt, args), e, c.details(e))
 	}
 
-	eq.triggered = true
+	trigger(eq, call)
+	if err = checkDeadline(eq, ctx, call); err != nil {
+		return nil, err
+	}
+	if err = checkContext(eq, ctx, call); err != nil {
+		return nil, err
+	}
+	if err = checkTransaction(eq, s, call); err != nil {
+		return nil, err
+	}
+	if err = checkStatement(eq, s, call); err != nil {
+		return nil, err
+	}
+	eq.stmts = append(eq.stmts, s.stmt)
+	if err = s.delay(eq, ctx); err != nil {
+		return nil, err
+	}
 	if eq.err != nil {
-		return nil, eq.err // mocked to return error
+		eq.queries = append(eq.queries, stmt)
+		return nil, c.badConn(eq.err) // mocked to return error
 	}
 
-	if eq.result == nil {
-		return nil, fmt.Errorf("exec query '%s' with args %+v, must return a database/sql/driver.result, but it was not set for expectation %T as %+v", query, args, eq, eq)
+	if eq.result == nil && !c.increments(query) {
+		return nil, fmt.Errorf("exec query '%s' with args %+v, must return a database/sql/driver.result, but it was not set for expectation %s", query, args, eq)
 	}
 
 	defer argMatcherErrorHandler(&err) // converts panic to error in case of reflect value type mismatch
 
 	if !eq.queryMatches(query) {
-		return nil, fmt.Errorf("exec query '%s', does not match regex '%s'", query, eq.sqlRegex.String())
+		return nil, fmt.Errorf("exec query %s, does not match regex %s", c.sql(stmt), c.sql(eq.sqlRegex.String()))
+	}
+
+	if err = eq.upsert.check(query); err != nil {
+		return nil, fmt.Errorf("exec query %s, %s", c.sql(query), err)
+	}
+
+	if !eq.argsMatches(args, c.compare) {
+		return nil, fmt.Errorf("exec query %s, args %s%s does not match expected %s%s", c.sql(stmt), c.printArgs(args), c.preview(stmt, args), c.printArgs(eq.args), c.details(eq))
+	}
+
+	if !eq.placeholdersMatch(query, c.placeholders) {
+		return nil, fmt.Errorf("exec query %s has %d placeholders, but args %+v were expected", c.sql(query), countPlaceholders(query, c.placeholders), eq.args)
 	}
 
-	if !eq.argsMatches(args) {
-		return nil, fmt.Errorf("exec query '%s', args %+v does not match expected %+v", query, args, eq.args)
+	if err = c.chaos.fail(); err != nil {
+		return nil, c.badConn(err)
 	}
 
-	return eq.result, err
+	eq.queries = append(eq.queries, stmt)
+	return c.autoIncrement(query, eq.result), err
 }
 
-func (c *conn) Prepare(query string) (driver.Stmt, error) {
-	e := c.next()
+func (s *session) Prepare(query string) (_ driver.Stmt, err error) {
+	defer s.trace("sql.prepare", query, nil)(&err, nil)
+	c := s.conn
+	c.wait()
+	s.lock()
+	defer c.mu.Unlock()
+	c.stats.Prepares++
+
+	if c.permissive != nil {
+		return &statement{conn: s, query: stripQuery(query), closed: true}, nil
+	}
+
+	call := fmt.Sprintf("call to prepare '%s'", stripQuery(query))
+	if err := c.checkInteraction(call); err != nil {
+		return nil, err
+	}
+	if err := c.interrupt(call); err != nil {
+		return nil, err
+	}
+
+	e := c.next(func(e expectation) bool {
+		switch e := e.(type) {
+		case *expectedPrepare:
+			return true
+		case *expectedCopyFrom:
+			return copyFromRegex(e.table, e.columns).MatchString(stripQuery(query))
+		}
+		return false
+	})
 
 	// for backwards compatibility, ignore when Prepare not expected
 	if e == nil {
-		return &statement{mock.conn, stripQuery(query)}, nil
+		return s.prepared(stripQuery(query)), nil
 	}
+	if ec, ok := e.(*expectedCopyFrom); ok && isCopyFrom(query) {
+		if err := c.checkPolicies(stripQuery(query)); err != nil {
+			return nil, err
+		}
+		return ec.prepare(s, call, stripQuery(query))
+	}
+
 	eq, ok := e.(*expectedPrepare)
 	if !ok {
-		return &statement{mock.conn, stripQuery(query)}, nil
+		return s.prepared(stripQuery(query)), nil
 	}
 
-	eq.triggered = true
+	trigger(eq, call)
+	if err := checkTransaction(eq, s, call); err != nil {
+		return nil, err
+	}
+	if err := s.delay(eq, nil); err != nil {
+		return nil, err
+	}
 	if eq.err != nil {
-		return nil, eq.err // mocked to return error
+		return nil, c.badConn(eq.err) // mocked to return error
 	}
 
-	return &statement{mock.conn, stripQuery(query)}, nil
+	if err := c.chaos.fail(); err != nil {
+		return nil, c.badConn(err)
+	}
+
+	stmt := s.prepared(stripQuery(query))
+	eq.prepared = append(eq.prepared, stmt)
+	return stmt, nil
+}
+
+func (s *session) Query(query string, args []driver.Value) (driver.Rows, error) {
+	if s.skipped() {
+		return nil, driver.ErrSkip
+	}
+	return s.query(nil, query, args)
 }
 
-func (c *conn) Query(query string, args []driver.Value) (rw driver.Rows, err error) {
-	e := c.next()
-	query = stripQuery(query)
+// whether the call is made directly, rather than through a statement,
+// while direct calls are disabled, so database/sql must prepare one
+func (s *session) skipped() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.noDirect && s.stmt == nil
+}
+
+// queries rows, the call may be canceled by ctx
+func (s *session) query(ctx canceler, query string, args []driver.Value) (rw driver.Rows, err error) {
+	defer s.trace("sql.query", query, args)(&err, &rw)
+	c := s.conn
+	c.wait()
+	s.lock()
+	defer c.mu.Unlock()
+	c.stats.Queries++
+
+	if c.permissive != nil {
+		return c.counted(c.permissive.query()), nil
+	}
+
+	call := fmt.Sprintf("call to query '%s' with args %+v", stripQuery(query), args)
+	if err = c.checkInteraction(call); err != nil {
+		return nil, err
+	}
+	if err := c.interrupt(call); err != nil {
+		return nil, err
+	}
+
+	stmt := stripQuery(query)
+	if err = c.checkPolicies(stmt); err != nil {
+		return nil, err
+	}
+	query = c.matching.normalize(stmt)
+
+	accepts := func(e expectation) bool {
+		switch e := e.(type) {
+		case *expectedQuery:
+			return e.matches(query, args, c.compare) && e.placeholdersMatch(query, c.placeholders)
+		case *expectedCall:
+			return e.matches(query, args, c.compare) && e.placeholdersMatch(query, c.placeholders)
+		}
+		return false
+	}
+	if c.fallsBack() && !c.accepted(query, accepts) {
+		if res, ok, err := c.fake(stmt, args); ok {
+			if err != nil {
+				return nil, err
+			}
+			if rs, isRows := res.(driver.Rows); isRows {
+				return rs, nil
+			}
+			return NewRows(nil), nil // a statement queried
+		}
+		if c.passthrough != nil {
+			return c.passQuery(stmt, args)
+		}
+	}
+
+	if err = c.checkAmbiguity(call, query, accepts); err != nil {
+		return nil, err
+	}
+	e := c.nextQuery(query, accepts)
 	if e == nil {
-		return nil, fmt.Errorf("all expectations were already fulfilled, call to query '%s' with args %+v was not expected", query, args)
+		return nil, c.unexpected(fmt.Sprintf("call to query %s with args %s%s", c.sql(stmt), c.printArgs(args), c.preview(stmt, args)))
+	}
+
+	if ec, ok := e.(*expectedCall); ok {
+		if err = c.call(ec, call, stmt, query, args); err != nil {
+			return nil, err
+		}
+		if err = checkDeadline(ec, ctx, call); err != nil {
+			return nil, err
+		}
+		if err = checkContext(ec, ctx, call); err != nil {
+			return nil, err
+		}
+		if err = checkTransaction(ec, s, call); err != nil {
+			return nil, err
+		}
+		if err = checkStatement(ec, s, call); err != nil {
+			return nil, err
+		}
+		ec.stmts = append(ec.stmts, s.stmt)
+		if err = s.delay(ec, ctx); err != nil {
+			return nil, err
+		}
+		ec.queries = append(ec.queries, stmt)
+		if ec.rows == nil {
+			return ec.track(c, &rows{}), nil
+		}
+		return ec.track(c, cloneRows(ec.rows)), nil
 	}
 
 	eq, ok := e.(*expectedQuery)
 	if !ok {
-		return nil, fmt.Errorf("call to query '%s' with args %+v, was not expected, next expectation is %T as %+v", query, args, e, e)
+		return nil, fmt.Errorf("call to query %s with args %s%s, was not expected, next expectation is %s%s", c.sql(stmt), c.printArgs(args), c.preview(stmt, args), e, c.details(e))
 	}
 
-	eq.triggered = true
+	trigger(eq, call)
+	if err = checkDeadline(eq, ctx, call); err != nil {
+		return nil, err
+	}
+	if err = checkContext(eq, ctx, call); err != nil {
+		return nil, err
+	}
+	if err = checkTransaction(eq, s, call); err != nil {
+		return nil, err
+	}
+	if err = checkStatement(eq, s, call); err != nil {
+		return nil, err
+	}
+	eq.stmts = append(eq.stmts, s.stmt)
+	if err = s.delay(eq, ctx); err != nil {
+		return nil, err
+	}
 	if eq.err != nil {
-		return nil, eq.err // mocked to return error
+		eq.queries = append(eq.queries, stmt)
+		return nil, c.badConn(eq.err) // mocked to return error
 	}
 
-	if eq.rows == nil {
-		return nil, fmt.Errorf("query '%s' with args %+v, must return a database/sql/driver.rows, but it was not set for expectation %T as %+v", query, args, eq, eq)
+	if eq.rows == nil && eq.returning == "" {
+		return nil, fmt.Errorf("query '%s' with args %+v, must return a database/sql/driver.rows, but it was not set for expectation %s", query, args, eq)
 	}
 
 	defer argMatcherErrorHandler(&err) // converts panic to error in case of reflect value type mismatch
 
 	if !eq.queryMatches(query) {
-		return nil, fmt.Errorf("query '%s', does not match regex [%s]", query, eq.sqlRegex.String())
+		return nil, fmt.Errorf("query %s, does not match regex %s", c.sql(stmt), c.sql(eq.sqlRegex.String()))
+	}
+
+	if err = eq.upsert.check(query); err != nil {
+		return nil, fmt.Errorf("query %s, %s", c.sql(query), err)
+	}
+
+	if !eq.argsMatches(args, c.compare) {
+		return nil, fmt.Errorf("query %s, args %s%s does not match expected %s%s", c.sql(stmt), c.printArgs(args), c.preview(stmt, args), c.printArgs(eq.args), c.details(eq))
+	}
+
+	if !eq.placeholdersMatch(query, c.placeholders) {
+		return nil, fmt.Errorf("query %s has %d placeholders, but args %+v were expected", c.sql(query), countPlaceholders(query, c.placeholders), eq.args)
+	}
+
+	if err = c.chaos.fail(); err != nil {
+		return nil, c.badConn(err)
+	}
+
+	rows := eq.rows
+	if rows == nil {
+		rows = returnedRows(stmt, args, &eq.lastID)
+	}
+	if err = c.checkRows(stmt, rows); err != nil {
+		return nil, err
+	}
+
+	eq.queries = append(eq.queries, stmt)
+
+	return eq.track(c, cloneRows(rows)), err
+}
+
+// creates a statement, which is tracked until closed
+func (s *session) prepared(query string) *statement {
+	stmt := &statement{conn: s, query: query, created: callSite()}
+	s.statements = append(s.statements, stmt)
+	return stmt
+}
+
+// matches a stored procedure call, either from Exec or Query
+// and assigns output parameters if the call succeeds
+func (c *conn) call(ec *expectedCall, call, stmt, query string, args []driver.Value) (err error) {
+	trigger(ec, call)
+	if ec.err != nil {
+		ec.queries = append(ec.queries, stmt)
+		return c.badConn(ec.err) // mocked to return error
+	}
+
+	defer argMatcherErrorHandler(&err) // converts panic to error in case of reflect value type mismatch
+
+	if !ec.queryMatches(query) {
+		return fmt.Errorf("query '%s', is not a call to procedure '%s'", query, ec.procedure)
+	}
+
+	if !ec.argsMatches(args, c.compare) {
+		return fmt.Errorf("call to procedure '%s', args %+v does not match expected %+v", ec.procedure, args, ec.args)
+	}
+
+	if !ec.placeholdersMatch(query, c.placeholders) {
+		return fmt.Errorf("call to procedure '%s' has %d placeholders, but args %+v were expected", ec.procedure, countPlaceholders(query, c.placeholders), ec.args)
 	}
 
-	if !eq.argsMatches(args) {
-		return nil, fmt.Errorf("query '%s', args %+v does not match expected %+v", query, args, eq.args)
+	if err = c.chaos.fail(); err != nil {
+		return c.badConn(err)
 	}
 
-	return eq.rows, err
+	return setOutParams(args, ec.out)
 }
 
 func argMatcherErrorHandler(errp *error) {
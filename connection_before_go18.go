@@ -0,0 +1,10 @@
+//go:build !go1.8
+// +build !go1.8
+
+package sqlmock
+
+import "errors"
+
+// returned by calls, which time out without a context
+// to wait for, like context.DeadlineExceeded since go1.8
+var errDeadlineExceeded = errors.New("context deadline exceeded")
@@ -0,0 +1,18 @@
+//go:build go1.10
+// +build go1.10
+
+package sqlmock
+
+import (
+	"context"
+)
+
+// ResetSession implements driver.SessionResetter
+func (s *session) ResetSession(ctx context.Context) error {
+	return s.resetSession()
+}
+
+// IsValid implements driver.Validator
+func (s *session) IsValid() bool {
+	return s.valid()
+}
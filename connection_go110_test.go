@@ -0,0 +1,63 @@
+//go:build go1.10
+// +build go1.10
+
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"testing"
+)
+
+func TestResetSessionExpectations(t *testing.T) {
+	db, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	// connection was used by ping, so it is reset before reuse
+	ExpectResetSession()
+	ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, 1))
+
+	if _, err = db.Exec("UPDATE articles SET title = ?", "hello"); err != nil {
+		t.Errorf("error '%s' was not expected while updating articles", err)
+	}
+
+	// bad session makes database/sql continue on a fresh connection
+	ExpectResetSession().WillReturnError(driver.ErrBadConn)
+	ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, 1))
+
+	if _, err = db.Exec("UPDATE articles SET title = ?", "hello"); err != nil {
+		t.Errorf("error '%s' was not expected while updating articles", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestInvalidatedConnectionIsDiscarded(t *testing.T) {
+	db, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, 1))
+	ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, 1))
+
+	InvalidateConn()
+	if _, err = db.Exec("UPDATE articles SET title = ?", "hello"); err != nil {
+		t.Errorf("error '%s' was not expected while updating articles", err)
+	}
+
+	if n := db.Stats().OpenConnections; n != 0 {
+		t.Errorf("expected invalidated connection to be discarded, but there are %d open connections", n)
+	}
+
+	if _, err = db.Exec("UPDATE articles SET title = ?", "hello"); err != nil {
+		t.Errorf("error '%s' was not expected while updating articles on a fresh connection", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
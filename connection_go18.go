@@ -0,0 +1,23 @@
+//go:build go1.8
+// +build go1.8
+
+package sqlmock
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// returned by calls, which time out without a context to wait for
+var errDeadlineExceeded error = context.DeadlineExceeded
+
+// BeginTx implements driver.ConnBeginTx, the transaction
+// and its commit or rollback may be canceled by ctx
+func (s *session) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	return s.begin(ctx)
+}
+
+// Ping implements driver.Pinger
+func (s *session) Ping(ctx context.Context) error {
+	return s.ping(ctx)
+}
@@ -0,0 +1,254 @@
+//go:build go1.8
+// +build go1.8
+
+package sqlmock
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPingsAreIgnoredUnlessMonitored(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, 1))
+	if err = db.Ping(); err != nil {
+		t.Errorf("error '%s' was not expected while pinging", err)
+	}
+	if _, err = db.Exec("UPDATE articles SET title = ?", "hello"); err != nil {
+		t.Errorf("error '%s' was not expected while updating articles", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestMonitoredPings(t *testing.T) {
+	db, m, err := NewMock(MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectPing()
+	m.ExpectPing().WillReturnError(fmt.Errorf("database is down"))
+
+	if err = db.Ping(); err != nil {
+		t.Errorf("error '%s' was not expected while pinging", err)
+	}
+	if err = db.Ping(); err == nil || err.Error() != "database is down" {
+		t.Errorf("expected the error of the ping expectation, but got: %v", err)
+	}
+	if err = db.Ping(); err == nil {
+		t.Errorf("expected an error for a ping, which was not expected")
+	}
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
+
+func TestMonitoredPingOfBadConnection(t *testing.T) {
+	db, m, err := NewMock(MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	// the connection is discarded, so the next ping opens a fresh one
+	m.ExpectPing().WillReturnError(driver.ErrBadConn)
+	m.ExpectPing()
+
+	if err = db.Ping(); err != driver.ErrBadConn {
+		t.Errorf("expected a bad connection, but got: %v", err)
+	}
+	if err = db.Ping(); err != nil {
+		t.Errorf("error '%s' was not expected while pinging", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestDelayedBeginIsCanceledByContext(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectBegin().WillDelayFor(time.Second)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err = db.BeginTx(ctx, nil); err != context.DeadlineExceeded {
+		t.Errorf("expected the begin to time out, but got: %v", err)
+	}
+	if time.Since(start) >= time.Second {
+		t.Errorf("expected the begin to return once its context was canceled")
+	}
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
+
+func TestDelayedCommitAndRollback(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectBegin().WillDelayFor(20 * time.Millisecond)
+	m.ExpectCommit().WillDelayFor(20 * time.Millisecond)
+	m.ExpectBegin()
+	m.ExpectRollback().WillDelayFor(time.Second)
+
+	start := time.Now()
+	tx, err := db.BeginTx(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while beginning a transaction", err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while committing a transaction", err)
+	}
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("expected the begin and commit to be delayed, but they took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while beginning a transaction", err)
+	}
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	// database/sql rolls the transaction back once its context is canceled
+	start = time.Now()
+	tx.Rollback()
+	if time.Since(start) >= time.Second {
+		t.Errorf("expected the rollback to return once the context was canceled")
+	}
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
+
+func TestRequireDeadlineWithin(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectQuery("SELECT (.+) FROM users").RequireDeadlineWithin(time.Second).WillReturnRow([]string{"id"}, 1)
+	m.ExpectExec("UPDATE users").RequireDeadlineWithin(time.Second).WillReturnRowsAffected(1)
+	m.ExpectExec("DELETE FROM users").RequireDeadlineWithin(time.Second).WillReturnRowsAffected(1)
+
+	var id int
+	err = db.QueryRow("SELECT id FROM users").Scan(&id)
+	if err == nil || err.Error() != "call to query 'SELECT id FROM users' with args [] has no deadline, but one within 1s was required" {
+		t.Errorf("expected an error, since the query has no deadline, but got: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	if _, err = db.ExecContext(ctx, "UPDATE users SET name = ?", "gedi"); err == nil {
+		t.Errorf("expected an error, since the deadline of the exec is too far")
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	if _, err = db.ExecContext(ctx, "DELETE FROM users"); err != nil {
+		t.Errorf("error '%s' was not expected, the exec has a deadline within a second", err)
+	}
+
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
+
+func TestWillTimeout(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectQuery("SELECT (.+) FROM reports").WillTimeout()
+	m.ExpectExec("UPDATE reports").WillTimeout()
+	m.ExpectExec("UPDATE reports").WillReturnRowsAffected(1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err = db.QueryContext(ctx, "SELECT * FROM reports"); err != context.DeadlineExceeded {
+		t.Errorf("expected the query to time out with context.DeadlineExceeded, but got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 10*time.Millisecond {
+		t.Errorf("expected the query to wait for the deadline of its context, but it took %s", elapsed)
+	}
+
+	if _, err = db.Exec("UPDATE reports SET status = 1"); err != context.DeadlineExceeded {
+		t.Errorf("expected the exec without a context to time out immediately, but got: %v", err)
+	}
+	if _, err = db.Exec("UPDATE reports SET status = 1"); err != nil {
+		t.Errorf("error '%s' was not expected, the retried exec succeeds", err)
+	}
+
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
+
+func TestPrepareWillTimeout(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectPrepare().WillTimeout()
+
+	if _, err = db.Prepare("UPDATE reports SET status = ?"); err != context.DeadlineExceeded {
+		t.Errorf("expected the prepare without a context to time out immediately, but got: %v", err)
+	}
+
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
+
+func TestWillBlockUntilCanceled(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectQuery("SELECT (.+) FROM reports").WillBlockUntilCanceled()
+	m.ExpectExec("UPDATE reports").WillBlockUntilCanceled()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+	if _, err = db.QueryContext(ctx, "SELECT * FROM reports"); err != context.Canceled {
+		t.Errorf("expected the query to return once it was canceled, but got: %v", err)
+	}
+	if _, err = db.Exec("UPDATE reports SET status = 1"); err == nil {
+		t.Errorf("expected an error, since the exec has no context, which may be canceled")
+	}
+
+	report := m.Report()
+	if report[0].Canceled != 1 || report[1].Canceled != 0 {
+		t.Errorf("expected only the query to be reported as canceled, but got %+v", report)
+	}
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
@@ -0,0 +1,15 @@
+//go:build !go1.9
+// +build !go1.9
+
+package sqlmock
+
+import "time"
+
+// Context is the context of a driver call, as WithContextCheck gives it,
+// it has the methods of context.Context, which it is an alias of since go1.9
+type Context interface {
+	Deadline() (deadline time.Time, ok bool)
+	Done() <-chan struct{}
+	Err() error
+	Value(key interface{}) interface{}
+}
@@ -0,0 +1,9 @@
+//go:build go1.9
+// +build go1.9
+
+package sqlmock
+
+import "context"
+
+// Context is the context of a driver call, as WithContextCheck gives it
+type Context = context.Context
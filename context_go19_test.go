@@ -0,0 +1,51 @@
+//go:build go1.9
+// +build go1.9
+
+package sqlmock
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestWithContextCheck(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	tenant := func(ctx context.Context) error {
+		if id, ok := ctx.Value(tenantKey{}).(string); !ok || id != "acme" {
+			return fmt.Errorf("tenant acme was expected, but got %v", ctx.Value(tenantKey{}))
+		}
+		return nil
+	}
+	m.ExpectQuery("SELECT (.+) FROM users").WithContextCheck(tenant).WillReturnRow([]string{"id"}, 1)
+	m.ExpectExec("UPDATE users").WithContextCheck(tenant).WillReturnRowsAffected(1)
+	m.ExpectExec("DELETE FROM users").WithContextCheck(tenant).WillReturnRowsAffected(1)
+
+	var id int
+	ctx := context.WithValue(context.Background(), tenantKey{}, "acme")
+	if err = db.QueryRowContext(ctx, "SELECT id FROM users").Scan(&id); err != nil {
+		t.Errorf("error '%s' was not expected, the query has the tenant on its context", err)
+	}
+
+	ctx = context.WithValue(context.Background(), tenantKey{}, "globex")
+	_, err = db.ExecContext(ctx, "UPDATE users SET name = ?", "gedi")
+	if err == nil || err.Error() != "call to exec query 'UPDATE users SET name = ?' with args [gedi] failed the context check of exec 'UPDATE users': tenant acme was expected, but got globex" {
+		t.Errorf("expected an error, since the exec has another tenant, but got: %v", err)
+	}
+
+	_, err = db.Exec("DELETE FROM users")
+	if err == nil || err.Error() != "call to exec query 'DELETE FROM users' with args [] failed the context check of exec 'DELETE FROM users': tenant acme was expected, but got <nil>" {
+		t.Errorf("expected an error, since the exec has no tenant on its context, but got: %v", err)
+	}
+
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
@@ -0,0 +1,100 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var copyFromRe = regexp.MustCompile(`(?i)^\s*COPY\s+.+\s+FROM\s+STDIN`)
+
+// checks whether query is a COPY FROM STDIN statement
+func isCopyFrom(query string) bool {
+	return copyFromRe.MatchString(query)
+}
+
+// builds a regex matching COPY FROM STDIN of the given table and
+// columns, where identifiers may be quoted like pq.CopyIn does
+func copyFromRegex(table string, columns []string) *regexp.Regexp {
+	ident := func(name string) string {
+		return `"?` + regexp.QuoteMeta(name) + `"?`
+	}
+
+	parts := strings.Split(table, ".")
+	for i, p := range parts {
+		parts[i] = ident(p)
+	}
+
+	cols := make([]string, len(columns))
+	for i, c := range columns {
+		cols[i] = ident(c)
+	}
+
+	return regexp.MustCompile(`(?i)^\s*COPY\s+` + strings.Join(parts, `\.`) +
+		`\s*\(\s*` + strings.Join(cols, `\s*,\s*`) + `\s*\)\s+FROM\s+STDIN`)
+}
+
+// matches the prepared COPY statement
+func (e *expectedCopyFrom) prepare(s *session, call, query string) (driver.Stmt, error) {
+	if !copyFromRegex(e.table, e.columns).MatchString(query) {
+		return nil, fmt.Errorf("copy statement '%s', does not match expected table '%s' with columns %v", query, e.table, e.columns)
+	}
+	e.calls = append(e.calls, call) // triggered when the copy is finished
+	return &copyStatement{s.prepared(query), e}, nil
+}
+
+// a statement which receives copied rows
+type copyStatement struct {
+	*statement
+	e *expectedCopyFrom
+}
+
+func (stmt *copyStatement) NumInput() int {
+	return -1
+}
+
+// Exec copies a row, or finishes the copy if there are no arguments
+func (stmt *copyStatement) Exec(args []driver.Value) (res driver.Result, err error) {
+	stmt.conn.wait()
+	stmt.conn.lock()
+	defer stmt.conn.mu.Unlock()
+
+	e := stmt.e
+	if e.triggered {
+		return nil, fmt.Errorf("copy to table '%s' was already finished", e.table)
+	}
+
+	if len(args) == 0 {
+		trigger(e, fmt.Sprintf("call to finish copy to table '%s' after %d rows", e.table, e.copied))
+		if e.err != nil {
+			return nil, e.err // mocked to return error
+		}
+		if e.rows != nil && e.copied != len(e.rows) {
+			return nil, fmt.Errorf("copy to table '%s' finished after %d rows, but %d rows were expected", e.table, e.copied, len(e.rows))
+		}
+		if e.result == nil {
+			return driver.RowsAffected(e.copied), nil
+		}
+		return e.result, nil
+	}
+
+	defer argMatcherErrorHandler(&err) // converts panic to error in case of reflect value type mismatch
+
+	if e.rows != nil {
+		if e.copied == len(e.rows) {
+			return nil, fmt.Errorf("copy to table '%s' row %+v was not expected, all %d rows were already copied", e.table, args, len(e.rows))
+		}
+		expected := &queryBasedExpectation{args: e.rows[e.copied]}
+		if !expected.argsMatches(args, stmt.conn.compare) {
+			return nil, fmt.Errorf("copy to table '%s' row %+v does not match expected %+v", e.table, args, e.rows[e.copied])
+		}
+	}
+
+	e.copied++
+	return driver.RowsAffected(0), nil
+}
+
+func (stmt *copyStatement) Query(args []driver.Value) (driver.Rows, error) {
+	return nil, fmt.Errorf("copy to table '%s' does not support queries", stmt.e.table)
+}
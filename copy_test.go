@@ -0,0 +1,97 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestCopyFromRegex(t *testing.T) {
+	re := copyFromRegex("public.users", []string{"name", "age"})
+	if !re.MatchString(`COPY "public"."users" ("name", "age") FROM STDIN`) {
+		t.Error("expected quoted copy statement to match")
+	}
+
+	if !re.MatchString(`copy public.users (name,age) from stdin`) {
+		t.Error("expected unquoted copy statement to match")
+	}
+
+	if re.MatchString(`COPY "public"."users" ("name") FROM STDIN`) {
+		t.Error("expected copy statement with different columns not to match")
+	}
+}
+
+func TestCopyFromExpectations(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectBegin()
+	ExpectCopyFrom("users", "name", "age").
+		WithCopyRow("gedi", 30).
+		WithCopyRow("pieter", 25)
+	ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	stmt, err := tx.Prepare(`COPY "users" ("name", "age") FROM STDIN`)
+	if err != nil {
+		t.Errorf("error '%s' was not expected while preparing a copy statement", err)
+	}
+
+	for _, row := range [][]interface{}{{"gedi", 30}, {"pieter", 25}} {
+		if _, err = stmt.Exec(row...); err != nil {
+			t.Errorf("error '%s' was not expected while copying a row", err)
+		}
+	}
+
+	res, err := stmt.Exec()
+	if err != nil {
+		t.Errorf("error '%s' was not expected while finishing the copy", err)
+	}
+
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Errorf("expected 2 copied rows, but got %d", n)
+	}
+
+	if err = stmt.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the copy statement", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Errorf("an error '%s' was not expected when commiting a transaction", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestCopyFromRowMismatch(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectCopyFrom("users", "name").WithCopyRow("gedi")
+
+	stmt, err := db.Prepare(`COPY "users" ("name") FROM STDIN`)
+	if err != nil {
+		t.Errorf("error '%s' was not expected while preparing a copy statement", err)
+	}
+
+	if _, err = stmt.Exec("pieter"); err == nil {
+		t.Error("an error was expected, since the copied row does not match")
+	}
+
+	if _, err = stmt.Exec(); err == nil {
+		t.Error("an error was expected, since not all expected rows were copied")
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
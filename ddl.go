@@ -0,0 +1,119 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// words DDL statements start with
+var ddlWords = []string{"CREATE", "ALTER", "DROP", "TRUNCATE", "RENAME", "COMMENT"}
+
+// words after which IF EXISTS or IF NOT EXISTS may follow
+var existenceChecked = map[string]bool{
+	"TABLE": true, "VIEW": true, "INDEX": true, "SCHEMA": true, "SEQUENCE": true,
+	"TYPE": true, "EXTENSION": true, "DATABASE": true, "COLUMN": true, "FUNCTION": true,
+	"TRIGGER": true, "CONSTRAINT": true,
+}
+
+// words table constraints, which may be named, start with
+var namedConstraints = map[string]bool{
+	"PRIMARY": true, "UNIQUE": true, "FOREIGN": true, "CHECK": true,
+}
+
+// ExpectDDL expects a CREATE, ALTER, DROP, TRUNCATE, RENAME or COMMENT
+// statement to be executed. Unlike ExpectExec it is given the statement,
+// not a regex, which is matched regardless of whitespace, case, quoting
+// of identifiers, IF EXISTS and IF NOT EXISTS clauses and the names of
+// table constraints, which migrations tend to vary. The statement returns
+// driver.ResultNoRows, unless another result is set. Panics if the
+// statement is not DDL
+func ExpectDDL(statement string) Mock {
+	return mock.ExpectDDL(statement)
+}
+
+// ExpectDDL expects a DDL statement to be executed
+func (m *MockDB) ExpectDDL(statement string) Mock {
+	return m.conn.expect(ddlExpectation(statement))
+}
+
+// ExpectDDL expects a DDL statement to be executed
+func (s *ExpectationSet) ExpectDDL(statement string) Mock {
+	return s.recorder.expect(ddlExpectation(statement))
+}
+
+func ddlExpectation(statement string) *expectedExec {
+	ts := tokenize(statement)
+	if len(ts) == 0 || !hasWord(ddlWords, ts[0]) {
+		panic(fmt.Sprintf("statement '%s' is not DDL, it must start with one of %v", statement, ddlWords))
+	}
+	e := &expectedExec{what: fmt.Sprintf("DDL '%s'", statement), result: driver.ResultNoRows}
+	e.sqlRegex = ddlRegexp(ts)
+	return e
+}
+
+// builds a regex which matches the statement of the tokens,
+// the way ExpectDDL describes it
+func ddlRegexp(ts []token) *regexp.Regexp {
+	var parts []string
+	var prev *token
+	for i := 0; i < len(ts); i++ {
+		t := ts[i]
+		switch {
+		case t.is("IF") && i+2 < len(ts) && ts[i+1].is("NOT") && ts[i+2].is("EXISTS"):
+			i += 2
+			continue
+		case t.is("IF") && i+1 < len(ts) && ts[i+1].is("EXISTS"):
+			i++
+			continue
+		case t.is("CONSTRAINT") && i+2 < len(ts) && ts[i+1].ident() &&
+			ts[i+2].kind == 'w' && namedConstraints[strings.ToUpper(ts[i+2].text)]:
+			i++
+			continue
+		case t.isPunct(";") && i == len(ts)-1:
+			continue
+		}
+
+		if prev != nil {
+			parts = append(parts, ddlSeparator(*prev, t))
+		}
+		prev = &ts[i]
+		if t.kind == 'w' && namedConstraints[strings.ToUpper(t.text)] {
+			parts = append(parts, `(CONSTRAINT\s+\S+\s+)?`)
+		}
+		switch t.kind {
+		case 'w', 'q':
+			parts = append(parts, `["`+"`"+`\[]?`+regexp.QuoteMeta(t.text)+`["`+"`"+`\]]?`)
+		default:
+			parts = append(parts, regexp.QuoteMeta(t.text))
+		}
+		if t.kind == 'w' && existenceChecked[strings.ToUpper(t.text)] {
+			parts = append(parts, `(\s+IF(\s+NOT)?\s+EXISTS)?`)
+		}
+	}
+	return regexp.MustCompile(`(?i)^\s*` + strings.Join(parts, "") + `\s*;?\s*$`)
+}
+
+// matches the whitespace between two tokens, which is required
+// between words, identifiers and numbers, so that they are not
+// glued together, and optional around punctuation and strings
+func ddlSeparator(a, b token) string {
+	if ddlSpaced(a) && ddlSpaced(b) {
+		return `\s+`
+	}
+	return `\s*`
+}
+
+func ddlSpaced(t token) bool {
+	return t.kind == 'w' || t.kind == 'q' || t.kind == 'n'
+}
+
+func hasWord(words []string, t token) bool {
+	for _, w := range words {
+		if t.is(w) {
+			return true
+		}
+	}
+	return false
+}
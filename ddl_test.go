@@ -0,0 +1,95 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+)
+
+func TestDDLRegexp(t *testing.T) {
+	cases := []struct {
+		expected string
+		matching []string
+		other    []string
+	}{
+		{
+			"CREATE TABLE users (id INT NOT NULL, PRIMARY KEY (id))",
+			[]string{
+				"create table if not exists `users` (\n  id int not null,\n  constraint users_pk primary key (id)\n);",
+				`CREATE TABLE "users"( id INT NOT NULL, PRIMARY KEY(id) )`,
+			},
+			[]string{
+				"CREATE TABLE users (id INT, PRIMARY KEY (id))",
+				"CREATE TABLEusers (id INT NOT NULL, PRIMARY KEY (id))",
+				"CREATE TABLE users (idINT NOTNULL, PRIMARY KEY (id))",
+				"CREATE TABLE users (id INT NOT NULL, PRIMARY KEY (id)); DROP TABLE orders",
+			},
+		},
+		{
+			"DROP TABLE IF EXISTS orders;",
+			[]string{"DROP TABLE orders", "drop table if exists [orders]"},
+			[]string{"DROP TABLE orders_archive", "DROP VIEW orders"},
+		},
+		{
+			"ALTER TABLE orders DROP CONSTRAINT orders_user_fk",
+			[]string{"ALTER TABLE orders DROP CONSTRAINT IF EXISTS orders_user_fk"},
+			[]string{"ALTER TABLE orders DROP CONSTRAINT orders_fk"},
+		},
+	}
+	for _, c := range cases {
+		re := ddlRegexp(tokenize(c.expected))
+		for _, q := range c.matching {
+			if !re.MatchString(q) {
+				t.Errorf("expected '%s' to match DDL '%s'", q, c.expected)
+			}
+		}
+		for _, q := range c.other {
+			if re.MatchString(q) {
+				t.Errorf("expected '%s' not to match DDL '%s'", q, c.expected)
+			}
+		}
+	}
+}
+
+func TestExpectDDL(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectDDL("CREATE TABLE IF NOT EXISTS users (id INT)")
+	ExpectDDL("TRUNCATE users").WillReturnResult(NewResult(0, 3))
+
+	res, err := db.Exec("CREATE TABLE users (\n  id INT\n)")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while creating the table", err)
+	}
+	if _, err = res.RowsAffected(); err == nil {
+		t.Errorf("expected the result to be driver.ResultNoRows, but rows affected were returned")
+	}
+	if res, err = db.Exec("truncate `users`"); err != nil {
+		t.Fatalf("error '%s' was not expected while truncating the table", err)
+	}
+	if n, _ := res.RowsAffected(); n != 3 {
+		t.Errorf("expected the result set for the DDL, but got %d rows affected", n)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic, since an insert is not DDL")
+		}
+	}()
+	ExpectDDL("INSERT INTO users (id) VALUES (1)")
+}
+
+func TestDDLResult(t *testing.T) {
+	if e := ddlExpectation("DROP INDEX users_name"); e.result != driver.ResultNoRows {
+		t.Errorf("expected DDL to return driver.ResultNoRows by default, but got %v", e.result)
+	}
+	if s := ddlExpectation("DROP INDEX users_name").String(); s != "DDL 'DROP INDEX users_name'" {
+		t.Errorf("unexpected description of the DDL expectation: %s", s)
+	}
+}
@@ -0,0 +1,97 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"math/big"
+	"reflect"
+	"strconv"
+)
+
+// converts a value to a rational number, if it is a number
+// or a string holding a decimal, like drivers send decimals
+func decimal(v interface{}) (*big.Rat, bool) {
+	switch v := v.(type) {
+	case *big.Int:
+		if v == nil {
+			return nil, false
+		}
+		return new(big.Rat).SetInt(v), true
+	case *big.Rat:
+		return v, v != nil
+	case int64:
+		return new(big.Rat).SetInt64(v), true
+	case float64:
+		return new(big.Rat).SetString(strconv.FormatFloat(v, 'g', -1, 64))
+	case string:
+		return new(big.Rat).SetString(v)
+	case []byte:
+		return new(big.Rat).SetString(string(v))
+	}
+	return nil, false
+}
+
+// converts a value of any integer or floating point kind to
+// a rational number, floats by their shortest representation
+func number(v interface{}) (*big.Rat, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return new(big.Rat).SetInt64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Rat).SetInt(new(big.Int).SetUint64(rv.Uint())), true
+	case reflect.Float32:
+		return new(big.Rat).SetString(strconv.FormatFloat(rv.Float(), 'g', -1, 32))
+	case reflect.Float64:
+		return new(big.Rat).SetString(strconv.FormatFloat(rv.Float(), 'g', -1, 64))
+	}
+	return nil, false
+}
+
+// compares an argument with an expected arbitrary precision number
+// or a driver.Valuer, like a decimal type, by value. Reports whether
+// the expected value is one of them
+func decimalMatches(expected, v driver.Value, cmp comparison) (matches, ok bool) {
+	switch expected.(type) {
+	case *big.Int, *big.Rat:
+		e, ok := decimal(expected)
+		if !ok {
+			return v == nil, true // a nil number expects NULL
+		}
+		a, ok := decimal(v)
+		return ok && e.Cmp(a) == 0, true
+	case driver.Valuer:
+		ev, err := expected.(driver.Valuer).Value()
+		if err != nil {
+			return false, true
+		}
+		if _, isString := ev.(string); isString {
+			if e, ok := decimal(ev); ok {
+				a, ok := decimal(v)
+				return ok && e.Cmp(a) == 0, true
+			}
+		}
+		return argMatches(ev, v, cmp), true
+	}
+	return false, false
+}
+
+// the exact decimal representation of an arbitrary precision
+// number, which is sent to database/sql like a driver would
+func decimalString(v interface{}) (string, bool) {
+	switch v := v.(type) {
+	case *big.Int:
+		return v.String(), v != nil
+	case *big.Rat:
+		if v == nil {
+			return "", false
+		}
+		for prec := 0; prec < 64; prec++ {
+			s := v.FloatString(prec)
+			if r, _ := new(big.Rat).SetString(s); r.Cmp(v) == 0 {
+				return s, true
+			}
+		}
+		return v.FloatString(64), true // not a finite decimal, rounded
+	}
+	return "", false
+}
@@ -0,0 +1,95 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"math/big"
+	"testing"
+)
+
+// a decimal type like shopspring/decimal, sent as a string
+type testDecimal string
+
+func (d testDecimal) Value() (driver.Value, error) {
+	return string(d), nil
+}
+
+func TestDecimalArguments(t *testing.T) {
+	price, _ := new(big.Rat).SetString("10.50")
+	cases := []struct {
+		expected, actual driver.Value
+		matches          bool
+	}{
+		{big.NewInt(42), int64(42), true},
+		{big.NewInt(42), "42", true},
+		{big.NewInt(42), "42.0", true},
+		{big.NewInt(42), int64(43), false},
+		{big.NewInt(42), "forty two", false},
+		{price, "10.5", true},
+		{price, []byte("10.500"), true},
+		{price, float64(10.5), true},
+		{price, "10.51", false},
+		{price, (*big.Int)(nil), false},
+		{(*big.Int)(nil), nil, true},
+		{(*big.Int)(nil), int64(0), false},
+		{testDecimal("1.50"), "1.5", true},
+		{testDecimal("1.50"), "1.49", false},
+		{testDecimal("n/a"), "n/a", true},
+		{testDecimal("n/a"), "N/A", false},
+		{sql.NullInt64{Int64: 5, Valid: true}, int64(5), true},
+		{sql.NullInt64{}, nil, true},
+	}
+	for _, c := range cases {
+		if argMatches(c.expected, c.actual, comparison{}) != c.matches {
+			t.Errorf("expected match of %v against %v to be %v", c.actual, c.expected, c.matches)
+		}
+	}
+
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectExec("UPDATE products").
+		WithArgs(price, 1).
+		WillReturnRowsAffected(1)
+
+	if _, err = db.Exec("UPDATE products SET price = ? WHERE id = ?", testDecimal("10.500"), 1); err != nil {
+		t.Errorf("error '%s' was not expected while updating a price", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestDecimalRowValues(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	total, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	ExpectQuery("SELECT (.+) FROM accounts").
+		WillReturnRows(NewRows([]string{"total", "rate", "third"}).
+			AddRow(total, big.NewRat(3, 8), big.NewRat(1, 3)))
+
+	var tot, rate, third string
+	if err = db.QueryRow("SELECT total, rate, third FROM accounts").Scan(&tot, &rate, &third); err != nil {
+		t.Errorf("error '%s' was not expected while scanning decimals", err)
+	}
+
+	if tot != "123456789012345678901234567890" {
+		t.Errorf("expected big integer to be returned as its decimal string, but got %s", tot)
+	}
+	if rate != "0.375" {
+		t.Errorf("expected rational to be returned as an exact decimal string, but got %s", rate)
+	}
+	if len(third) != 66 || third[:6] != "0.3333" {
+		t.Errorf("expected a repeating decimal to be rounded, but got %s", third)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build go1.10 && !go1.17
+// +build go1.10,!go1.17
+
+package sqlmock
+
+// database/sql does not close the connector, so expectations are
+// verified once the last connection of the database is closed
+const connectorClosed = false
@@ -0,0 +1,39 @@
+//go:build go1.10
+// +build go1.10
+
+package sqlmock
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// OpenConnector implements driver.DriverContext, so that the data
+// source name is resolved to its mock once, rather than every time
+// the pool opens a connection
+func (d *mockDriver) OpenConnector(dsn string) (driver.Connector, error) {
+	return &connector{driver: d, mock: d.lookup(dsn), pool: &pool{deferred: connectorClosed}}, nil
+}
+
+// opens connections to a mock, the pool may
+// call it concurrently, like it does with drivers
+type connector struct {
+	driver *mockDriver
+	mock   *MockDB
+	pool   *pool // of the database the connector opens connections for
+}
+
+// Connect implements driver.Connector, unless ctx is done
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	default:
+	}
+	return c.mock.conn.open(c.pool)
+}
+
+// Driver implements driver.Connector
+func (c *connector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,61 @@
+//go:build go1.10
+// +build go1.10
+
+package sqlmock
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOpenConnector(t *testing.T) {
+	_, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	connector, err := driverInstance.OpenConnector(m.(*MockDB).dsn)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while opening a connector", err)
+	}
+	if connector.Driver() != driverInstance {
+		t.Errorf("expected the connector to report the mock driver, but got %T", connector.Driver())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = connector.Connect(ctx); err != context.Canceled {
+		t.Errorf("expected the connection to be canceled, but got: %v", err)
+	}
+
+	db := sql.OpenDB(connector)
+	db.SetMaxOpenConns(5)
+	m.ExpectExec("UPDATE articles").Times(10).WillDelayFor(10 * time.Millisecond).WillReturnRowsAffected(1)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := db.Exec("UPDATE articles SET views = views + 1")
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("error '%s' was not expected while updating articles concurrently", err)
+		}
+	}
+	if open := db.Stats().OpenConnections; open < 2 {
+		t.Errorf("expected the pool to open connections concurrently, but %d is open", open)
+	}
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
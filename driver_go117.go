@@ -0,0 +1,27 @@
+//go:build go1.17
+// +build go1.17
+
+package sqlmock
+
+import "fmt"
+
+// database/sql closes the connector as the database is closed
+const connectorClosed = true
+
+// Close implements io.Closer, which database/sql calls as the database
+// is closed, after its idle connections were. The expectations are
+// verified then, connections still in use, like by rows or transactions
+// left open, are reported as leaks
+func (c *connector) Close() error {
+	conn := c.mock.conn
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	c.pool.closed = true
+	err := conn.verify()
+	if n := c.pool.open - c.pool.held; err == nil && n > 0 {
+		err = fmt.Errorf("%d connections were leaked, they were still in use as the database was closed", n)
+	}
+	conn.reset()
+	return err
+}
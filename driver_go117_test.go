@@ -0,0 +1,62 @@
+//go:build go1.17
+// +build go1.17
+
+package sqlmock
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestClosingDatabaseVerifiesWhileRowsAreOpen(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1).AddRow(2))
+	ExpectExec("UPDATE never").WillReturnRowsAffected(1)
+
+	rows, err := db.Query("SELECT id FROM users")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while selecting users", err)
+	}
+	defer rows.Close()
+
+	err = db.Close()
+	if err == nil || !strings.Contains(err.Error(), "which was not matched yet") {
+		t.Errorf("expected closing the database to report the remaining expectation, but got %v", err)
+	}
+
+	db, err = New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	ExpectExec("UPDATE users").WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE users SET name = ?", "gedi"); err != nil {
+		t.Errorf("error '%s' was not expected, since the expectations of the closed database were reset", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestClosingDatabaseReportsLeakedConnections(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		t.Errorf("error '%s' was not expected while grabbing a connection", err)
+	}
+
+	err = db.Close()
+	if err == nil || !strings.Contains(err.Error(), "1 connections were leaked") {
+		t.Errorf("expected closing the database to report the leaked connection, but got %v", err)
+	}
+	conn.Close()
+}
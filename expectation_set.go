@@ -0,0 +1,228 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"regexp"
+)
+
+// ExpectationSet is a reusable sequence of expectations, like
+// "standard order lookup" or "audit insert", which is declared
+// once and applied to the mock in as many tests as needed:
+//
+//	var auditInsert = sqlmock.NewExpectationSet()
+//	auditInsert.ExpectExec("INSERT INTO audit").WillReturnResult(sqlmock.NewResult(1, 1))
+//
+//	sqlmock.Apply(auditInsert)
+//
+// Sets may be composed of other sets, every application of a set
+// declares fresh copies of its expectations
+type ExpectationSet struct {
+	recorder *conn
+}
+
+// NewExpectationSet creates an expectation set, which starts
+// with the expectations of the given sets in order
+func NewExpectationSet(sets ...*ExpectationSet) *ExpectationSet {
+	s := &ExpectationSet{&conn{}}
+	s.Include(sets...)
+	return s
+}
+
+// Include appends the expectations of the given sets in order
+func (s *ExpectationSet) Include(sets ...*ExpectationSet) *ExpectationSet {
+	for _, set := range sets {
+		for _, e := range copyExpectations(set.recorder.expectations) {
+			s.recorder.expect(e)
+		}
+	}
+	return s
+}
+
+// ExpectBegin expects transaction to be started
+func (s *ExpectationSet) ExpectBegin() Mock {
+	return s.recorder.expect(&expectedBegin{})
+}
+
+// ExpectCommit expects transaction to be commited
+func (s *ExpectationSet) ExpectCommit() Mock {
+	return s.recorder.expect(&expectedCommit{})
+}
+
+// ExpectRollback expects transaction to be rolled back
+func (s *ExpectationSet) ExpectRollback() Mock {
+	return s.recorder.expect(&expectedRollback{})
+}
+
+// ExpectPrepare expects Query to be prepared
+func (s *ExpectationSet) ExpectPrepare() Mock {
+	return s.recorder.expect(&expectedPrepare{})
+}
+
+// ExpectExec expects database Exec to be triggered, which will match
+// the given query string as a regular expression
+func (s *ExpectationSet) ExpectExec(sqlRegexStr string) Mock {
+	e := &expectedExec{}
+	e.sqlRegex = regexp.MustCompile(sqlRegexStr)
+	return s.recorder.expect(e)
+}
+
+// ExpectQuery database Query to be triggered, which will match
+// the given query string as a regular expression
+func (s *ExpectationSet) ExpectQuery(sqlRegexStr string) Mock {
+	e := &expectedQuery{}
+	e.sqlRegex = regexp.MustCompile(sqlRegexStr)
+	return s.recorder.expect(e)
+}
+
+// ExpectCall expects a stored procedure with the given name to be called
+func (s *ExpectationSet) ExpectCall(procedure string) Mock {
+	e := &expectedCall{procedure: procedure}
+	e.sqlRegex = procedureRegex(procedure)
+	e.unanchored = true // matches the beginning of a call only
+	return s.recorder.expect(e)
+}
+
+// ExpectCopyFrom expects a postgres COPY FROM STDIN statement
+// for the given table and columns to be prepared
+func (s *ExpectationSet) ExpectCopyFrom(table string, columns ...string) Mock {
+	return s.recorder.expect(&expectedCopyFrom{table: table, columns: columns})
+}
+
+// ExpectResetSession expects database/sql to reset the
+// session of a pooled connection before it is reused
+func (s *ExpectationSet) ExpectResetSession() Mock {
+	return s.recorder.expect(&expectedResetSession{})
+}
+
+// ExpectBadConnThenRecover expects the next driver call
+// to fail with driver.ErrBadConn
+func (s *ExpectationSet) ExpectBadConnThenRecover() Mock {
+	return s.recorder.expect(&expectedBadConn{})
+}
+
+// InOrder groups the given expectations of the set,
+// which must be matched in the given order
+func (s *ExpectationSet) InOrder(mocks ...Mock) Mock {
+	return s.recorder.group(true, mocks)
+}
+
+// InAnyOrder groups the given expectations of the set,
+// which may be matched in any order
+func (s *ExpectationSet) InAnyOrder(mocks ...Mock) Mock {
+	return s.recorder.group(false, mocks)
+}
+
+// Apply declares the expectations of the given sets in order,
+// as if they were declared one by one
+func Apply(sets ...*ExpectationSet) {
+	mock.Apply(sets...)
+}
+
+// Apply declares the expectations of the given sets on the mock
+func (m *MockDB) Apply(sets ...*ExpectationSet) {
+	for _, set := range sets {
+		for _, e := range copyExpectations(set.recorder.expectations) {
+			m.conn.expect(e)
+		}
+	}
+}
+
+// makes copies of declared expectations, so that every
+// copy is fulfilled on its own. Groups and order constraints
+// are copied along and refer to the copies
+func copyExpectations(es []expectation) []expectation {
+	copies := make(map[expectation]expectation)
+
+	var copyOf func(e expectation) expectation
+	copyOf = func(e expectation) expectation {
+		if c, ok := copies[e]; ok {
+			return c
+		}
+		v := reflect.ValueOf(e).Elem()
+		cv := reflect.New(v.Type())
+		cv.Elem().Set(v)
+		c := cv.Interface().(expectation)
+		copies[e] = c
+		copyReferences(c)
+
+		common := c.common()
+		if common.group != nil {
+			common.group = copyOf(common.group).(*expectedGroup)
+		}
+		after := make([]expectation, len(common.after))
+		for i, other := range common.after {
+			after[i] = copyOf(other)
+		}
+		common.after = after
+		if common.through != nil {
+			common.through = copyOf(common.through).(*expectedPrepare)
+		}
+
+		if g, ok := c.(*expectedGroup); ok {
+			members := make([]expectation, len(g.members))
+			for i, member := range g.members {
+				members[i] = copyOf(member)
+			}
+			g.members = members
+		}
+		return c
+	}
+
+	result := make([]expectation, len(es))
+	for i, e := range es {
+		result[i] = copyOf(e)
+	}
+	return result
+}
+
+// replaces the slices and maps a shallow copy shares with
+// its original by copies, keeping nil ones nil since some
+// of them tell apart expecting nothing from expecting anything
+func copyReferences(e expectation) {
+	common := e.common()
+	common.calls = append([]string(nil), common.calls...)
+	common.responses = append(([]func(expectation))(nil), common.responses...)
+	common.stmts = append([]*statement(nil), common.stmts...)
+
+	if qe := queryBased(e); qe != nil {
+		if qe.args != nil {
+			qe.args = append(make([]driver.Value, 0, len(qe.args)), qe.args...)
+		}
+		if qe.argsAt != nil {
+			argsAt := make(map[int]driver.Value, len(qe.argsAt))
+			for pos, arg := range qe.argsAt {
+				argsAt[pos] = arg
+			}
+			qe.argsAt = argsAt
+		}
+		if qe.upsert != nil {
+			u := *qe.upsert
+			u.target = append([]string(nil), u.target...)
+			u.set = append([]string(nil), u.set...)
+			qe.upsert = &u
+		}
+		qe.queries = append([]string(nil), qe.queries...)
+	}
+
+	switch e := e.(type) {
+	case *expectedQuery:
+		e.returned = append([]*trackedRows(nil), e.returned...)
+	case *expectedCall:
+		e.returned = append([]*trackedRows(nil), e.returned...)
+		if e.out != nil {
+			e.out = append(make([]driver.Value, 0, len(e.out)), e.out...)
+		}
+	case *expectedPrepare:
+		e.prepared = append([]*statement(nil), e.prepared...)
+	case *expectedCopyFrom:
+		e.columns = append([]string(nil), e.columns...)
+		if e.rows != nil {
+			rows := make([][]driver.Value, len(e.rows))
+			for i, row := range e.rows {
+				rows[i] = append(make([]driver.Value, 0, len(row)), row...)
+			}
+			e.rows = rows
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestExpectationSets(t *testing.T) {
+	lookup := NewExpectationSet()
+	lookup.ExpectQuery("SELECT (.+) FROM orders WHERE id = ?").
+		WithArgs(1).
+		WillReturnRows(NewRows([]string{"id", "status"}).AddRow(1, 0))
+
+	audit := NewExpectationSet()
+	audit.ExpectExec("INSERT INTO audit").WillReturnResult(NewResult(1, 1))
+
+	cancel := NewExpectationSet(lookup)
+	cancel.ExpectBegin()
+	cancel.ExpectExec("UPDATE orders SET status").WithArgs(1).WillReturnResult(NewResult(0, 1))
+	cancel.Include(audit)
+	cancel.ExpectCommit()
+
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	// the same set applied twice is fulfilled twice
+	Apply(cancel, cancel)
+
+	for i := 0; i < 2; i++ {
+		var id, status int
+		if err = db.QueryRow("SELECT id, status FROM orders WHERE id = ?", 1).Scan(&id, &status); err != nil {
+			t.Errorf("error '%s' was not expected while looking up the order", err)
+		}
+
+		tx, err := db.Begin()
+		if err != nil {
+			t.Errorf("an error '%s' was not expected when beginning a transaction", err)
+		}
+
+		if _, err = tx.Exec("UPDATE orders SET status = ?", 1); err != nil {
+			t.Errorf("error '%s' was not expected while updating the order", err)
+		}
+
+		if _, err = tx.Exec("INSERT INTO audit (action) VALUES ('cancel')"); err != nil {
+			t.Errorf("error '%s' was not expected while inserting an audit record", err)
+		}
+
+		if err = tx.Commit(); err != nil {
+			t.Errorf("an error '%s' was not expected when commiting a transaction", err)
+		}
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+
+	// the set itself is never fulfilled
+	for _, e := range cancel.recorder.expectations {
+		if e.fulfilled() {
+			t.Errorf("expected expectation %T of the set not to be fulfilled", e)
+		}
+	}
+}
+
+func TestExpectationSetKeepsOrderConstraints(t *testing.T) {
+	s := NewExpectationSet()
+	update := s.ExpectExec("UPDATE orders").WillReturnResult(NewResult(0, 1))
+	s.ExpectExec("INSERT INTO audit").WillReturnResult(NewResult(1, 1)).After(update)
+
+	Apply(s)
+	defer Reset()
+
+	es := mock.conn.expectations
+	if after := es[1].common().after; len(after) != 1 || after[0] != es[0] {
+		t.Errorf("expected applied audit insert to be matched after the applied order update, but got %v", after)
+	}
+}
+
+func TestExpectationSetKeepsSuccessiveResponses(t *testing.T) {
+	s := NewExpectationSet()
+	s.ExpectExec("UPDATE orders").Times(2).WillReturnRowsAffected(1).ThenReturnError(sql.ErrConnDone)
+
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	m.Apply(s)
+
+	if _, err = db.Exec("UPDATE orders SET status = 1"); err != nil {
+		t.Errorf("error '%s' was not expected on the first update", err)
+	}
+	if _, err = db.Exec("UPDATE orders SET status = 1"); err != sql.ErrConnDone {
+		t.Errorf("expected the second update to fail with the error of the set, but got: %v", err)
+	}
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	// the responses are set on the applied copy, not on the set
+	e := s.recorder.expectations[0].(*expectedExec)
+	if e.err != nil || e.matched != 0 {
+		t.Errorf("expected the expectation of the set to be untouched, but got error %v and %d matches", e.err, e.matched)
+	}
+	db.Close()
+}
+
+func TestExpectationSetCopiesArguments(t *testing.T) {
+	s := NewExpectationSet()
+	s.ExpectExec("UPDATE orders").WithArgs(1, 2).WithArgAt(0, 1).WillReturnResult(NewResult(0, 1))
+
+	db1, m1, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	db2, m2, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db1.Close()
+	defer db2.Close()
+	m1.Apply(s)
+	m2.Apply(s)
+
+	e1 := queryBased(m1.(*MockDB).conn.expectations[0])
+	e1.args[1] = 3
+	e1.argsAt[0] = 4
+
+	for _, e := range []*queryBasedExpectation{queryBased(m2.(*MockDB).conn.expectations[0]), queryBased(s.recorder.expectations[0])} {
+		if e.args[1] != 2 {
+			t.Errorf("expected the args of other copies to be untouched, but got %v", e.args)
+		}
+		if e.argsAt[0] != 1 {
+			t.Errorf("expected the args at positions of other copies to be untouched, but got %v", e.argsAt)
+		}
+	}
+}
@@ -2,8 +2,11 @@ package sqlmock
 
 import (
 	"database/sql/driver"
+	"fmt"
 	"reflect"
 	"regexp"
+	"regexp/syntax"
+	"time"
 )
 
 // Argument interface allows to match
@@ -12,23 +15,98 @@ type Argument interface {
 	Match(driver.Value) bool
 }
 
+// values of a slice, which match as many arguments
+type expanded []driver.Value
+
+// Expand allows a slice or an array given to WithArgs to match as
+// many arguments, like the ones of an IN (?, ?, ?) clause built for
+// the slice. Unless placeholders are permissive, the query must have
+// a placeholder for every argument as well
+func Expand(slice interface{}) driver.Value {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		panic(fmt.Sprintf("only a slice or an array may be expanded, but got %T", slice))
+	}
+	values := make(expanded, v.Len())
+	for i := range values {
+		values[i] = v.Index(i).Interface()
+	}
+	return values
+}
+
+// flattens expanded args in place, reports whether there were any
+func flatten(args []driver.Value) ([]driver.Value, bool) {
+	var flat []driver.Value
+	var found bool
+	for _, arg := range args {
+		if values, ok := arg.(expanded); ok {
+			flat, found = append(flat, values...), true
+			continue
+		}
+		flat = append(flat, arg)
+	}
+	if found && flat == nil {
+		flat = []driver.Value{} // an empty slice expects no args
+	}
+	return flat, found
+}
+
 // an expectation interface
 type expectation interface {
+	fmt.Stringer
 	fulfilled() bool
 	setError(err error)
+	common() *commonExpectation
 }
 
 // common expectation struct
 // satisfies the expectation interface
 type commonExpectation struct {
 	triggered bool
+	matched   int
+	times     int                   // calls to be matched, once if zero
+	calls     []string              // matched against this expectation
+	responses []func(e expectation) // to successive calls, the last one repeats
+	declared  string                // file and line of the declaration
 	err       error
+	delay     time.Duration       // before the matched call returns
+	timeout   bool                // the matched call waits until its context is done
+	blocking  bool                // the matched call waits until its context is canceled
+	canceled  int                 // calls which returned early, since their context was done
+	deadline  time.Duration       // the context of the call must end within
+	ctxCheck  func(Context) error // the context of the call must pass
+	inTx      bool                // the matched call must be made within a transaction
+	reuse     bool                // the matched calls must be made through a single statement
+	stmts     []*statement        // the matched calls were made through, nil if made directly
+	through   *expectedPrepare    // the matched calls must be made through a statement it prepared
+	after     []expectation       // must be fulfilled before this one
+	group     *expectedGroup
+	conn      *expectedConn // the dedicated connection it is scoped to
+}
+
+func (e *commonExpectation) common() *commonExpectation {
+	return e
 }
 
 func (e *commonExpectation) fulfilled() bool {
 	return e.triggered
 }
 
+// marks the expectation as triggered by the given call, once it was
+// matched as many times as expected, and sets up the response to it
+func trigger(e expectation, call string) {
+	common := e.common()
+	common.matched++
+	common.triggered = common.matched >= common.times
+	common.calls = append(common.calls, call)
+	if n := len(common.responses); n > 0 {
+		if common.matched < n {
+			n = common.matched
+		}
+		common.responses[n-1](e)
+	}
+}
+
 func (e *commonExpectation) setError(err error) {
 	e.err = err
 }
@@ -37,15 +115,121 @@ func (e *commonExpectation) setError(err error) {
 // adds a query matching logic
 type queryBasedExpectation struct {
 	commonExpectation
-	sqlRegex *regexp.Regexp
-	args     []driver.Value
+	sqlRegex   *regexp.Regexp
+	regex      *regexp.Regexp // sqlRegex normalized like queries, if needed
+	unanchored bool           // even if regexes are anchored
+	args       []driver.Value
+	argsAt     map[int]driver.Value // expected at zero based positions
+	expanded   bool                 // some args were expanded from a slice
+	upsert     *upsert              // the conflict handling expected, if any
+	queries    []string             // of the calls matched against it, as they were made
+}
+
+// describes the expected query and args, if any
+func (e *queryBasedExpectation) describe(kind string) string {
+	s := fmt.Sprintf("%s '%s'", kind, e.sqlRegex)
+	if e.args != nil {
+		s += fmt.Sprintf(" with args %+v", e.args)
+	}
+	if e.argsAt != nil {
+		s += fmt.Sprintf(" with args at positions %+v", e.argsAt)
+	}
+	return s
+}
+
+// the query based part of an expectation, if it has one
+func queryBased(e expectation) *queryBasedExpectation {
+	switch e := e.(type) {
+	case *expectedQuery:
+		return &e.queryBasedExpectation
+	case *expectedExec:
+		return &e.queryBasedExpectation
+	case *expectedCall:
+		return &e.queryBasedExpectation
+	}
+	return nil
+}
+
+// panics if the regex matches a single whole query only, like an
+// anchored literal does, which has as many placeholders of the style
+// as there are expected args. So that the mistake is reported where
+// the expectation is declared, instead of as a mismatch of a call
+func (e *queryBasedExpectation) validateArgs(style PlaceholderStyle) {
+	re := e.sqlRegex
+	if e.regex != nil {
+		re = e.regex
+	}
+	query, ok := exactQuery(re)
+	if !ok || e.expanded {
+		return
+	}
+	if n := countPlaceholders(query, style); n >= 0 && n != len(e.args) {
+		panic(fmt.Sprintf("query '%s' has %d placeholders, but %d args %+v were expected", query, n, len(e.args), e.args))
+	}
+}
+
+// the query the regex matches, if it is a literal anchored
+// at both ends, so that it matches no other query
+func exactQuery(re *regexp.Regexp) (string, bool) {
+	tree, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	tree = tree.Simplify()
+	if tree.Op != syntax.OpConcat || len(tree.Sub) != 3 {
+		return "", false
+	}
+	begin, lit, end := tree.Sub[0], tree.Sub[1], tree.Sub[2]
+	if !isBegin(begin.Op) || lit.Op != syntax.OpLiteral || !isEnd(end.Op) {
+		return "", false
+	}
+	return string(lit.Rune), true
+}
+
+// checks whether the query has a placeholder for every
+// expected arg, if some of them were expanded from a slice
+func (e *queryBasedExpectation) placeholdersMatch(sql string, style PlaceholderStyle) bool {
+	if !e.expanded || style == PermissivePlaceholders {
+		return true
+	}
+	return countPlaceholders(sql, style) == len(e.args)
 }
 
 func (e *queryBasedExpectation) queryMatches(sql string) bool {
+	if e.regex != nil {
+		return e.regex.MatchString(sql)
+	}
 	return e.sqlRegex.MatchString(sql)
 }
 
-func (e *queryBasedExpectation) argsMatches(args []driver.Value) bool {
+// checks whether both query and args match, a type
+// mismatch of args is not a match
+func (e *queryBasedExpectation) matches(sql string, args []driver.Value, cmp comparison) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	return e.arityMatches(len(args)) && e.queryMatches(sql) && e.upsert.check(sql) == nil && e.argsMatches(args, cmp)
+}
+
+// checks whether the number of args may match, before
+// more expensive matching of the query and args
+func (e *queryBasedExpectation) arityMatches(n int) bool {
+	for pos := range e.argsAt {
+		if pos >= n {
+			return false
+		}
+	}
+	return e.args == nil || len(e.args) == n
+}
+
+func (e *queryBasedExpectation) argsMatches(args []driver.Value, cmp comparison) bool {
+	for n, expected := range e.argsAt {
+		if n >= len(args) || !argMatches(expected, args[n], cmp) {
+			return false
+		}
+	}
 	if nil == e.args {
 		return true
 	}
@@ -53,62 +237,162 @@ func (e *queryBasedExpectation) argsMatches(args []driver.Value) bool {
 		return false
 	}
 	for k, v := range args {
-		matcher, ok := e.args[k].(Argument)
-		if ok {
-			if !matcher.Match(v) {
-				return false
-			}
-			continue
+		if !argMatches(e.args[k], v, cmp) {
+			return false
+		}
+	}
+	return true
+}
+
+// options of comparing arguments with the expected values
+type comparison struct {
+	exactTimeLocation bool
+	exactTypes        bool // arguments match only values of the same type
+	numericValues     bool // numbers match equal ones of any numeric type
+}
+
+// compares an argument with the expected value
+func argMatches(expected, v driver.Value, cmp comparison) bool {
+	matcher, ok := expected.(Argument)
+	if ok {
+		return matcher.Match(v)
+	}
+	if et, ok := expected.(time.Time); ok {
+		// the same instant matches regardless of the monotonic
+		// clock reading and, unless required, the location
+		t, ok := v.(time.Time)
+		if cmp.exactTimeLocation && ok && t.Location().String() != et.Location().String() {
+			return false
 		}
-		vi := reflect.ValueOf(v)
-		ai := reflect.ValueOf(e.args[k])
-		switch vi.Kind() {
-		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-			if vi.Int() != ai.Int() {
-				return false
-			}
-		case reflect.Float32, reflect.Float64:
-			if vi.Float() != ai.Float() {
-				return false
-			}
-		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-			if vi.Uint() != ai.Uint() {
-				return false
-			}
-		case reflect.String:
-			if vi.String() != ai.String() {
-				return false
-			}
-		default:
-			// compare types like time.Time based on type only
-			if vi.Kind() != ai.Kind() {
-				return false
-			}
+		return ok && t.Equal(et)
+	}
+	if matches, ok := decimalMatches(expected, v, cmp); ok {
+		return matches
+	}
+	if cmp.numericValues {
+		if e, ok := number(expected); ok {
+			a, ok := number(v)
+			return ok && e.Cmp(a) == 0
+		}
+	}
+	if cmp.exactTypes {
+		return reflect.TypeOf(v) == reflect.TypeOf(expected) && reflect.DeepEqual(v, expected)
+	}
+	vi := reflect.ValueOf(v)
+	ai := reflect.ValueOf(expected)
+	switch vi.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return vi.Int() == ai.Int()
+	case reflect.Float32, reflect.Float64:
+		return vi.Float() == ai.Float()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return vi.Uint() == ai.Uint()
+	case reflect.String:
+		return vi.String() == ai.String()
+	}
+	// compare other types based on type only
+	return vi.Kind() == ai.Kind()
+}
+
+// group of expectations, matched either in order or in any order,
+// it is fulfilled when all of them are
+type expectedGroup struct {
+	commonExpectation
+
+	ordered bool
+	members []expectation
+}
+
+func (g *expectedGroup) fulfilled() bool {
+	for _, e := range g.members {
+		if !e.fulfilled() {
+			return false
 		}
 	}
 	return true
 }
 
+func (g *expectedGroup) String() string {
+	order := "in any order"
+	if g.ordered {
+		order = "in order"
+	}
+	return fmt.Sprintf("group of %d expectations %s", len(g.members), order)
+}
+
+func (g *expectedGroup) setError(err error) {
+	panic(fmt.Sprintf("error may be returned only by a single expectation, current is %T", g))
+}
+
 // begin transaction
 type expectedBegin struct {
 	commonExpectation
 }
 
+func (e *expectedBegin) String() string {
+	return "begin transaction"
+}
+
 // tx commit
 type expectedCommit struct {
 	commonExpectation
 }
 
+func (e *expectedCommit) String() string {
+	return "commit transaction"
+}
+
 // tx rollback
 type expectedRollback struct {
 	commonExpectation
 }
 
+func (e *expectedRollback) String() string {
+	return "rollback transaction"
+}
+
+// ping of the connection, matched only when pings are monitored
+type expectedPing struct {
+	commonExpectation
+}
+
+func (e *expectedPing) String() string {
+	return "ping"
+}
+
+// session reset before the connection is reused
+type expectedResetSession struct {
+	commonExpectation
+}
+
+func (e *expectedResetSession) String() string {
+	return "session reset"
+}
+
+// bad connection, matched by any driver call
+type expectedBadConn struct {
+	commonExpectation
+}
+
+func (e *expectedBadConn) String() string {
+	return "bad connection on the next driver call"
+}
+
 // query expectation
 type expectedQuery struct {
 	queryBasedExpectation
+	rowsTracker
 
-	rows driver.Rows
+	rows      driver.Rows
+	returning string // the table of an expected INSERT ... RETURNING, if set
+	lastID    int64  // generated for rows returned by it
+}
+
+func (e *expectedQuery) String() string {
+	if e.returning != "" {
+		return fmt.Sprintf("insert into %s returning rows", e.returning)
+	}
+	return e.describe("query")
 }
 
 // exec query expectation
@@ -116,6 +400,14 @@ type expectedExec struct {
 	queryBasedExpectation
 
 	result driver.Result
+	what   string // describes what was expected instead of the regex, if set
+}
+
+func (e *expectedExec) String() string {
+	if e.what != "" {
+		return e.what
+	}
+	return e.describe("exec")
 }
 
 // Prepare expectation
@@ -123,4 +415,45 @@ type expectedPrepare struct {
 	commonExpectation
 
 	statement driver.Stmt
+	prepared  []*statement // when it was matched
+}
+
+func (e *expectedPrepare) String() string {
+	if e.inTx {
+		return "prepare statement within a transaction"
+	}
+	return "prepare statement"
+}
+
+// stored procedure call expectation
+type expectedCall struct {
+	queryBasedExpectation
+	rowsTracker
+
+	procedure string
+	rows      driver.Rows
+	result    driver.Result
+	out       []driver.Value
+}
+
+// postgres COPY FROM STDIN expectation
+type expectedCopyFrom struct {
+	commonExpectation
+
+	table   string
+	columns []string
+	rows    [][]driver.Value
+	copied  int
+	result  driver.Result
+}
+
+func (e *expectedCall) String() string {
+	if e.args == nil {
+		return fmt.Sprintf("call to procedure '%s'", e.procedure)
+	}
+	return fmt.Sprintf("call to procedure '%s' with args %+v", e.procedure, e.args)
+}
+
+func (e *expectedCopyFrom) String() string {
+	return fmt.Sprintf("copy to table '%s' with columns %v", e.table, e.columns)
 }
@@ -1,6 +1,7 @@
 package sqlmock
 
 import (
+	"database/sql"
 	"database/sql/driver"
 	"regexp"
 	"testing"
@@ -17,29 +18,29 @@ func (m matcher) Match(driver.Value) bool {
 func TestQueryExpectationArgComparison(t *testing.T) {
 	e := &queryBasedExpectation{}
 	against := []driver.Value{5}
-	if !e.argsMatches(against) {
+	if !e.argsMatches(against, comparison{}) {
 		t.Error("arguments should match, since the no expectation was set")
 	}
 
 	e.args = []driver.Value{5, "str"}
 
 	against = []driver.Value{5}
-	if e.argsMatches(against) {
+	if e.argsMatches(against, comparison{}) {
 		t.Error("arguments should not match, since the size is not the same")
 	}
 
 	against = []driver.Value{3, "str"}
-	if e.argsMatches(against) {
+	if e.argsMatches(against, comparison{}) {
 		t.Error("arguments should not match, since the first argument (int value) is different")
 	}
 
 	against = []driver.Value{5, "st"}
-	if e.argsMatches(against) {
+	if e.argsMatches(against, comparison{}) {
 		t.Error("arguments should not match, since the second argument (string value) is different")
 	}
 
 	against = []driver.Value{5, "str"}
-	if !e.argsMatches(against) {
+	if !e.argsMatches(against, comparison{}) {
 		t.Error("arguments should match, but it did not")
 	}
 
@@ -49,12 +50,20 @@ func TestQueryExpectationArgComparison(t *testing.T) {
 	tm, _ := time.Parse(longForm, "Feb 3, 2013 at 7:54pm (PST)")
 
 	against = []driver.Value{5, tm}
-	if !e.argsMatches(against) {
-		t.Error("arguments should match (time will be compared only by type), but it did not")
+	if e.argsMatches(against, comparison{}) {
+		t.Error("arguments should not match, since time is compared by value")
 	}
 
-	against = []driver.Value{5, matcher{}}
-	if !e.argsMatches(against) {
+	e.args = []driver.Value{5, tm.In(time.UTC)}
+	if !e.argsMatches(against, comparison{}) {
+		t.Error("arguments should match, since time is the same instant in a different location")
+	}
+	if e.argsMatches(against, comparison{exactTimeLocation: true}) {
+		t.Error("arguments should not match, since the exact time location is required")
+	}
+
+	e.args = []driver.Value{5, matcher{}}
+	if !e.argsMatches(against, comparison{}) {
 		t.Error("arguments should match, but it did not")
 	}
 }
@@ -71,3 +80,73 @@ func TestQueryExpectationSqlMatch(t *testing.T) {
 		t.Errorf("Sql must have matched the query")
 	}
 }
+
+func TestExpandedArgs(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectQuery("SELECT (.+) FROM users WHERE status = \\? AND id IN").
+		WithArgs("active", Expand([]int{1, 2, 3})).
+		WillReturnRow([]string{"id"}, 1)
+	ExpectExec("DELETE FROM users WHERE id IN").
+		WithArgs(Expand([2]int{1, 2})).
+		WillReturnRowsAffected(2)
+
+	var id int
+	if err = db.QueryRow("SELECT id FROM users WHERE status = ? AND id IN (?, ?, ?)", "active", 1, 2, 3).Scan(&id); err != nil {
+		t.Errorf("error '%s' was not expected while selecting users", err)
+	}
+
+	if _, err = db.Exec("DELETE FROM users WHERE id IN (?)", 1, 2); err == nil {
+		t.Errorf("expected an error, since the query has a single placeholder for two args")
+	}
+
+	Reset()
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestArgsAtPositions(t *testing.T) {
+	e := &queryBasedExpectation{argsAt: map[int]driver.Value{2: "paid", 0: 5}}
+	if !e.argsMatches([]driver.Value{int64(5), "gedi", "paid", 1.5}, comparison{}) {
+		t.Errorf("expected args to match at positions %+v", e.argsAt)
+	}
+	if e.argsMatches([]driver.Value{int64(5), "gedi", "pending"}, comparison{}) {
+		t.Errorf("expected args not to match, since the status differs")
+	}
+	if e.argsMatches([]driver.Value{int64(5)}, comparison{}) {
+		t.Errorf("expected args not to match, since there are fewer of them")
+	}
+
+	e.args = []driver.Value{5, "gedi", "paid"}
+	if e.argsMatches([]driver.Value{int64(5), "gedi", "paid", 1.5}, comparison{}) {
+		t.Errorf("expected args not to match, since all of them are expected as well")
+	}
+}
+
+func TestWithArgsShouldPanicOnPlaceholderMismatch(t *testing.T) {
+	db, m, err := NewMock(QueryMatchingOption(AnchorRegexes))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	func() {
+		expected := `query 'UPDATE users SET name = ? WHERE id = ?' has 2 placeholders, but 1 args [5] were expected`
+		defer func() {
+			if msg, _ := recover().(string); msg != expected {
+				t.Errorf("expected WithArgs to panic with \"%s\", but got: %s", expected, msg)
+			}
+		}()
+		m.ExpectExec(`UPDATE users SET name = \? WHERE id = \?`).WithArgs(5)
+	}()
+
+	// regexes matching many queries, or expanded slices, are not validated
+	m.ExpectExec(`UPDATE users SET (.+) WHERE id = \?`).WithArgs("gedi", 5)
+	m.ExpectExec(`DELETE FROM users WHERE id IN \(\?, \?\)`).WithArgs(Expand([]int{1, 2}))
+	m.ExpectExec(`UPDATE users SET name = \? WHERE id = \?`).WithArgs("gedi", 5)
+	m.Reset()
+	db.Close()
+}
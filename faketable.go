@@ -0,0 +1,489 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Table is an in-memory table, which answers simple statements
+// made against it, when no expectation matches them. It is created
+// by NewTable, seeded with AddRow and registered by RegisterTables
+type Table struct {
+	mu      sync.Mutex
+	name    string
+	columns []string
+	rows    [][]driver.Value
+}
+
+// NewTable creates an in-memory table with the given columns
+func NewTable(name string, columns ...string) *Table {
+	return &Table{name: strings.ToLower(name), columns: columns}
+}
+
+// AddRow seeds the table with a row, values are converted like
+// args of database/sql are, so an int is stored as int64.
+// Panics if there are not as many values as columns
+func (t *Table) AddRow(values ...driver.Value) *Table {
+	if len(values) != len(t.columns) {
+		panic(fmt.Sprintf("expected %d values for the row of table %s, but got %d", len(t.columns), t.name, len(values)))
+	}
+	row := make([]driver.Value, len(values))
+	for i, v := range values {
+		row[i] = fakeValue(v)
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rows = append(t.rows, row)
+	return t
+}
+
+// Rows lists the rows of the table, as statements left them
+func (t *Table) Rows() [][]driver.Value {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rows := make([][]driver.Value, len(t.rows))
+	for i, row := range t.rows {
+		rows[i] = append([]driver.Value(nil), row...)
+	}
+	return rows
+}
+
+// RegisterTables registers in-memory tables, which answer simple
+// statements made against them, when no expectation matches them.
+// So expectations override the tables and fixtures need not be wired
+// with expectations in every test. Understood are SELECT of columns
+// or * FROM a table, INSERT INTO a table VALUES, UPDATE of a table SET
+// columns and DELETE FROM a table, filtered by a WHERE of equalities
+// and IS NULL conditions joined with AND. Values may be placeholders
+// or literals. Other statements are matched against expectations only.
+// Tables are not transactional and are unregistered as the connection
+// is closed
+func RegisterTables(tables ...*Table) {
+	mock.RegisterTables(tables...)
+}
+
+// RegisterTables registers in-memory tables answering simple statements
+func (m *MockDB) RegisterTables(tables ...*Table) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	if m.conn.tables == nil {
+		m.conn.tables = make(map[string]*Table)
+	}
+	for _, t := range tables {
+		m.conn.tables[t.name] = t
+	}
+}
+
+// whether a pending expectation accepts the call, without binding it
+func (c *conn) accepted(query string, accepts func(expectation) bool) bool {
+	return len(c.accepting(query, accepts)) > 0
+}
+
+// whether calls no expectation accepts are answered by registered
+// tables or passed through, otherwise they are not looked up ahead
+func (c *conn) fallsBack() bool {
+	return len(c.tables) > 0 || c.passthrough != nil
+}
+
+// a statement parsed against a registered table
+type fakeStatement struct {
+	table *Table
+	ts    []token
+	args  []driver.Value
+	next  int // the sequential placeholder
+}
+
+// answers the statement with a registered table, if it is simple
+// enough and made against one. The returned result is driver.Rows for
+// queries and driver.Result otherwise, ok is false if no table answers it
+func (c *conn) fake(query string, args []driver.Value) (res interface{}, ok bool, err error) {
+	if len(c.tables) == 0 {
+		return nil, false, nil
+	}
+	stmts := statements(tokenize(query))
+	if len(stmts) != 1 {
+		return nil, false, nil
+	}
+	s := &fakeStatement{ts: stmts[0], args: args}
+
+	var name string
+	var i int
+	switch ts := s.ts; {
+	case ts[0].is("SELECT"):
+		from := find(ts, "FROM")
+		if from == -1 {
+			return nil, false, nil
+		}
+		name, i = qualifiedName(ts, from+1)
+	case ts[0].is("INSERT") && len(ts) > 1 && ts[1].is("INTO"),
+		ts[0].is("DELETE") && len(ts) > 1 && ts[1].is("FROM"):
+		name, i = qualifiedName(ts, 2)
+	case ts[0].is("UPDATE"):
+		name, i = qualifiedName(ts, 1)
+	default:
+		return nil, false, nil
+	}
+	if s.table = c.tables[name]; s.table == nil {
+		return nil, false, nil
+	}
+	s.table.mu.Lock()
+	defer s.table.mu.Unlock()
+
+	switch {
+	case s.ts[0].is("SELECT"):
+		res, ok, err = s.query(i)
+	case s.ts[0].is("INSERT"):
+		res, ok, err = s.insert(i)
+	case s.ts[0].is("UPDATE"):
+		res, ok, err = s.update(i)
+	default:
+		res, ok, err = s.delete(i)
+	}
+	if ok && err != nil {
+		err = fmt.Errorf("statement '%s' made against table %s failed: %s", query, name, err)
+	}
+	return res, ok, err
+}
+
+// a condition of WHERE
+type fakeCondition struct {
+	column int
+	value  driver.Value
+	null   bool // IS NULL, or IS NOT NULL if value is true
+}
+
+func (s *fakeStatement) query(i int) (interface{}, bool, error) {
+	t := s.table
+	if i < len(s.ts) && s.ts[i].is("AS") {
+		i++
+	}
+	if i < len(s.ts) && s.ts[i].kind == 'w' && !s.ts[i].is("WHERE") && !s.ts[i].is("LIMIT") {
+		i++ // an alias
+	}
+	var columns []int
+	for _, item := range split(s.ts[1:find(s.ts, "FROM")]) {
+		if len(item) == 1 && item[0].isPunct("*") {
+			for j := range t.columns {
+				columns = append(columns, j)
+			}
+			continue
+		}
+		name, next := qualifiedName(item, 0)
+		if next != len(item) {
+			return nil, false, nil
+		}
+		j, err := s.column(name)
+		if err != nil {
+			return nil, true, err
+		}
+		columns = append(columns, j)
+	}
+
+	var limitBy []token
+	if n := len(s.ts); n > 2 && s.ts[n-2].is("LIMIT") {
+		s.ts, limitBy = s.ts[:n-2], s.ts[n-2:]
+	}
+	where, ok, err := s.where(i)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+	limit := -1
+	if limitBy != nil {
+		v, _ := s.value(limitBy[1]) // placeholders of LIMIT follow the ones of WHERE
+		l, ok := v.(int64)
+		if !ok {
+			return nil, false, nil
+		}
+		limit = int(l)
+	}
+
+	names := make([]string, len(columns))
+	for j, col := range columns {
+		names[j] = t.columns[col]
+	}
+	rs := NewRows(names)
+	for _, row := range t.rows {
+		if limit == 0 {
+			break
+		}
+		if !satisfies(row, where) {
+			continue
+		}
+		values := make([]driver.Value, len(columns))
+		for j, col := range columns {
+			values[j] = row[col]
+		}
+		rs.AddRow(values...)
+		limit--
+	}
+	return rs, true, nil
+}
+
+func (s *fakeStatement) insert(i int) (interface{}, bool, error) {
+	t := s.table
+	var columns []int
+	if i < len(s.ts) && s.ts[i].isPunct("(") {
+		for _, item := range items(s.ts, i) {
+			name, next := qualifiedName(item, 0)
+			if next != len(item) {
+				return nil, false, nil
+			}
+			j, err := s.column(name)
+			if err != nil {
+				return nil, true, err
+			}
+			columns = append(columns, j)
+		}
+		i = closing(s.ts, i) + 1
+	} else {
+		for j := range t.columns {
+			columns = append(columns, j)
+		}
+	}
+	if i >= len(s.ts) || !s.ts[i].is("VALUES") {
+		return nil, false, nil
+	}
+
+	id := -1
+	for j, col := range t.columns {
+		if strings.EqualFold(col, "id") {
+			id = j
+		}
+	}
+	for _, col := range columns {
+		if col == id {
+			id = -1 // inserted
+		}
+	}
+
+	var inserted [][]driver.Value
+	var firstID int64
+	for i++; i < len(s.ts); i = closing(s.ts, i) + 2 {
+		if !s.ts[i].isPunct("(") {
+			return nil, false, nil
+		}
+		values := items(s.ts, i)
+		if len(values) != len(columns) {
+			return nil, true, fmt.Errorf("%d values are inserted into %d columns", len(values), len(columns))
+		}
+		row := make([]driver.Value, len(t.columns))
+		for j, item := range values {
+			if len(item) != 1 {
+				return nil, false, nil
+			}
+			v, ok := s.value(item[0])
+			if !ok {
+				return nil, false, nil
+			}
+			row[columns[j]] = v
+		}
+		if id != -1 {
+			next := maxID(t.rows, id) + 1
+			for _, r := range inserted {
+				if n, _ := r[id].(int64); n >= next {
+					next = n + 1
+				}
+			}
+			row[id] = next
+			if firstID == 0 {
+				firstID = next
+			}
+		}
+		inserted = append(inserted, row)
+		if end := closing(s.ts, i) + 1; end < len(s.ts) && !s.ts[end].isPunct(",") {
+			return nil, false, nil
+		}
+	}
+	t.rows = append(t.rows, inserted...)
+	return NewResult(firstID, int64(len(inserted))), true, nil
+}
+
+func (s *fakeStatement) update(i int) (interface{}, bool, error) {
+	if i >= len(s.ts) || !s.ts[i].is("SET") {
+		return nil, false, nil
+	}
+	end := i + 1
+	for end < len(s.ts) && !s.ts[end].is("WHERE") {
+		end++
+	}
+	type assignment struct {
+		column int
+		value  driver.Value
+	}
+	var set []assignment
+	for _, item := range split(s.ts[i+1 : end]) {
+		name, next := qualifiedName(item, 0)
+		if next+2 != len(item) || !item[next].isPunct("=") {
+			return nil, false, nil
+		}
+		j, err := s.column(name)
+		if err != nil {
+			return nil, true, err
+		}
+		v, ok := s.value(item[next+1])
+		if !ok {
+			return nil, false, nil
+		}
+		set = append(set, assignment{j, v})
+	}
+	where, ok, err := s.where(end)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+
+	var n int64
+	for _, row := range s.table.rows {
+		if satisfies(row, where) {
+			for _, a := range set {
+				row[a.column] = a.value
+			}
+			n++
+		}
+	}
+	return NewResult(0, n), true, nil
+}
+
+func (s *fakeStatement) delete(i int) (interface{}, bool, error) {
+	where, ok, err := s.where(i)
+	if !ok || err != nil {
+		return nil, ok, err
+	}
+	var kept [][]driver.Value
+	for _, row := range s.table.rows {
+		if !satisfies(row, where) {
+			kept = append(kept, row)
+		}
+	}
+	n := len(s.table.rows) - len(kept)
+	s.table.rows = kept
+	return NewResult(0, int64(n)), true, nil
+}
+
+// parses the WHERE starting at i, which must end the statement
+func (s *fakeStatement) where(i int) ([]fakeCondition, bool, error) {
+	if i == len(s.ts) {
+		return nil, true, nil
+	}
+	if !s.ts[i].is("WHERE") {
+		return nil, false, nil
+	}
+	var conds []fakeCondition
+	for i++; i < len(s.ts); i++ {
+		name, next := qualifiedName(s.ts, i)
+		if name == "" || next+1 >= len(s.ts) {
+			return nil, false, nil
+		}
+		col, err := s.column(name)
+		if err != nil {
+			return nil, true, err
+		}
+		switch i = next; {
+		case s.ts[i].isPunct("="):
+			v, ok := s.value(s.ts[i+1])
+			if !ok {
+				return nil, false, nil
+			}
+			conds = append(conds, fakeCondition{column: col, value: v})
+			i += 2
+		case s.ts[i].is("IS") && s.ts[i+1].is("NULL"):
+			conds = append(conds, fakeCondition{column: col, null: true})
+			i += 2
+		case s.ts[i].is("IS") && i+2 < len(s.ts) && s.ts[i+1].is("NOT") && s.ts[i+2].is("NULL"):
+			conds = append(conds, fakeCondition{column: col, null: true, value: true})
+			i += 3
+		default:
+			return nil, false, nil
+		}
+		if i < len(s.ts) && !s.ts[i].is("AND") {
+			return nil, false, nil
+		}
+	}
+	return conds, true, nil
+}
+
+// the index of the column of the table
+func (s *fakeStatement) column(name string) (int, error) {
+	for i, col := range s.table.columns {
+		if strings.EqualFold(col, name) {
+			return i, nil
+		}
+	}
+	return -1, fmt.Errorf("column %s does not exist", name)
+}
+
+// the value of a literal or placeholder
+func (s *fakeStatement) value(t token) (driver.Value, bool) {
+	switch t.kind {
+	case '?':
+		n := s.next
+		if t.text[0] == '$' {
+			n, _ = strconv.Atoi(t.text[1:])
+			n--
+		}
+		s.next++
+		if n < 0 || n >= len(s.args) {
+			return nil, false
+		}
+		return fakeValue(s.args[n]), true
+	case 's':
+		return strings.Replace(t.text[1:len(t.text)-1], "''", "'", -1), true
+	case 'n':
+		if n, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return n, true
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		return f, err == nil
+	case 'w':
+		switch {
+		case t.is("NULL"):
+			return nil, true
+		case t.is("TRUE"):
+			return true, true
+		case t.is("FALSE"):
+			return false, true
+		}
+	}
+	return nil, false
+}
+
+// whether the row satisfies every condition
+func satisfies(row []driver.Value, conds []fakeCondition) bool {
+	for _, c := range conds {
+		v := row[c.column]
+		switch {
+		case c.null:
+			if (v == nil) == (c.value == true) {
+				return false
+			}
+		case v == nil || c.value == nil:
+			return false // NULL equals nothing
+		case !argMatches(v, c.value, comparison{numericValues: true}):
+			return false
+		}
+	}
+	return true
+}
+
+// the greatest id of the rows
+func maxID(rows [][]driver.Value, col int) (id int64) {
+	for _, row := range rows {
+		if n, ok := row[col].(int64); ok && n > id {
+			id = n
+		}
+	}
+	return id
+}
+
+// a value as it is stored, converted like args are
+func fakeValue(v driver.Value) driver.Value {
+	if converted, err := driver.DefaultParameterConverter.ConvertValue(v); err == nil {
+		v = converted
+	}
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
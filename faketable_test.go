@@ -0,0 +1,88 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFakeTables(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	users := NewTable("users", "id", "name", "team").
+		AddRow(1, "gedi", "core").
+		AddRow(2, "pieter", "core").
+		AddRow(3, "brad", nil)
+	RegisterTables(users)
+	ExpectQuery("SELECT name FROM users WHERE id = \\?").WithArgs(1).WillReturnRow([]string{"name"}, "overridden")
+
+	var name string
+	if err = db.QueryRow("SELECT name FROM users WHERE id = ?", 1).Scan(&name); err != nil || name != "overridden" {
+		t.Errorf("expected the expectation to override the table, but got %s and error: %v", name, err)
+	}
+	if err = db.QueryRow("SELECT name FROM users WHERE id = ?", 2).Scan(&name); err != nil || name != "pieter" {
+		t.Errorf("expected the table to answer the query, but got %s and error: %v", name, err)
+	}
+
+	rows, err := db.Query(`SELECT u.id, u.name FROM "users" u WHERE u.team = $1 AND name = 'gedi' LIMIT $2`, "core", 5)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while selecting users", err)
+	}
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err = rows.Scan(&id, &name); err != nil {
+			t.Errorf("error '%s' was not expected while scanning", err)
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if !reflect.DeepEqual(ids, []int64{1}) {
+		t.Errorf("expected the user matching every condition, but got %v", ids)
+	}
+
+	res, err := db.Exec("INSERT INTO users (name, team) VALUES (?, ?), ('it''s', NULL)", "dave", "web")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while inserting", err)
+	}
+	if id, _ := res.LastInsertId(); id != 4 {
+		t.Errorf("expected the id of the first inserted row to be generated, but got %d", id)
+	}
+	if res, err = db.Exec("UPDATE users SET team = ? WHERE team IS NULL", "ops"); err != nil {
+		t.Fatalf("error '%s' was not expected while updating", err)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Errorf("expected 2 users without a team to be updated, but got %d", n)
+	}
+	if res, err = db.Exec("DELETE FROM users WHERE team = 'core'"); err != nil {
+		t.Fatalf("error '%s' was not expected while deleting", err)
+	}
+	if n, _ := res.RowsAffected(); n != 2 {
+		t.Errorf("expected 2 users of the core team to be deleted, but got %d", n)
+	}
+
+	expected := [][]driver.Value{
+		{int64(3), "brad", "ops"},
+		{int64(4), "dave", "web"},
+		{int64(5), "it's", "ops"},
+	}
+	if rows := users.Rows(); !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected the rows %v, but got %v", expected, rows)
+	}
+
+	if _, err = db.Exec("UPDATE users SET email = ?", "x"); err == nil || !strings.Contains(err.Error(), "column email does not exist") {
+		t.Errorf("expected an error, since the column does not exist, but got: %v", err)
+	}
+	if _, err = db.Query("SELECT name FROM users ORDER BY name"); err == nil {
+		t.Errorf("expected an error, since the statement is not simple enough to be answered by the table")
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Package gomegamock provides Gomega matchers of sqlmock mocks, so that
+Ginkgo suites may express database verifications idiomatically:
+
+	db, mock, err := sqlmock.NewMock()
+	...
+	Expect(mock).To(gomegamock.HaveExecuted("UPDATE users"))
+	Expect(mock).To(gomegamock.HaveMetExpectations())
+
+The matchers satisfy types.GomegaMatcher of github.com/onsi/gomega,
+the package does not import Gomega, so it adds no dependency.
+*/
+package gomegamock
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// the part of sqlmock.Sqlmock the matchers need
+type verifier interface {
+	ExpectationsWereMet() error
+}
+
+type reporter interface {
+	Report() []sqlmock.ExpectationReport
+}
+
+// HaveMetExpectations succeeds if ExpectationsWereMet
+// of the mock, like the one of sqlmock.NewMock, does
+func HaveMetExpectations() *MetExpectationsMatcher {
+	return &MetExpectationsMatcher{}
+}
+
+// MetExpectationsMatcher is the matcher of HaveMetExpectations
+type MetExpectationsMatcher struct {
+	err error // of the last match
+}
+
+// Match verifies the expectations of the mock
+func (m *MetExpectationsMatcher) Match(actual interface{}) (bool, error) {
+	v, ok := actual.(verifier)
+	if !ok {
+		return false, fmt.Errorf("HaveMetExpectations matcher expects a sqlmock.Sqlmock, but got %T", actual)
+	}
+	m.err = v.ExpectationsWereMet()
+	return m.err == nil, nil
+}
+
+// FailureMessage describes the expectation, which was not met
+func (m *MetExpectationsMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected the mock to have met all expectations, but: %s", m.err)
+}
+
+// NegatedFailureMessage is reported when all expectations were met
+func (m *MetExpectationsMatcher) NegatedFailureMessage(actual interface{}) string {
+	return "Expected the mock not to have met all expectations, but it did"
+}
+
+// HaveExecuted succeeds if an exec or a query call, with a query
+// matching the regular expression, was accepted by any of the
+// expectations of the mock, or failed with the error it was mocked
+// to return. Calls which were not expected, or which failed a check
+// of the expectation, like its arguments or transaction, are not
+// taken into account
+func HaveExecuted(sqlRegexStr string) *ExecutedMatcher {
+	return &ExecutedMatcher{regex: regexp.MustCompile(sqlRegexStr)}
+}
+
+// ExecutedMatcher is the matcher of HaveExecuted
+type ExecutedMatcher struct {
+	regex   *regexp.Regexp
+	queries []string // matched by the mock, of the last match
+}
+
+// Match looks for a query matching the regular expression
+// among the calls matched by the mock
+func (m *ExecutedMatcher) Match(actual interface{}) (bool, error) {
+	r, ok := actual.(reporter)
+	if !ok {
+		return false, fmt.Errorf("HaveExecuted matcher expects a sqlmock.Sqlmock, but got %T", actual)
+	}
+	m.queries = nil
+	for _, report := range r.Report() {
+		m.queries = append(m.queries, report.Queries...)
+	}
+	for _, q := range m.queries {
+		if m.regex.MatchString(q) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FailureMessage lists the queries the mock matched
+func (m *ExecutedMatcher) FailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected a query matching '%s' to have been executed, but the executed ones were:\n%s", m.regex, m.executed())
+}
+
+// NegatedFailureMessage lists the queries the mock matched
+func (m *ExecutedMatcher) NegatedFailureMessage(actual interface{}) string {
+	return fmt.Sprintf("Expected no query matching '%s' to have been executed, but the executed ones were:\n%s", m.regex, m.executed())
+}
+
+// the executed queries, one per indented line
+func (m *ExecutedMatcher) executed() string {
+	if len(m.queries) == 0 {
+		return "    none"
+	}
+	return "    " + strings.Join(m.queries, "\n    ")
+}
@@ -0,0 +1,102 @@
+package gomegamock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+func TestHaveMetExpectations(t *testing.T) {
+	db, mock, err := sqlmock.NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	matcher := HaveMetExpectations()
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	if ok, err := matcher.Match(mock); ok || err != nil {
+		t.Errorf("expected the matcher to fail, since the update was not made, but got %v and error: %v", ok, err)
+	}
+	if msg := matcher.FailureMessage(mock); !strings.Contains(msg, "exec 'UPDATE users'") {
+		t.Errorf("expected the failure message to describe the remaining expectation, but got: %s", msg)
+	}
+
+	if _, err = db.Exec("UPDATE users SET name = ?", "gedi"); err != nil {
+		t.Errorf("error '%s' was not expected while updating", err)
+	}
+	if ok, err := matcher.Match(mock); !ok || err != nil {
+		t.Errorf("expected the matcher to succeed, but got %v and error: %v", ok, err)
+	}
+
+	if _, err := matcher.Match(db); err == nil {
+		t.Errorf("expected an error, since the database is not a mock")
+	}
+}
+
+func TestHaveExecuted(t *testing.T) {
+	db, mock, err := sqlmock.NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery("SELECT (.+) FROM users").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	matcher := HaveExecuted(`UPDATE users SET name`)
+	if ok, err := matcher.Match(mock); ok || err != nil {
+		t.Errorf("expected the matcher to fail, since nothing was executed, but got %v and error: %v", ok, err)
+	}
+	if msg := matcher.FailureMessage(mock); !strings.HasSuffix(msg, "\n    none") {
+		t.Errorf("expected the failure message to tell no query was executed, but got: %s", msg)
+	}
+
+	if _, err = db.Exec("UPDATE users SET name = ?", "gedi"); err != nil {
+		t.Errorf("error '%s' was not expected while updating", err)
+	}
+	var id int
+	if err = db.QueryRow("SELECT id FROM users").Scan(&id); err != nil {
+		t.Errorf("error '%s' was not expected while selecting", err)
+	}
+
+	if ok, err := matcher.Match(mock); !ok || err != nil {
+		t.Errorf("expected the matcher to succeed, but got %v and error: %v", ok, err)
+	}
+	matcher = HaveExecuted(`DELETE FROM users`)
+	if ok, _ := matcher.Match(mock); ok {
+		t.Errorf("expected the matcher to fail, since nothing was deleted")
+	}
+	expected := "Expected a query matching 'DELETE FROM users' to have been executed, but the executed ones were:\n" +
+		"    UPDATE users SET name = ?\n    SELECT id FROM users"
+	if msg := matcher.FailureMessage(mock); msg != expected {
+		t.Errorf("expected the failure message to be:\n%s\nbut got:\n%s", expected, msg)
+	}
+}
+
+func TestHaveExecutedIgnoresRejectedCalls(t *testing.T) {
+	db, mock, err := sqlmock.NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE users").WithArgs("gedi").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM users").WillReturnError(fmt.Errorf("locked"))
+
+	if _, err = db.Exec("UPDATE users SET name = ?", "luke"); err == nil {
+		t.Errorf("expected an error, since the args do not match")
+	}
+	if ok, _ := HaveExecuted(`UPDATE users`).Match(mock); ok {
+		t.Errorf("expected the matcher to fail, since the update was rejected by the mock")
+	}
+
+	if _, err = db.Exec("DELETE FROM users"); err == nil || err.Error() != "locked" {
+		t.Errorf("expected the mocked error, but got: %v", err)
+	}
+	if ok, err := HaveExecuted(`DELETE FROM users`).Match(mock); !ok || err != nil {
+		t.Errorf("expected the matcher to succeed, since the delete failed as mocked, but got %v and error: %v", ok, err)
+	}
+}
@@ -0,0 +1,202 @@
+/*
+Package gormmock provides sqlmock expectation builders shaped
+like the statements GORM v2 issues, so that tests of GORM based
+code do not have to repeat its conventions:
+
+	gormmock.ExpectCreate("users", sqlmock.NewResult(1, 1), "gedi", 30)
+
+	gormmock.ExpectFindNotDeleted("users", gormmock.Rows([]User{{Name: "gedi"}}))
+
+Writes are wrapped in the transaction GORM opens by default,
+tables are matched whether quoted with backticks, double quotes
+or not at all. The package does not import GORM, models are
+inspected through reflection following GORM naming rules.
+
+The functions declare expectations on the package level mock,
+the methods of For on a mock created by sqlmock.NewMock. Both
+return the expected statement, so that it may be refined:
+
+	gormmock.For(mock).ExpectFind("users", rows).WithArgs(5)
+*/
+package gormmock
+
+import (
+	"database/sql/driver"
+	"regexp"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+// Table returns a regular expression which matches the
+// given table or column name, quoted the way any GORM
+// dialect quotes it
+func Table(name string) string {
+	return "[`\"]?" + regexp.QuoteMeta(name) + "[`\"]?"
+}
+
+// Expecter declares expectations shaped like GORM statements on a mock
+type Expecter struct {
+	mock expecter
+}
+
+// the part of a mock the expectations are declared on,
+// which both sqlmock.Sqlmock and the package level mock have
+type expecter interface {
+	ExpectBegin() sqlmock.Mock
+	ExpectCommit() sqlmock.Mock
+	ExpectExec(sqlRegexStr string) sqlmock.Mock
+	ExpectQuery(sqlRegexStr string) sqlmock.Mock
+}
+
+// the package level mock of sqlmock
+type packageMock struct{}
+
+func (packageMock) ExpectBegin() sqlmock.Mock  { return sqlmock.ExpectBegin() }
+func (packageMock) ExpectCommit() sqlmock.Mock { return sqlmock.ExpectCommit() }
+
+func (packageMock) ExpectExec(sqlRegexStr string) sqlmock.Mock {
+	return sqlmock.ExpectExec(sqlRegexStr)
+}
+
+func (packageMock) ExpectQuery(sqlRegexStr string) sqlmock.Mock {
+	return sqlmock.ExpectQuery(sqlRegexStr)
+}
+
+var pkg = &Expecter{packageMock{}}
+
+// For returns an Expecter, which declares expectations
+// on the given mock, like the one of sqlmock.NewMock
+func For(mock sqlmock.Sqlmock) *Expecter {
+	return &Expecter{mock}
+}
+
+// ExpectTransaction expects the expectations declared by fn
+// to be wrapped in a transaction, which is how GORM runs
+// every write unless SkipDefaultTransaction is configured.
+// The commit is returned, so that it may be made to fail
+func ExpectTransaction(fn func()) sqlmock.Mock {
+	return pkg.ExpectTransaction(fn)
+}
+
+// ExpectTransaction expects the expectations of fn within a transaction
+func (x *Expecter) ExpectTransaction(fn func()) sqlmock.Mock {
+	x.mock.ExpectBegin()
+	fn()
+	return x.mock.ExpectCommit()
+}
+
+// the statement expected by fn within a transaction
+func (x *Expecter) transaction(fn func() sqlmock.Mock) (statement sqlmock.Mock) {
+	x.ExpectTransaction(func() {
+		statement = fn()
+	})
+	return
+}
+
+// ExpectCreate expects an INSERT into the given table within
+// the default transaction, the args are expected if given.
+// The statement is returned, so that it may be made to fail
+func ExpectCreate(table string, result driver.Result, args ...driver.Value) sqlmock.Mock {
+	return pkg.ExpectCreate(table, result, args...)
+}
+
+// ExpectCreate expects an INSERT into the given table within a transaction
+func (x *Expecter) ExpectCreate(table string, result driver.Result, args ...driver.Value) sqlmock.Mock {
+	return x.transaction(func() sqlmock.Mock {
+		return x.mock.ExpectExec("INSERT INTO " + Table(table)).
+			WithArgs(args...).
+			WillReturnResult(result)
+	})
+}
+
+// ExpectCreateReturning expects an INSERT into the given table
+// with a RETURNING clause within the default transaction, which
+// is how GORM creates records on postgres and sqlite. The
+// returned rows usually hold the generated primary key
+func ExpectCreateReturning(table string, rows driver.Rows, args ...driver.Value) sqlmock.Mock {
+	return pkg.ExpectCreateReturning(table, rows, args...)
+}
+
+// ExpectCreateReturning expects an INSERT with a RETURNING clause within a transaction
+func (x *Expecter) ExpectCreateReturning(table string, rows driver.Rows, args ...driver.Value) sqlmock.Mock {
+	return x.transaction(func() sqlmock.Mock {
+		return x.mock.ExpectQuery("INSERT INTO " + Table(table) + ".+RETURNING").
+			WithArgs(args...).
+			WillReturnRows(rows)
+	})
+}
+
+// ExpectUpdate expects an UPDATE of the given table within
+// the default transaction, the args are expected if given
+func ExpectUpdate(table string, result driver.Result, args ...driver.Value) sqlmock.Mock {
+	return pkg.ExpectUpdate(table, result, args...)
+}
+
+// ExpectUpdate expects an UPDATE of the given table within a transaction
+func (x *Expecter) ExpectUpdate(table string, result driver.Result, args ...driver.Value) sqlmock.Mock {
+	return x.transaction(func() sqlmock.Mock {
+		return x.mock.ExpectExec("UPDATE " + Table(table) + " SET").
+			WithArgs(args...).
+			WillReturnResult(result)
+	})
+}
+
+// ExpectDelete expects a DELETE from the given table within
+// the default transaction, which is how GORM deletes records
+// of models without gorm.DeletedAt or with Unscoped
+func ExpectDelete(table string, result driver.Result, args ...driver.Value) sqlmock.Mock {
+	return pkg.ExpectDelete(table, result, args...)
+}
+
+// ExpectDelete expects a DELETE from the given table within a transaction
+func (x *Expecter) ExpectDelete(table string, result driver.Result, args ...driver.Value) sqlmock.Mock {
+	return x.transaction(func() sqlmock.Mock {
+		return x.mock.ExpectExec("DELETE FROM " + Table(table)).
+			WithArgs(args...).
+			WillReturnResult(result)
+	})
+}
+
+// ExpectSoftDelete expects the UPDATE which sets deleted_at
+// of not yet deleted records within the default transaction,
+// which is how GORM deletes records of models with gorm.DeletedAt
+func ExpectSoftDelete(table string, result driver.Result, args ...driver.Value) sqlmock.Mock {
+	return pkg.ExpectSoftDelete(table, result, args...)
+}
+
+// ExpectSoftDelete expects the UPDATE of deleted_at within a transaction
+func (x *Expecter) ExpectSoftDelete(table string, result driver.Result, args ...driver.Value) sqlmock.Mock {
+	return x.transaction(func() sqlmock.Mock {
+		return x.mock.ExpectExec("UPDATE " + Table(table) + " SET " + Table("deleted_at") + "=.+" + notDeleted).
+			WithArgs(args...).
+			WillReturnResult(result)
+	})
+}
+
+// ExpectFind expects a SELECT from the given table, GORM does
+// not wrap reads in a transaction. Arguments may be expected
+// on the returned Mock
+func ExpectFind(table string, rows driver.Rows) sqlmock.Mock {
+	return pkg.ExpectFind(table, rows)
+}
+
+// ExpectFind expects a SELECT from the given table
+func (x *Expecter) ExpectFind(table string, rows driver.Rows) sqlmock.Mock {
+	return x.mock.ExpectQuery("SELECT .+ FROM " + Table(table)).
+		WillReturnRows(rows)
+}
+
+// ExpectFindNotDeleted expects a SELECT from the given table,
+// which excludes soft deleted records the way GORM scopes
+// queries of models with gorm.DeletedAt
+func ExpectFindNotDeleted(table string, rows driver.Rows) sqlmock.Mock {
+	return pkg.ExpectFindNotDeleted(table, rows)
+}
+
+// ExpectFindNotDeleted expects a SELECT of not soft deleted records
+func (x *Expecter) ExpectFindNotDeleted(table string, rows driver.Rows) sqlmock.Mock {
+	return x.mock.ExpectQuery("SELECT .+ FROM " + Table(table) + ".+" + notDeleted).
+		WillReturnRows(rows)
+}
+
+var notDeleted = "([`\"]?\\w+[`\"]?\\.)?" + Table("deleted_at") + " IS NULL"
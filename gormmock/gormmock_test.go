@@ -0,0 +1,203 @@
+package gormmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+type deletedAt struct {
+	Time  time.Time
+	Valid bool
+}
+
+func (d deletedAt) Value() (driver.Value, error) {
+	if !d.Valid {
+		return nil, nil
+	}
+	return d.Time, nil
+}
+
+type model struct {
+	ID        uint
+	CreatedAt time.Time
+	DeletedAt deletedAt
+}
+
+type order struct {
+	ID     uint
+	UserID uint
+}
+
+type user struct {
+	model
+	Name    string `gorm:"column:full_name"`
+	Age     *int
+	APIKey  []byte
+	Address struct {
+		City string
+	} `gorm:"embedded;embeddedPrefix:address_"`
+	Orders   []order
+	Manager  *user
+	Password string `gorm:"-"`
+	internal string
+}
+
+func TestModelColumns(t *testing.T) {
+	expected := "id,created_at,deleted_at,full_name,age,api_key,address_city"
+	if cols := strings.Join(Columns([]user{}), ","); cols != expected {
+		t.Errorf("expected columns to be '%s', but got '%s'", expected, cols)
+	}
+
+	for name, expected := range map[string]string{"UserID": "user_id", "HTTPServer": "http_server", "Age2": "age2", "ID": "id"} {
+		if s := snakeCase(name); s != expected {
+			t.Errorf("expected '%s' to be snake cased as '%s', but got '%s'", name, expected, s)
+		}
+	}
+}
+
+func TestModelRowsAndFind(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	age := 30
+	u := user{Name: "gedi", Age: &age}
+	u.ID = 5
+	u.Address.City = "Vilnius"
+
+	ExpectFindNotDeleted("users", Rows([]*user{&u, {Name: "pieter"}})).WithArgs(5)
+
+	rs, err := db.Query("SELECT * FROM `users` WHERE id = ? AND `users`.`deleted_at` IS NULL", 5)
+	if err != nil {
+		t.Errorf("error '%s' was not expected while selecting users", err)
+	}
+
+	var names, cities []string
+	var ages []sql.NullInt64
+	for rs.Next() {
+		var id uint
+		var created time.Time
+		var deleted *time.Time
+		var name, city string
+		var age sql.NullInt64
+		var key []byte
+		if err = rs.Scan(&id, &created, &deleted, &name, &age, &key, &city); err != nil {
+			t.Errorf("error '%s' was not expected while scanning a user", err)
+		}
+		names = append(names, name)
+		cities = append(cities, city)
+		ages = append(ages, age)
+	}
+	rs.Close()
+
+	if strings.Join(names, ",") != "gedi,pieter" || cities[0] != "Vilnius" {
+		t.Errorf("expected users gedi from Vilnius and pieter, but got %v from %v", names, cities)
+	}
+	if ages[0].Int64 != 30 || ages[1].Valid {
+		t.Errorf("expected ages 30 and NULL, but got %v", ages)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestWritesInDefaultTransaction(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectCreate("users", sqlmock.NewResult(1, 1), "gedi")
+	ExpectCreateReturning("users", sqlmock.NewRows([]string{"id"}).AddRow(2))
+	ExpectUpdate("users", sqlmock.NewResult(0, 1))
+	ExpectSoftDelete("users", sqlmock.NewResult(0, 1))
+	ExpectDelete("orders", sqlmock.NewResult(0, 3))
+
+	statements := []string{
+		"INSERT INTO `users` (`full_name`) VALUES (?)",
+		`INSERT INTO "users" ("full_name") VALUES ($1) RETURNING "id"`,
+		"UPDATE `users` SET `full_name`=? WHERE `id` = ?",
+		"UPDATE `users` SET `deleted_at`=? WHERE `users`.`id` = ? AND `users`.`deleted_at` IS NULL",
+		"DELETE FROM `orders` WHERE `user_id` = ?",
+	}
+	for i, query := range statements {
+		tx, err := db.Begin()
+		if err != nil {
+			t.Errorf("an error '%s' was not expected when beginning a transaction", err)
+		}
+
+		if i == 1 {
+			var id int
+			err = tx.QueryRow(query, "gedi").Scan(&id)
+		} else if i == 0 {
+			_, err = tx.Exec(query, "gedi")
+		} else {
+			_, err = tx.Exec(query)
+		}
+		if err != nil {
+			t.Errorf("error '%s' was not expected while running '%s'", err, query)
+		}
+
+		if err = tx.Commit(); err != nil {
+			t.Errorf("an error '%s' was not expected when commiting a transaction", err)
+		}
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestExpecterOfIsolatedMock(t *testing.T) {
+	db, mock, err := sqlmock.NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	lost := fmt.Errorf("connection lost")
+	x := For(mock)
+	x.ExpectFind("users", sqlmock.NewRows([]string{"id"}).AddRow(5)).WithArgs(5)
+	x.ExpectUpdate("users", sqlmock.NewResult(0, 1)).WithArgs("gedi", 5)
+	x.ExpectTransaction(func() {
+		mock.ExpectExec("DELETE FROM " + Table("orders")).WillReturnResult(sqlmock.NewResult(0, 1))
+	}).WillReturnError(lost)
+
+	var id int
+	if err = db.QueryRow("SELECT * FROM `users` WHERE id = ?", 5).Scan(&id); err != nil {
+		t.Errorf("error '%s' was not expected while selecting a user", err)
+	}
+
+	writes := []struct {
+		query  string
+		args   []interface{}
+		commit error
+	}{
+		{"UPDATE `users` SET `full_name`=? WHERE `id` = ?", []interface{}{"gedi", 5}, nil},
+		{"DELETE FROM `orders`", nil, lost},
+	}
+	for _, w := range writes {
+		tx, err := db.Begin()
+		if err != nil {
+			t.Fatalf("an error '%s' was not expected when beginning a transaction", err)
+		}
+		if _, err = tx.Exec(w.query, w.args...); err != nil {
+			t.Errorf("error '%s' was not expected while running '%s'", err, w.query)
+		}
+		if err = tx.Commit(); err != w.commit {
+			t.Errorf("expected the commit after '%s' to return %v, but got %v", w.query, w.commit, err)
+		}
+	}
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
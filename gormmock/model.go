@@ -0,0 +1,191 @@
+package gormmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/DATA-DOG/go-sqlmock"
+)
+
+var (
+	valuerType = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	timeType   = reflect.TypeOf(time.Time{})
+)
+
+// Columns returns the column names of the given GORM model,
+// which may be a struct, a pointer to it or a slice of them.
+// Names are taken from the column tag or the snake cased field
+// name, embedded structs are flattened, associations and fields
+// tagged with "-" are skipped
+func Columns(model interface{}) []string {
+	var cols []string
+	for _, f := range fields(modelType(model), nil, "") {
+		cols = append(cols, f.column)
+	}
+	return cols
+}
+
+// Rows builds rows with the columns of the given GORM model,
+// one row for the struct given or for each struct of the given
+// slice. An empty slice gives rows with columns only
+func Rows(model interface{}) sqlmock.Rows {
+	typ := modelType(model)
+	fs := fields(typ, nil, "")
+
+	cols := make([]string, len(fs))
+	for i, f := range fs {
+		cols[i] = f.column
+	}
+	rs := sqlmock.NewRows(cols)
+
+	v := reflect.Indirect(reflect.ValueOf(model))
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return rs.AddRow(values(v, fs)...)
+	}
+	for i := 0; i < v.Len(); i++ {
+		rs.AddRow(values(reflect.Indirect(v.Index(i)), fs)...)
+	}
+	return rs
+}
+
+type field struct {
+	column string
+	index  []int
+}
+
+func modelType(model interface{}) reflect.Type {
+	typ := reflect.TypeOf(model)
+	for typ != nil && (typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("gorm model must be a struct, a pointer to it or a slice of them, but got %T", model))
+	}
+	return typ
+}
+
+func fields(typ reflect.Type, index []int, prefix string) (fs []field) {
+	for i := 0; i < typ.NumField(); i++ {
+		sf := typ.Field(i)
+		if sf.PkgPath != "" && !sf.Anonymous {
+			continue // unexported
+		}
+
+		settings := tagSettings(sf.Tag.Get("gorm"))
+		if _, ok := settings["-"]; ok {
+			continue
+		}
+
+		idx := make([]int, len(index)+1)
+		copy(idx, index)
+		idx[len(index)] = i
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		_, embedded := settings["EMBEDDED"]
+		if (sf.Anonymous || embedded) && ft.Kind() == reflect.Struct && !isValue(sf.Type) {
+			fs = append(fs, fields(ft, idx, prefix+settings["EMBEDDEDPREFIX"])...)
+			continue
+		}
+		if sf.PkgPath != "" || !isColumn(sf.Type) {
+			continue // unexported embedded value or association
+		}
+
+		col, ok := settings["COLUMN"]
+		if !ok {
+			col = snakeCase(sf.Name)
+		}
+		fs = append(fs, field{column: prefix + col, index: idx})
+	}
+	return
+}
+
+// values of the fields, nil pointers on the
+// way to an embedded field give a nil value
+func values(v reflect.Value, fs []field) []driver.Value {
+	vals := make([]driver.Value, len(fs))
+	for i, f := range fs {
+		fv := v
+		for _, x := range f.index {
+			if fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv = reflect.Value{}
+					break
+				}
+				fv = fv.Elem()
+			}
+			fv = fv.Field(x)
+		}
+		if fv.IsValid() {
+			vals[i] = fv.Interface()
+		}
+	}
+	return vals
+}
+
+func isValue(typ reflect.Type) bool {
+	return typ.Implements(valuerType) || reflect.PtrTo(typ).Implements(valuerType) || typ == timeType
+}
+
+func isColumn(typ reflect.Type) bool {
+	if isValue(typ) {
+		return true
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+		if isValue(typ) {
+			return true
+		}
+	}
+	switch typ.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Interface, reflect.Chan, reflect.Func:
+		return false
+	case reflect.Slice, reflect.Array:
+		return typ.Elem().Kind() == reflect.Uint8
+	}
+	return true
+}
+
+// parses gorm tag settings the way GORM does,
+// keys are upper cased and separated by semicolons
+func tagSettings(tag string) map[string]string {
+	settings := make(map[string]string)
+	for _, s := range strings.Split(tag, ";") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		kv := strings.SplitN(s, ":", 2)
+		key := strings.ToUpper(strings.TrimSpace(kv[0]))
+		if len(kv) == 2 {
+			settings[key] = strings.TrimSpace(kv[1])
+		} else {
+			settings[key] = key
+		}
+	}
+	return settings
+}
+
+// snake cases a field name keeping initialisms
+// together, like GORM: UserID becomes user_id
+func snakeCase(name string) string {
+	rs := []rune(name)
+	var out []rune
+	for i, r := range rs {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := rs[i-1]
+			nextLower := i+1 < len(rs) && unicode.IsLower(rs[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				out = append(out, '_')
+			}
+		}
+		out = append(out, unicode.ToLower(r))
+	}
+	return string(out)
+}
@@ -0,0 +1,136 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+)
+
+// RecordedCall is a driver call in the history of a mock. It holds
+// no timings or addresses, so that the history of the same calls is
+// always the same and may be compared with a golden file
+type RecordedCall struct {
+	Call         string          `json:"call"` // the operation, like sql.query or sql.commit
+	Query        string          `json:"query,omitempty"`
+	Args         []interface{}   `json:"args,omitempty"`
+	Columns      []string        `json:"columns,omitempty"` // of the returned rows
+	Rows         [][]interface{} `json:"rows,omitempty"`    // returned, if they were declared by NewRows
+	LastInsertID *int64          `json:"last_insert_id,omitempty"`
+	RowsAffected *int64          `json:"rows_affected,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	Stack        []string        `json:"stack,omitempty"` // of the call, if recorded with CallStacksOption
+}
+
+// History lists the driver calls made on the mock connection since
+// it was last closed or reset, whether they matched expectations or
+// not, with their arguments and results. Calls made in the Permissive
+// mode are not recorded
+func History() []RecordedCall {
+	return mock.History()
+}
+
+// History lists the driver calls made on the mock
+func (m *MockDB) History() []RecordedCall {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	return append([]RecordedCall(nil), m.conn.history...)
+}
+
+// DumpHistory writes the History as indented JSON, which is stable
+// for the same calls. So that it may be committed as a golden file
+// and diffed across refactors, to prove the queries did not change.
+// It must be called before the connection is closed
+func DumpHistory(w io.Writer) error {
+	return mock.DumpHistory(w)
+}
+
+// DumpHistory writes the history of the mock as indented JSON
+func (m *MockDB) DumpHistory(w io.Writer) error {
+	data, err := json.MarshalIndent(m.History(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// records the call in the history, the result is
+// a *driver.Result or *driver.Rows, if the call has one
+func (c *conn) record(name, query string, args []driver.Value, result interface{}, err error, stack []string) {
+	call := recordedCall(name, query, args, result, err)
+	call.Stack = stack
+	c.mu.Lock()
+	if c.permissive == nil { // would grow without bound under load
+		c.history = append(c.history, call)
+	}
+	c.mu.Unlock()
+}
+
+// the call as it is recorded in a history, the result is
+// a *driver.Result or *driver.Rows, if the call has one
+func recordedCall(name, query string, args []driver.Value, result interface{}, err error) RecordedCall {
+	call := RecordedCall{Call: name, Query: query}
+	for _, arg := range args {
+		call.Args = append(call.Args, historyValue(arg))
+	}
+	switch r := result.(type) {
+	case *driver.Result:
+		if *r != nil {
+			if id, err := (*r).LastInsertId(); err == nil {
+				call.LastInsertID = &id
+			}
+			if n, err := (*r).RowsAffected(); err == nil {
+				call.RowsAffected = &n
+			}
+		}
+	case *driver.Rows:
+		if *r != nil {
+			call.Columns = (*r).Columns()
+			rs := *r
+			if tr, ok := rs.(*trackedRows); ok {
+				rs = tr.Rows
+			}
+			if rs, ok := rs.(*rows); ok {
+				for _, row := range rs.rows {
+					values := make([]interface{}, len(row))
+					for i, v := range row {
+						values[i] = historyValue(v)
+					}
+					call.Rows = append(call.Rows, values)
+				}
+			}
+		}
+	}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	return call
+}
+
+// a value as it is written to JSON, values
+// which JSON does not represent are printed
+func historyValue(v driver.Value) interface{} {
+	switch v := v.(type) {
+	case nil, bool, int64, float64, string:
+		return v
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case func() driver.Value:
+		return "<generated>"
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint()
+	case reflect.Float32:
+		return rv.Float()
+	}
+	return fmt.Sprintf("%v", v)
+}
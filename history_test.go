@@ -0,0 +1,91 @@
+package sqlmock
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+)
+
+func TestDumpHistory(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectBegin()
+	ExpectExec("INSERT INTO users").WithArgs("gedi", []byte("x")).WillReturnResult(NewResult(7, 1))
+	ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"id", "name"}).AddRow(7, "gedi"))
+	ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while beginning a transaction", err)
+	}
+	if _, err = tx.Exec("INSERT INTO users (name, avatar) VALUES (?, ?)", "gedi", []byte("x")); err != nil {
+		t.Errorf("error '%s' was not expected while inserting", err)
+	}
+	rs, err := tx.Query("SELECT id, name FROM users")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while selecting", err)
+	}
+	rs.Close()
+	if _, err = tx.Exec("DELETE FROM users"); err == nil {
+		t.Errorf("expected an error, since the delete was not expected")
+	}
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while committing", err)
+	}
+
+	var buf bytes.Buffer
+	if err = DumpHistory(&buf); err != nil {
+		t.Errorf("error '%s' was not expected while dumping the history", err)
+	}
+	golden := `[
+  {
+    "call": "sql.begin"
+  },
+  {
+    "call": "sql.exec",
+    "query": "INSERT INTO users (name, avatar) VALUES (?, ?)",
+    "args": [
+      "gedi",
+      "x"
+    ],
+    "last_insert_id": 7,
+    "rows_affected": 1
+  },
+  {
+    "call": "sql.query",
+    "query": "SELECT id, name FROM users",
+    "columns": [
+      "id",
+      "name"
+    ],
+    "rows": [
+      [
+        7,
+        "gedi"
+      ]
+    ]
+  },
+  {
+    "call": "sql.exec",
+    "query": "DELETE FROM users",
+    "error": "call to exec query 'DELETE FROM users' with args [], was not expected, next expectation is commit transaction"
+  },
+  {
+    "call": "sql.commit"
+  }
+]
+`
+	if buf.String() != golden {
+		t.Errorf("expected the history:\n%s\nbut got:\n%s", golden, buf.String())
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+	if history := History(); len(history) != 0 {
+		t.Errorf("expected the history to be cleared on close, but got %+v", history)
+	}
+}
@@ -0,0 +1,117 @@
+package sqlmock
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+)
+
+// query based expectations indexed by a word, which every query
+// matching their regex must contain. So that in unordered mode, with
+// thousands of expectations declared, only the few ones which may
+// match are evaluated for a call instead of all of them
+type index struct {
+	words     map[string][]indexed
+	unindexed []indexed // expectations which do not require any word
+}
+
+// an expectation with its declaration position
+type indexed struct {
+	pos int
+	e   expectation
+}
+
+type byPosition []indexed
+
+func (p byPosition) Len() int           { return len(p) }
+func (p byPosition) Less(i, j int) bool { return p[i].pos < p[j].pos }
+func (p byPosition) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// indexes a query based expectation declared at the given position,
+// under the required word with the fewest expectations so far
+func (idx *index) add(pos int, e expectation) {
+	qe := queryBased(e)
+	if qe == nil {
+		return
+	}
+	if idx.words == nil {
+		idx.words = make(map[string][]indexed)
+	}
+	var word string
+	re := qe.sqlRegex
+	if qe.regex != nil {
+		re = qe.regex
+	}
+	for _, w := range requiredWords(re) {
+		if n, m := len(idx.words[w]), len(idx.words[word]); word == "" || n < m || (n == m && len(w) > len(word)) {
+			word = w
+		}
+	}
+	if word == "" {
+		idx.unindexed = append(idx.unindexed, indexed{pos, e})
+		return
+	}
+	idx.words[word] = append(idx.words[word], indexed{pos, e})
+}
+
+// the expectations which may match the query, in declaration order
+func (idx *index) lookup(query string) []expectation {
+	found := append([]indexed{}, idx.unindexed...)
+	seen := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(query, notWordChar) {
+		if !seen[w] {
+			seen[w] = true
+			found = append(found, idx.words[w]...)
+		}
+	}
+	sort.Sort(byPosition(found))
+
+	candidates := make([]expectation, len(found))
+	for i, f := range found {
+		candidates[i] = f.e
+	}
+	return candidates
+}
+
+// the words, which any string matched by the regex must contain. Only
+// the words of literals the regex is a concatenation of are taken, if
+// they are delimited by non word characters within the literal too, or
+// by the beginning or end of the text
+func requiredWords(re *regexp.Regexp) (words []string) {
+	tree, err := syntax.Parse(re.String(), syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	subs := []*syntax.Regexp{tree}
+	if tree.Op == syntax.OpConcat {
+		subs = tree.Sub
+	}
+	for i, sub := range subs {
+		if sub.Op != syntax.OpLiteral || sub.Flags&syntax.FoldCase != 0 {
+			continue
+		}
+		fields := strings.FieldsFunc(string(sub.Rune), notWordChar)
+		if len(fields) > 0 && !notWordChar(sub.Rune[0]) && (i == 0 || !isBegin(subs[i-1].Op)) {
+			fields = fields[1:] // may be a part of a longer word
+		}
+		if len(fields) > 0 && !notWordChar(sub.Rune[len(sub.Rune)-1]) && (i == len(subs)-1 || !isEnd(subs[i+1].Op)) {
+			fields = fields[:len(fields)-1]
+		}
+		words = append(words, fields...)
+	}
+	return words
+}
+
+func isBegin(op syntax.Op) bool {
+	return op == syntax.OpBeginText || op == syntax.OpBeginLine
+}
+
+func isEnd(op syntax.Op) bool {
+	return op == syntax.OpEndText || op == syntax.OpEndLine
+}
+
+// whether the rune is not matched by \w
+func notWordChar(r rune) bool {
+	return !(r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z')
+}
@@ -0,0 +1,86 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestRequiredWords(t *testing.T) {
+	cases := map[string][]string{
+		"SELECT (.+) FROM users WHERE id = \\?": {"FROM", "users", "WHERE", "id"},
+		"SELECT (.+) FROM users":                {"FROM"},
+		"^INSERT INTO orders$":                  {"INSERT", "INTO", "orders"},
+		"DELETE FROM (users|orders) WHERE":      {"FROM"},
+		"(?i)select 1 from users":               nil,
+		"UPDATE|DELETE":                         nil,
+		"users":                                 nil,
+	}
+	for expr, expected := range cases {
+		if words := requiredWords(regexp.MustCompile(expr)); !reflect.DeepEqual(words, expected) {
+			t.Errorf("expected regex '%s' to require words %q, but got %q", expr, expected, words)
+		}
+	}
+}
+
+func TestIndexLookup(t *testing.T) {
+	var idx index
+	exprs := []string{
+		"SELECT (.+) FROM users WHERE id",
+		"SELECT (.+) FROM orders WHERE id",
+		"DELETE FROM users WHERE",
+		"users",
+		"SELECT (.+) FROM users WHERE name",
+	}
+	for i, expr := range exprs {
+		idx.add(i, &expectedQuery{queryBasedExpectation: queryBasedExpectation{sqlRegex: regexp.MustCompile(expr)}})
+	}
+
+	var found []string
+	for _, e := range idx.lookup("SELECT id, name FROM users WHERE id = ?") {
+		found = append(found, e.(*expectedQuery).sqlRegex.String())
+	}
+	expected := []string{exprs[0], exprs[2], exprs[3], exprs[4]}
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("expected lookup to find %q in declaration order, but got %q", expected, found)
+	}
+
+	if n := len(idx.lookup("INSERT INTO users_archive VALUES (?)")); n != 1 {
+		t.Errorf("expected only the expectation which requires no word to be found, but got %d", n)
+	}
+}
+
+func TestUnorderedMatchingOfManyExpectations(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	MatchExpectationsInOrder(false)
+	defer MatchExpectationsInOrder(true)
+
+	n := 2000
+	for i := 0; i < n; i++ {
+		ExpectExec(fmt.Sprintf("UPDATE table_%d SET value = \\? WHERE id = \\?", i)).
+			WithArgs(i, 1).
+			WillReturnRowsAffected(1)
+	}
+	ExpectExec("UPDATE (.+) SET value").WithArgs("any", 1).WillReturnRowsAffected(2)
+
+	if _, err = db.Exec("UPDATE table_1 SET value = ? WHERE id = ?", "any", 1); err != nil {
+		t.Errorf("error '%s' was not expected while updating with an expectation which requires no word", err)
+	}
+	for i := n - 1; i >= 0; i-- {
+		if _, err = db.Exec(fmt.Sprintf("UPDATE table_%d SET value = ? WHERE id = ?", i), i, 1); err != nil {
+			t.Errorf("error '%s' was not expected while updating table %d", err, i)
+		}
+	}
+	if _, err = db.Exec("UPDATE table_1 SET value = ? WHERE id = ?", 1, 1); err == nil {
+		t.Errorf("expected an error, since all expectations were fulfilled")
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
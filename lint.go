@@ -0,0 +1,143 @@
+package sqlmock
+
+import (
+	"fmt"
+	"reflect"
+	"regexp/syntax"
+)
+
+// Lint warns about declared expectations, which are likely mistakes:
+// expectations which may be matched at the same time, like in unordered
+// mode or within an InAnyOrder group, where the regex of the one declared
+// first matches the query of the other, so it takes the calls meant for
+// the other one, and expectations required to be matched after the ones
+// which are matched later in ordered mode, which may never be matched.
+// With WarnBroadPatterns it warns about overly broad regexes as well
+func Lint() []string {
+	return mock.Lint()
+}
+
+// Lint warns about declared expectations of the mock, which are likely mistakes
+func (m *MockDB) Lint() []string {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	return m.conn.lint()
+}
+
+func (c *conn) lint() (warnings []string) {
+	if c.broadRegexes != AllowBroadPatterns {
+		for _, e := range c.expectations {
+			if err := checkBroadPattern(e); err != nil {
+				warnings = append(warnings, err.Error())
+			}
+		}
+	}
+
+	for i, a := range c.expectations {
+		for _, b := range c.expectations[i+1:] {
+			if !c.competing(a, b) || !shadows(a, b) {
+				continue
+			}
+			if shadows(b, a) {
+				warnings = append(warnings, fmt.Sprintf("expectations %s, declared at %s, and %s, declared at %s, are ambiguous, both match each other's queries",
+					a, a.common().declared, b, b.common().declared))
+				continue
+			}
+			warnings = append(warnings, fmt.Sprintf("expectation %s, declared at %s, shadows %s, declared at %s, it matches its queries as well",
+				a, a.common().declared, b, b.common().declared))
+		}
+	}
+
+	if c.unordered {
+		return
+	}
+	position := make(map[expectation]int)
+	for i, e := range c.expectations {
+		if _, ok := position[outermost(e)]; !ok {
+			position[outermost(e)] = i
+		}
+	}
+	for _, e := range c.expectations {
+		for _, other := range e.common().after {
+			if position[outermost(other)] > position[outermost(e)] {
+				warnings = append(warnings, fmt.Sprintf("expectation %s, declared at %s, may never be matched, it must be matched after %s, which is matched later in order",
+					e, e.common().declared, other))
+			}
+		}
+	}
+	return
+}
+
+// checks whether both expectations may be matched at the same time,
+// which they may in unordered mode or within an unordered group
+func (c *conn) competing(a, b expectation) bool {
+	if c.unordered {
+		return true
+	}
+	for g := a.common().group; g != nil; g = g.common().group {
+		if g.ordered {
+			continue
+		}
+		for e := b.common().group; e != nil; e = e.common().group {
+			if e == g {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checks whether a matches the calls meant for b, a query
+// which b expects and its args, if any are expected by both
+func shadows(a, b expectation) bool {
+	qa, qb := queryBased(a), queryBased(b)
+	if qa == nil || qb == nil || reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	sample, ok := sampleOf(qb.sqlRegex.String())
+	if !ok || !qa.sqlRegex.MatchString(sample) {
+		return false
+	}
+	if qa.args == nil || qb.args == nil {
+		return true
+	}
+	return qa.matches(sample, qb.args, comparison{})
+}
+
+// generates a short string, which the given regular expression matches
+func sampleOf(expr string) (string, bool) {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var sample []rune
+	var gen func(re *syntax.Regexp)
+	gen = func(re *syntax.Regexp) {
+		switch re.Op {
+		case syntax.OpLiteral:
+			sample = append(sample, re.Rune...)
+		case syntax.OpCharClass:
+			r := re.Rune[0]
+			if r < 'x' && re.Rune[1] >= 'x' {
+				r = 'x'
+			}
+			sample = append(sample, r)
+		case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+			sample = append(sample, 'x')
+		case syntax.OpCapture, syntax.OpPlus:
+			gen(re.Sub[0])
+		case syntax.OpRepeat:
+			for i := 0; i < re.Min; i++ {
+				gen(re.Sub[0])
+			}
+		case syntax.OpConcat:
+			for _, sub := range re.Sub {
+				gen(sub)
+			}
+		case syntax.OpAlternate:
+			gen(re.Sub[0])
+		}
+	}
+	gen(re)
+	return string(sample), true
+}
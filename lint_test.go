@@ -0,0 +1,65 @@
+package sqlmock
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestLintShadowedAndAmbiguousExpectations(t *testing.T) {
+	defer Reset()
+
+	ExpectExec("INSERT INTO").WillReturnResult(NewResult(1, 1))
+	ExpectExec("INSERT INTO audit").WillReturnResult(NewResult(1, 1))
+	ExpectQuery("SELECT (.+) FROM users").WithArgs(1)
+	ExpectQuery("SELECT (.+) FROM users").WithArgs(2)
+	ExpectQuery("SELECT \\* FROM orders WHERE id = \\?")
+	ExpectQuery("SELECT \\* FROM orders WHERE id = \\?")
+
+	if warnings := Lint(); len(warnings) != 0 {
+		t.Errorf("expected no warnings in ordered mode, but got %v", warnings)
+	}
+
+	MatchExpectationsInOrder(false)
+	defer MatchExpectationsInOrder(true)
+
+	warnings := Lint()
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings in unordered mode, but got %v", warnings)
+	}
+	if !strings.HasPrefix(warnings[0], "expectation exec 'INSERT INTO', declared at lint_test.go:") || !strings.Contains(warnings[0], "shadows exec 'INSERT INTO audit'") {
+		t.Errorf("expected audit insert to be reported as shadowed, but got '%s'", warnings[0])
+	}
+	if !strings.Contains(warnings[1], "are ambiguous") || !strings.Contains(warnings[1], "FROM orders") {
+		t.Errorf("expected order queries to be reported as ambiguous, but got '%s'", warnings[1])
+	}
+}
+
+func TestLintWithinGroupsAndOrderConstraints(t *testing.T) {
+	defer Reset()
+
+	InAnyOrder(ExpectExec("UPDATE (.+)"), ExpectExec("UPDATE orders"))
+	insert := ExpectExec("INSERT INTO audit")
+	insert.After(ExpectExec("DELETE FROM orders"))
+
+	warnings := Lint()
+	if len(warnings) != 2 {
+		t.Fatalf("expected 2 warnings, but got %v", warnings)
+	}
+	if !strings.Contains(warnings[0], "shadows exec 'UPDATE orders'") {
+		t.Errorf("expected order update to be reported as shadowed within the group, but got '%s'", warnings[0])
+	}
+	if !strings.Contains(warnings[1], "exec 'INSERT INTO audit'") || !strings.Contains(warnings[1], "may never be matched") {
+		t.Errorf("expected audit insert to be reported as never matched, but got '%s'", warnings[1])
+	}
+
+	for _, s := range []string{"SELECT (.+) FROM users WHERE id IN \\(\\d+\\)", "a{2}b|c", "[^ ]+x?"} {
+		sample, ok := sampleOf(s)
+		if !ok {
+			t.Errorf("expected a sample of '%s'", s)
+		}
+		if !regexp.MustCompile(s).MatchString(sample) {
+			t.Errorf("expected '%s' to match its sample '%s'", s, sample)
+		}
+	}
+}
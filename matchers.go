@@ -0,0 +1,56 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// matches JSON documents structurally
+type jsonArgument struct {
+	expected interface{}
+}
+
+// JSON creates an Argument, which matches a JSON document given as
+// a string or bytes, if it is structurally equal to the expected one,
+// regardless of the key order and formatting. The expected document
+// may be given as a string, bytes or any value to be marshalled
+func JSON(expected interface{}) Argument {
+	var doc []byte
+	switch v := expected.(type) {
+	case string:
+		doc = []byte(v)
+	case []byte:
+		doc = v
+	default:
+		var err error
+		if doc, err = json.Marshal(v); err != nil {
+			panic(fmt.Sprintf("expected value %+v could not be marshalled to JSON: %s", v, err))
+		}
+	}
+
+	var a jsonArgument
+	if err := json.Unmarshal(doc, &a.expected); err != nil {
+		panic(fmt.Sprintf("expected JSON document could not be parsed: %s", err))
+	}
+	return &a
+}
+
+func (a *jsonArgument) Match(v driver.Value) bool {
+	var doc []byte
+	switch v := v.(type) {
+	case string:
+		doc = []byte(v)
+	case []byte:
+		doc = v
+	default:
+		return false
+	}
+
+	var actual interface{}
+	if err := json.Unmarshal(doc, &actual); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(a.expected, actual)
+}
@@ -0,0 +1,45 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestJSONArgument(t *testing.T) {
+	a := JSON(`{"name": "gedi", "tags": ["a", "b"], "age": 30}`)
+
+	cases := map[interface{}]bool{
+		`{"age":30,"tags":["a","b"],"name":"gedi"}`:       true,
+		`{"age":30.0,"name":"gedi","tags":["a","b"]}`:     true,
+		`{"age":30,"tags":["b","a"],"name":"gedi"}`:       false,
+		`{"age":30,"tags":["a","b"],"name":"gedi","x":1}`: false,
+		`not a json`: false,
+		30:           false,
+	}
+	for v, expected := range cases {
+		if a.Match(v) != expected {
+			t.Errorf("expected match of %v to be %v", v, expected)
+		}
+	}
+
+	if !JSON(map[string]int{"id": 1}).Match([]byte(`{ "id" : 1 }`)) {
+		t.Errorf("expected a marshalled value to match a JSON document given as bytes")
+	}
+
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectExec("INSERT INTO documents").
+		WithArgs(1, JSON(`{"b": 2, "a": 1}`)).
+		WillReturnRowsAffected(1)
+
+	if _, err = db.Exec("INSERT INTO documents (id, body) VALUES (?, ?)", 1, `{"a":1,"b":2}`); err != nil {
+		t.Errorf("error '%s' was not expected while inserting a document", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
@@ -0,0 +1,101 @@
+package sqlmock
+
+import (
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// migration tools mark the up part of a file and statements,
+// which are executed as a whole despite semicolons
+var (
+	upMigration    = regexp.MustCompile(`(?im)^\s*--\s*\+(?:goose|migrate)\s+up\b`)
+	statementBegin = regexp.MustCompile(`(?i)^--\s*\+(?:goose|migrate)\s+StatementBegin\b`)
+	statementEnd   = regexp.MustCompile(`(?i)^--\s*\+(?:goose|migrate)\s+StatementEnd\b`)
+)
+
+// MigrationSet expects the DDL statements to be executed in the given
+// order, every one of them returning no rows affected. Statements are
+// matched exactly, apart from whitespace and a trailing semicolon, so
+// that a migration runner may be tested for the order it applies them.
+// An idempotent runner applied twice to the set executes no DDL the
+// second time, every statement it executes again is not expected
+func MigrationSet(ddl ...string) *ExpectationSet {
+	s := NewExpectationSet()
+	for _, stmt := range ddl {
+		s.ExpectExec(ddlRegex(stmt)).WillReturnResult(NewResult(0, 0))
+	}
+	return s
+}
+
+// LoadMigrationSet expects the migration files to be executed in the
+// given order, like the sorted result of filepath.Glob, the way runners
+// execute them. Files of down migrations, named *.down.sql, and the down
+// sections of goose or sql-migrate files are skipped. Goose and sql-migrate
+// files are executed statement by statement, other files, like the ones of
+// golang-migrate, as a whole
+func LoadMigrationSet(paths ...string) (*ExpectationSet, error) {
+	var ddl []string
+	for _, path := range paths {
+		if strings.HasSuffix(path, ".down.sql") {
+			continue
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		file := string(data)
+		if loc := downMigration.FindStringIndex(file); loc != nil {
+			file = file[:loc[0]]
+		}
+		switch {
+		case upMigration.MatchString(file):
+			ddl = append(ddl, migrationStatements(file)...)
+		case strings.TrimSpace(file) != "": // runners skip empty files
+			ddl = append(ddl, file)
+		}
+	}
+	return MigrationSet(ddl...), nil
+}
+
+// splits the up section of a goose or sql-migrate file into statements,
+// which end with a semicolon at the end of a line. Comments are dropped
+func migrationStatements(file string) (stmts []string) {
+	var lines []string
+	block := false
+	for _, line := range strings.Split(file, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case statementBegin.MatchString(trimmed):
+			block = true
+			continue
+		case statementEnd.MatchString(trimmed):
+			block = false
+		case strings.HasPrefix(trimmed, "--") || trimmed == "":
+			continue
+		default:
+			lines = append(lines, line)
+			if block || !strings.HasSuffix(trimmed, ";") {
+				continue
+			}
+		}
+		if len(lines) > 0 {
+			stmts = append(stmts, strings.Join(lines, "\n"))
+			lines = nil
+		}
+	}
+	if len(lines) > 0 {
+		stmts = append(stmts, strings.Join(lines, "\n"))
+	}
+	return stmts
+}
+
+// the regular expression matching the statement exactly,
+// apart from whitespace and a trailing semicolon
+func ddlRegex(stmt string) string {
+	words := strings.Fields(strings.TrimSuffix(strings.TrimSpace(stmt), ";"))
+	for i, w := range words {
+		words[i] = regexp.QuoteMeta(w)
+	}
+	return `^\s*` + strings.Join(words, `\s+`) + `\s*;?\s*$`
+}
@@ -0,0 +1,88 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadMigrationSet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrations")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while creating a directory", err)
+	}
+	defer os.RemoveAll(dir)
+
+	migrations := map[string]string{
+		"001_users.up.sql":   "CREATE TABLE users (id INT, name TEXT);\nCREATE INDEX users_name ON users (name);\n",
+		"001_users.down.sql": "DROP TABLE users;",
+		"002_orders.sql": "-- +goose Up\n-- orders of users\nCREATE TABLE orders (\n  id INT\n);\n" +
+			"-- +goose StatementBegin\nCREATE FUNCTION noop() RETURNS void AS $$ BEGIN; END; $$ LANGUAGE plpgsql;\n-- +goose StatementEnd\n" +
+			"-- +goose Down\nDROP TABLE orders;\n",
+	}
+	for name, ddl := range migrations {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(ddl), 0644); err != nil {
+			t.Fatalf("error '%s' was not expected while writing a migration", err)
+		}
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.sql"))
+	set, err := LoadMigrationSet(paths...)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while loading migrations", err)
+	}
+
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	Apply(set)
+
+	applied := []string{
+		"CREATE TABLE users (id INT, name TEXT);\nCREATE INDEX users_name ON users (name);\n",
+		"CREATE TABLE orders (\n  id INT\n);",
+		"CREATE FUNCTION noop() RETURNS void AS $$ BEGIN; END; $$ LANGUAGE plpgsql;",
+	}
+	for _, ddl := range applied {
+		if _, err = db.Exec(ddl); err != nil {
+			t.Errorf("error '%s' was not expected while migrating", err)
+		}
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestMigrationSetOrder(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	migrations := MigrationSet("CREATE TABLE users (id INT)", "ALTER TABLE users ADD COLUMN name TEXT")
+
+	Apply(migrations)
+	if _, err = db.Exec("CREATE  TABLE users\n(id INT);"); err != nil {
+		t.Errorf("error '%s' was not expected while creating the table", err)
+	}
+	if _, err = db.Exec("ALTER TABLE users ADD COLUMN name TEXT"); err != nil {
+		t.Errorf("error '%s' was not expected while altering the table", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+
+	db, err = sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	Apply(migrations)
+	if _, err = db.Exec("ALTER TABLE users ADD COLUMN name TEXT"); err == nil {
+		t.Errorf("expected an error, since the table was altered before it was created")
+	}
+	if err = db.Close(); err == nil || !strings.Contains(err.Error(), "ALTER") {
+		t.Errorf("expected the unmet migration to be reported on close, but got: %v", err)
+	}
+}
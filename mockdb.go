@@ -0,0 +1,440 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"math/rand"
+	"regexp"
+	"sync"
+	"time"
+)
+
+// MockDB is a mock isolated from any other one, with its own
+// expectations, settings, policies and history. The package level
+// functions operate on the mock of sql.Open("mock", ""), while every
+// MockDB is reached only through the database NewMock returned
+type MockDB struct {
+	conn *conn
+	dsn  string
+}
+
+// Sqlmock is the interface of a mock, implemented by MockDB, so
+// that a mock may be wrapped by helpers, decorated or substituted
+type Sqlmock interface {
+	SetPlaceholderStyle(PlaceholderStyle)
+	SetQueryMatching(QueryMatching)
+	SetSQLFormat(SQLFormat)
+	SetVerbosity(Verbosity)
+	Reset()
+
+	ExpectBegin() Mock
+	ExpectCommit() Mock
+	ExpectRollback() Mock
+	ExpectRetriedTransaction(failures int, err error, body func()) Mock
+	ExpectPrepare() Mock
+	ExpectTxPrepare() Mock
+	ExpectExec(sqlRegexStr string) Mock
+	ExpectDDL(statement string) Mock
+	ExpectBulkInsert(table string, columns []string, rows [][]interface{}) Mock
+	ExpectInsertReturning(table string) Mock
+	ExpectQuery(sqlRegexStr string) Mock
+	ExpectCall(procedure string) Mock
+	ExpectCopyFrom(table string, columns ...string) Mock
+	ExpectConn() Mock
+	ExpectPooledConn() Mock
+	ExpectPing() Mock
+	ExpectBadConnThenRecover() Mock
+	ExpectResetSession() Mock
+	Apply(sets ...*ExpectationSet)
+	RegisterTables(tables ...*Table)
+	Passthrough(db *sql.DB)
+
+	InvalidateConn()
+	FailAfter(n int, err error)
+	Chaos(seed int64, fraction float64, errs ...error)
+	Latency(base, jitter time.Duration)
+	RecycleConns(lifetime time.Duration, calls int)
+	AutoIncrement(start, step int64)
+	Permissive(result driver.Result, rows driver.Rows)
+	StopPermissive()
+
+	RequireReadOnly()
+	ForbidQueries(sqlRegexStrs ...string)
+	AllowOnly(sqlRegexStrs ...string)
+	RequireSchema(schema *Schema)
+	RequireValidSQL(dialect SQLDialect)
+	RequireExactTimeLocation()
+	RequireExactArgTypes()
+	MatchNumbersByValue()
+	RequireStatementsClosed()
+	AssertNoMoreInteractions()
+
+	MatchExpectationsInOrder(ordered bool)
+	InOrder(mocks ...Mock) Mock
+	InAnyOrder(mocks ...Mock) Mock
+	InLanes(lanes ...[]Mock) Mock
+
+	ExpectationsWereMet() error
+	Report() []ExpectationReport
+	Plan() ExpectationPlan
+	PendingExpectations() []string
+	AssertExpectations(t TestingT) bool
+	MustExpectationsMet(t FailingT)
+	Lint() []string
+	Stats() CallStats
+	History() []RecordedCall
+	DumpHistory(w io.Writer) error
+}
+
+// routes connections to mocks by their data source name
+type mockDriver struct {
+	mu    sync.Mutex
+	mocks map[string]*MockDB
+	seq   int
+}
+
+var driverInstance = &mockDriver{mocks: make(map[string]*MockDB)}
+
+// opens a connection to the mock registered with the data source
+// name, unknown names are connections to the package level mock
+func (d *mockDriver) Open(dsn string) (driver.Conn, error) {
+	return d.lookup(dsn).conn.open(nil)
+}
+
+// the mock registered with the data source name,
+// or the package level mock for unknown names
+func (d *mockDriver) lookup(dsn string) *MockDB {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if m, ok := d.mocks[dsn]; ok {
+		return m
+	}
+	return mock
+}
+
+// NewMock creates a database connected to a new mock, which shares
+// nothing with the package level mock or any other one, and pings it
+// so that its expectations could be asserted on Close. Several
+// databases, a primary and a replica for example, may be mocked
+// in the same test this way. When pings are monitored the database
+// is not pinged, ExpectationsWereMet asserts the expectations then
+func NewMock(opts ...Option) (*sql.DB, Sqlmock, error) {
+	driverInstance.mu.Lock()
+	driverInstance.seq++
+	m := &MockDB{
+		conn: &conn{placeholders: AnyPlaceholders},
+		dsn:  fmt.Sprintf("sqlmock_%d", driverInstance.seq),
+	}
+	driverInstance.mocks[m.dsn] = m
+	driverInstance.mu.Unlock()
+	m.configure(opts)
+
+	db, err := sql.Open("mock", m.dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !m.conn.monitoringPings() {
+		// ensure open connection, otherwise Close does not assert expectations
+		db.Ping()
+	}
+	return db, m, nil
+}
+
+// SetPlaceholderStyle sets which placeholders are counted in prepared statements
+func (m *MockDB) SetPlaceholderStyle(style PlaceholderStyle) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.placeholders = style
+}
+
+// SetQueryMatching sets how queries and expected regexes are normalized
+func (m *MockDB) SetQueryMatching(matching QueryMatching) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.matching = matching
+	m.conn.adapt()
+}
+
+// SetSQLFormat sets how queries are printed in failure messages
+func (m *MockDB) SetSQLFormat(format SQLFormat) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.format = format
+}
+
+// Reset clears expectations, policies and violations of the mock
+func (m *MockDB) Reset() {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.reset()
+}
+
+// ExpectBegin expects a transaction to be started
+func (m *MockDB) ExpectBegin() Mock {
+	e := &expectedBegin{}
+	return m.conn.expect(e)
+}
+
+// ExpectCommit expects a transaction to be committed
+func (m *MockDB) ExpectCommit() Mock {
+	e := &expectedCommit{}
+	return m.conn.expect(e)
+}
+
+// ExpectRollback expects a transaction to be rolled back
+func (m *MockDB) ExpectRollback() Mock {
+	e := &expectedRollback{}
+	return m.conn.expect(e)
+}
+
+// ExpectRetriedTransaction expects a transaction to fail with err and to be retried
+func (m *MockDB) ExpectRetriedTransaction(failures int, err error, body func()) Mock {
+	for i := 0; i < failures; i++ {
+		m.ExpectBegin()
+		n := m.conn.declared()
+		if body(); m.conn.declared() == n {
+			m.ExpectCommit().WillReturnError(err)
+			continue
+		}
+		m.conn.last().setError(err)
+		m.ExpectRollback()
+	}
+	m.ExpectBegin()
+	body()
+	return m.ExpectCommit()
+}
+
+// ExpectPrepare expects a query to be prepared
+func (m *MockDB) ExpectPrepare() Mock {
+	e := &expectedPrepare{}
+	return m.conn.expect(e)
+}
+
+// ExpectTxPrepare expects a statement to be prepared within a transaction
+func (m *MockDB) ExpectTxPrepare() Mock {
+	e := &expectedPrepare{}
+	e.inTx = true
+	return m.conn.expect(e)
+}
+
+// ExpectBadConnThenRecover expects the next driver call to fail with driver.ErrBadConn
+func (m *MockDB) ExpectBadConnThenRecover() Mock {
+	e := &expectedBadConn{}
+	return m.conn.expect(e)
+}
+
+// ExpectResetSession expects the session of a pooled connection to be reset
+func (m *MockDB) ExpectResetSession() Mock {
+	e := &expectedResetSession{}
+	return m.conn.expect(e)
+}
+
+// ExpectPing expects the connection to be pinged
+func (m *MockDB) ExpectPing() Mock {
+	e := &expectedPing{}
+	return m.conn.expect(e)
+}
+
+// InvalidateConn marks the connection of the mock as no longer valid
+func (m *MockDB) InvalidateConn() {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.invalid = true
+}
+
+// FailAfter makes driver calls past the first n ones fail with err
+func (m *MockDB) FailAfter(n int, err error) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.faults = &faults{after: n, err: err}
+}
+
+// Chaos makes a fraction of matched driver calls fail at random
+func (m *MockDB) Chaos(seed int64, fraction float64, errs ...error) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	if fraction <= 0 {
+		m.conn.chaos = nil
+		return
+	}
+	if len(errs) == 0 {
+		errs = []error{ErrChaos}
+	}
+	m.conn.chaos = &chaos{rand.New(rand.NewSource(seed)), fraction, errs}
+}
+
+// Latency delays every driver call of the mock
+func (m *MockDB) Latency(base, jitter time.Duration) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.latency, m.conn.jitter = base, jitter
+}
+
+// RecycleConns expires connections of the mock after the lifetime or number of calls
+func (m *MockDB) RecycleConns(lifetime time.Duration, calls int) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.connLifetime, m.conn.connCalls = lifetime, calls
+}
+
+// Permissive makes every call of the mock succeed with canned results
+func (m *MockDB) Permissive(result driver.Result, rows driver.Rows) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.permissive = &permissive{result, rows}
+}
+
+// StopPermissive turns the permissive mode of the mock off
+func (m *MockDB) StopPermissive() {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.permissive = nil
+}
+
+// RequireReadOnly makes every statement which writes fail
+func (m *MockDB) RequireReadOnly() {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.readOnly = true
+}
+
+// ForbidQueries makes every statement matching any of the regexes fail
+func (m *MockDB) ForbidQueries(sqlRegexStrs ...string) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	for _, s := range sqlRegexStrs {
+		m.conn.forbidden = append(m.conn.forbidden, regexp.MustCompile(s))
+	}
+}
+
+// AllowOnly makes every statement matching none of the regexes fail
+func (m *MockDB) AllowOnly(sqlRegexStrs ...string) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	if m.conn.allowed == nil {
+		m.conn.allowed = []*regexp.Regexp{}
+	}
+	for _, s := range sqlRegexStrs {
+		m.conn.allowed = append(m.conn.allowed, regexp.MustCompile(s))
+	}
+}
+
+// RequireSchema makes every statement referencing what is not in the schema fail
+func (m *MockDB) RequireSchema(schema *Schema) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.schema = schema
+}
+
+// RequireValidSQL makes every statement, which is not valid SQL of the dialect, fail
+func (m *MockDB) RequireValidSQL(dialect SQLDialect) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.syntax = dialect
+}
+
+// RequireExactArgTypes requires arguments to have the type of the expected values
+func (m *MockDB) RequireExactArgTypes() {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.compare.exactTypes = true
+}
+
+// MatchNumbersByValue makes numbers match equal ones of any numeric type
+func (m *MockDB) MatchNumbersByValue() {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.compare.numericValues = true
+}
+
+// RequireExactTimeLocation requires time arguments to have the expected location
+func (m *MockDB) RequireExactTimeLocation() {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.compare.exactTimeLocation = true
+}
+
+// RequireStatementsClosed requires every prepared statement to be closed
+func (m *MockDB) RequireStatementsClosed() {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.stmtsMustBeClosed = true
+}
+
+// AssertNoMoreInteractions fails every driver call made after it, until the connection is closed
+func (m *MockDB) AssertNoMoreInteractions() {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.noMoreInteractions = true
+}
+
+// MatchExpectationsInOrder sets whether expectations are matched in declaration order
+func (m *MockDB) MatchExpectationsInOrder(ordered bool) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.unordered = !ordered
+}
+
+// InOrder groups the given expectations, which must be matched in the given order
+func (m *MockDB) InOrder(mocks ...Mock) Mock {
+	return m.conn.group(true, mocks)
+}
+
+// InAnyOrder groups the given expectations, which may be matched in any order
+func (m *MockDB) InAnyOrder(mocks ...Mock) Mock {
+	return m.conn.group(false, mocks)
+}
+
+// InLanes groups every lane of expectations in order, while lanes interleave
+func (m *MockDB) InLanes(lanes ...[]Mock) Mock {
+	return m.conn.lanes(lanes)
+}
+
+// ExpectExec expects an Exec of a query matching the regex
+func (m *MockDB) ExpectExec(sqlRegexStr string) Mock {
+	e := &expectedExec{}
+	e.sqlRegex = regexp.MustCompile(sqlRegexStr)
+	return m.conn.expect(e)
+}
+
+// ExpectQuery expects a Query of a query matching the regex
+func (m *MockDB) ExpectQuery(sqlRegexStr string) Mock {
+	e := &expectedQuery{}
+	e.sqlRegex = regexp.MustCompile(sqlRegexStr)
+
+	return m.conn.expect(e)
+}
+
+// ExpectCall expects a stored procedure to be called
+func (m *MockDB) ExpectCall(procedure string) Mock {
+	e := &expectedCall{procedure: procedure}
+	e.sqlRegex = procedureRegex(procedure)
+	e.unanchored = true // matches the beginning of a call only
+	return m.conn.expect(e)
+}
+
+// ExpectCopyFrom expects a postgres COPY FROM STDIN to the table
+func (m *MockDB) ExpectCopyFrom(table string, columns ...string) Mock {
+	e := &expectedCopyFrom{table: table, columns: columns}
+	return m.conn.expect(e)
+}
+
+// ExpectConn expects a dedicated connection, like the one of db.Conn
+func (m *MockDB) ExpectConn() Mock {
+	return m.conn.expectConn(false)
+}
+
+// ExpectPooledConn expects a connection of the pool, which
+// expectations scoped to it must be matched on
+func (m *MockDB) ExpectPooledConn() Mock {
+	return m.conn.expectConn(true)
+}
+
+// ExpectationsWereMet checks whether all expectations of the mock were met
+func (m *MockDB) ExpectationsWereMet() error {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	return m.conn.verify()
+}
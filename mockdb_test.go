@@ -0,0 +1,115 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestIsolatedMocks(t *testing.T) {
+	primary, primaryMock, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	replica, replicaMock, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	primaryMock.ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, 1))
+	replicaMock.ExpectQuery("SELECT title FROM articles").
+		WillReturnRows(NewRows([]string{"title"}).AddRow("hello"))
+
+	var title string
+	if err = replica.QueryRow("SELECT title FROM articles WHERE id = ?", 1).Scan(&title); err != nil {
+		t.Errorf("error '%s' was not expected while querying the replica", err)
+	}
+	if title != "hello" {
+		t.Errorf("expected the title of the replica, but got '%s'", title)
+	}
+	if err = replicaMock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations of the replica were expected to be met, but got: %s", err)
+	}
+	if err = primaryMock.ExpectationsWereMet(); err == nil {
+		t.Errorf("expected the exec of the primary to be pending")
+	}
+	if len(primaryMock.Report()) != 1 || len(replicaMock.Report()) != 1 {
+		t.Errorf("expected every mock to report only its own expectations")
+	}
+
+	if _, err = replica.Exec("UPDATE articles SET title = ?", "hi"); err == nil {
+		t.Errorf("expected the replica to fail an exec expected on the primary")
+	}
+	if _, err = primary.Exec("UPDATE articles SET title = ?", "hi"); err != nil {
+		t.Errorf("error '%s' was not expected while executing on the primary", err)
+	}
+
+	if err = primary.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the primary", err)
+	}
+	replica.Close()
+}
+
+func TestIsolatedMockDoesNotAffectPackageMock(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	m.ExpectBegin()
+	m.ExpectCommit()
+
+	if err = ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations of the isolated mock were not expected to leak, but got: %s", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("error '%s' was not expected while beginning a transaction", err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while committing a transaction", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+
+	other, err := sql.Open("mock", "")
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	if _, err = other.Begin(); err == nil {
+		t.Errorf("expected the package mock to fail a begin, which was not expected on it")
+	}
+	other.Close()
+}
+
+// a team specific helper, which decorates the mock
+type auditedMock struct {
+	Sqlmock
+}
+
+func (m auditedMock) ExpectExec(sqlRegexStr string) Mock {
+	e := m.Sqlmock.ExpectExec(sqlRegexStr)
+	m.Sqlmock.ExpectExec("INSERT INTO audit").WillReturnResult(NewResult(1, 1))
+	return e
+}
+
+func TestDecoratedMock(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	var audited Sqlmock = auditedMock{m}
+
+	audited.ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, 1))
+
+	if _, err = db.Exec("UPDATE articles SET title = ?", "hello"); err != nil {
+		t.Errorf("error '%s' was not expected while updating articles", err)
+	}
+	if _, err = db.Exec("INSERT INTO audit (action) VALUES (?)", "update"); err != nil {
+		t.Errorf("error '%s' was not expected while inserting an audit record", err)
+	}
+	if err = audited.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
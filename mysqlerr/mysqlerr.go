@@ -0,0 +1,128 @@
+/*
+Package mysqlerr provides constructors of common MySQL errors,
+shaped like mysql.MySQLError of github.com/go-sql-driver/mysql,
+to be returned by sqlmock expectations:
+
+	sqlmock.ExpectExec("INSERT INTO users").
+	    WillReturnError(mysqlerr.DuplicateKey("users.email", "gedi@example.com"))
+
+Error classification code which inspects the error number, state or
+message can be tested against the mock without a real database.
+*/
+package mysqlerr
+
+import (
+	"fmt"
+)
+
+// MySQLError has the same fields as mysql.MySQLError
+type MySQLError struct {
+	Number   uint16
+	SQLState [5]byte
+	Message  string
+}
+
+func (e *MySQLError) Error() string {
+	if e.SQLState != [5]byte{} {
+		return fmt.Sprintf("Error %d (%s): %s", e.Number, e.SQLState[:], e.Message)
+	}
+	return fmt.Sprintf("Error %d: %s", e.Number, e.Message)
+}
+
+// error numbers of the errors in this catalog
+const (
+	ErDupEntry            uint16 = 1062
+	ErNoReferencedRow2    uint16 = 1452
+	ErRowIsReferenced2    uint16 = 1451
+	ErLockWaitTimeout     uint16 = 1205
+	ErLockDeadlock        uint16 = 1213
+	ErQueryInterrupted    uint16 = 1317
+	ErConCountError       uint16 = 1040
+	ErDataTooLong         uint16 = 1406
+	ErBadNullError        uint16 = 1048
+	ErNoSuchTable         uint16 = 1146
+	ErBadFieldError       uint16 = 1054
+	ErParseError          uint16 = 1064
+	ErServerShutdown      uint16 = 1053
+	ErOptionPreventsStmt  uint16 = 1290
+	ErCheckConstraintViol uint16 = 3819
+)
+
+// New creates an error with the given number, state and message
+func New(number uint16, state string, message string) *MySQLError {
+	e := &MySQLError{Number: number, Message: message}
+	copy(e.SQLState[:], state)
+	return e
+}
+
+// DuplicateKey is returned when a unique key is violated
+func DuplicateKey(key string, value interface{}) *MySQLError {
+	return New(ErDupEntry, "23000", fmt.Sprintf("Duplicate entry '%v' for key '%s'", value, key))
+}
+
+// ForeignKeyViolation is returned when a referenced row does not exist
+func ForeignKeyViolation(table, constraint string) *MySQLError {
+	return New(ErNoReferencedRow2, "23000", fmt.Sprintf("Cannot add or update a child row: a foreign key constraint fails (`%s`, CONSTRAINT `%s`)", table, constraint))
+}
+
+// RowIsReferenced is returned when a row still referenced by a foreign key is deleted
+func RowIsReferenced(table, constraint string) *MySQLError {
+	return New(ErRowIsReferenced2, "23000", fmt.Sprintf("Cannot delete or update a parent row: a foreign key constraint fails (`%s`, CONSTRAINT `%s`)", table, constraint))
+}
+
+// NotNullViolation is returned when NULL is stored in a NOT NULL column
+func NotNullViolation(column string) *MySQLError {
+	return New(ErBadNullError, "23000", fmt.Sprintf("Column '%s' cannot be null", column))
+}
+
+// DataTooLong is returned when a value does not fit into the column
+func DataTooLong(column string) *MySQLError {
+	return New(ErDataTooLong, "22001", fmt.Sprintf("Data too long for column '%s' at row 1", column))
+}
+
+// Deadlock is returned when the transaction was chosen as a deadlock victim,
+// which is also how InnoDB reports serialization failures
+func Deadlock() *MySQLError {
+	return New(ErLockDeadlock, "40001", "Deadlock found when trying to get lock; try restarting transaction")
+}
+
+// SerializationFailure is the same as Deadlock, since InnoDB does
+// not have a distinct serialization failure error
+func SerializationFailure() *MySQLError {
+	return Deadlock()
+}
+
+// LockWaitTimeout is returned when a row lock could not be acquired in time
+func LockWaitTimeout() *MySQLError {
+	return New(ErLockWaitTimeout, "HY000", "Lock wait timeout exceeded; try restarting transaction")
+}
+
+// QueryInterrupted is returned when a query was killed or timed out
+func QueryInterrupted() *MySQLError {
+	return New(ErQueryInterrupted, "70100", "Query execution was interrupted")
+}
+
+// TooManyConnections is returned when the server connection limit is reached
+func TooManyConnections() *MySQLError {
+	return New(ErConCountError, "08004", "Too many connections")
+}
+
+// ReadOnly is returned when writing to a server running with --read-only
+func ReadOnly() *MySQLError {
+	return New(ErOptionPreventsStmt, "HY000", "The MySQL server is running with the --read-only option so it cannot execute this statement")
+}
+
+// NoSuchTable is returned when the table does not exist
+func NoSuchTable(schema, table string) *MySQLError {
+	return New(ErNoSuchTable, "42S02", fmt.Sprintf("Table '%s.%s' doesn't exist", schema, table))
+}
+
+// UnknownColumn is returned when the column does not exist
+func UnknownColumn(column string) *MySQLError {
+	return New(ErBadFieldError, "42S22", fmt.Sprintf("Unknown column '%s' in 'field list'", column))
+}
+
+// SyntaxError is returned when the statement can not be parsed
+func SyntaxError(near string) *MySQLError {
+	return New(ErParseError, "42000", fmt.Sprintf("You have an error in your SQL syntax; check the manual that corresponds to your MySQL server version for the right syntax to use near '%s' at line 1", near))
+}
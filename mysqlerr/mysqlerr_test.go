@@ -0,0 +1,33 @@
+package mysqlerr
+
+import (
+	"testing"
+)
+
+func TestErrorShape(t *testing.T) {
+	err := DuplicateKey("users.email", "gedi@example.com")
+	if err.Number != 1062 {
+		t.Errorf("expected error number to be 1062, but got %d", err.Number)
+	}
+
+	if string(err.SQLState[:]) != "23000" {
+		t.Errorf("expected sql state to be 23000, but got %s", err.SQLState[:])
+	}
+
+	expected := "Error 1062 (23000): Duplicate entry 'gedi@example.com' for key 'users.email'"
+	if err.Error() != expected {
+		t.Errorf("expected error message to be '%s', but got '%s'", expected, err.Error())
+	}
+
+	if msg := (&MySQLError{Number: 1205, Message: "timeout"}).Error(); msg != "Error 1205: timeout" {
+		t.Errorf("expected error without sql state to be formatted as 'Error 1205: timeout', but got '%s'", msg)
+	}
+}
+
+func TestRetryableErrors(t *testing.T) {
+	for _, err := range []*MySQLError{Deadlock(), SerializationFailure(), LockWaitTimeout()} {
+		if err.Number != ErLockDeadlock && err.Number != ErLockWaitTimeout {
+			t.Errorf("expected a retryable error number, but got %d", err.Number)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+//go:build go1.8
+// +build go1.8
+
+package sqlmock
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+)
+
+// ExecContext implements driver.ExecerContext, so that
+// named arguments are validated against the query
+func (s *session) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if s.skipped() {
+		return nil, driver.ErrSkip
+	}
+	values, err := s.namedValues(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return s.exec(ctx, query, values)
+}
+
+// QueryContext implements driver.QueryerContext, so that
+// named arguments are validated against the query
+func (s *session) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	if s.skipped() {
+		return nil, driver.ErrSkip
+	}
+	values, err := s.namedValues(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return s.query(ctx, query, values)
+}
+
+// ExecContext implements driver.StmtExecContext
+func (stmt *statement) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	defer stmt.conn.through(stmt)()
+	return stmt.conn.ExecContext(ctx, stmt.query, args)
+}
+
+// QueryContext implements driver.StmtQueryContext
+func (stmt *statement) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	defer stmt.conn.through(stmt)()
+	return stmt.conn.QueryContext(ctx, stmt.query, args)
+}
+
+// ExecContext implements driver.StmtExecContext, copied
+// rows are positional, so names of arguments are ignored
+func (stmt *copyStatement) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	return stmt.Exec(values(args))
+}
+
+// QueryContext implements driver.StmtQueryContext
+func (stmt *copyStatement) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	return stmt.Query(values(args))
+}
+
+// the values of the arguments in order
+func values(args []driver.NamedValue) []driver.Value {
+	vs := make([]driver.Value, len(args))
+	for i, arg := range args {
+		vs[i] = arg.Value
+	}
+	return vs
+}
+
+// the values of the arguments in order. Fails like a driver would, if
+// named and positional arguments are mixed, or if a named argument is
+// not declared by the query, unless placeholders are not counted
+func (s *session) namedValues(query string, args []driver.NamedValue) ([]driver.Value, error) {
+	s.mu.Lock()
+	style := s.placeholders
+	s.mu.Unlock()
+
+	var positional, named *driver.NamedValue
+	for i := range args {
+		if args[i].Name == "" {
+			positional = &args[i]
+		} else if named == nil {
+			named = &args[i]
+		}
+	}
+	if named == nil {
+		return values(args), nil
+	}
+	if positional != nil {
+		return nil, fmt.Errorf("query '%s' mixes named argument '%s' at %d with positional argument %d", stripQuery(query), named.Name, named.Ordinal, positional.Ordinal)
+	}
+	if style == PermissivePlaceholders {
+		return values(args), nil
+	}
+
+	_, declared := scanPlaceholders(query, style)
+	for _, arg := range args {
+		if !declared[":"+arg.Name] && !declared["@"+arg.Name] {
+			return nil, fmt.Errorf("named argument '%s' is not declared by query '%s'", arg.Name, stripQuery(query))
+		}
+	}
+	return values(args), nil
+}
@@ -0,0 +1,66 @@
+//go:build go1.8
+// +build go1.8
+
+package sqlmock
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestNamedArguments(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectExec("UPDATE articles").WithArgs("hello", 5).WillReturnResult(NewResult(0, 1))
+	m.ExpectPrepare()
+	m.ExpectQuery("SELECT title FROM articles").WithArgs(5).WillReturnRow([]string{"title"}, "hello")
+
+	if _, err = db.Exec("UPDATE articles SET title = :title WHERE id = :id", sql.Named("title", "hello"), sql.Named("id", 5)); err != nil {
+		t.Errorf("error '%s' was not expected while updating articles", err)
+	}
+
+	stmt, err := db.Prepare("SELECT title FROM articles WHERE id = :id")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	var title string
+	if err = stmt.QueryRow(sql.Named("id", 5)).Scan(&title); err != nil {
+		t.Errorf("error '%s' was not expected while querying articles", err)
+	}
+	stmt.Close()
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestNamedArgumentMixUps(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	_, err = db.Exec("UPDATE articles SET title = :title WHERE id = ?", sql.Named("title", "hello"), 5)
+	if err == nil || !strings.Contains(err.Error(), "mixes named argument 'title' at 1 with positional argument 2") {
+		t.Errorf("expected an error for mixed arguments, but got: %v", err)
+	}
+
+	_, err = db.Exec("UPDATE articles SET title = :title WHERE id = :id", sql.Named("title", "hello"), sql.Named("article", 5))
+	if err == nil || !strings.Contains(err.Error(), "named argument 'article' is not declared") {
+		t.Errorf("expected an error for an undeclared argument, but got: %v", err)
+	}
+
+	_, err = db.Exec("UPDATE articles SET title = ? WHERE id = ?", sql.Named("title", "hello"), sql.Named("id", 5))
+	if err == nil || !strings.Contains(err.Error(), "named argument 'title' is not declared") {
+		t.Errorf("expected an error for named arguments of positional placeholders, but got: %v", err)
+	}
+
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
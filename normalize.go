@@ -0,0 +1,110 @@
+package sqlmock
+
+import (
+	"regexp"
+	"unicode"
+)
+
+// QueryMatching defines how queries and the expected regular
+// expressions are normalized before they are matched, so that
+// the same expectation works for queries generated for several
+// databases. Options may be combined
+type QueryMatching int
+
+const (
+	// ExactQueryMatching matches queries as they are,
+	// only whitespace is collapsed
+	ExactQueryMatching QueryMatching = 0
+	// IgnoreIdentifierQuotes treats `col`, "col" and [col] as col
+	IgnoreIdentifierQuotes QueryMatching = 1 << iota
+	// IgnoreSchemaPrefixes drops qualifiers of identifiers, so
+	// that public.users is users and u.id is id
+	IgnoreSchemaPrefixes
+	// IgnoreCase lowercases everything but string literals,
+	// so that select matches SELECT
+	IgnoreCase
+	// AnchorRegexes makes expected regexes match whole queries, as
+	// if they were ^...$, unless the expectation is Unanchored
+	AnchorRegexes
+	// FingerprintLiterals replaces string and number literals of
+	// queries with ?, like pt-query-digest fingerprints do, so that
+	// queries inlining literals are matched by their shape. Expected
+	// regexes are not changed, they are written for the fingerprint
+	FingerprintLiterals
+)
+
+var (
+	quotedIdentifier      = regexp.MustCompile("`([^`]*)`|\"([^\"]*)\"|\\[(\\w+)\\]")
+	quotedRegexIdentifier = regexp.MustCompile("`([^`]*)`|\"([^\"]*)\"|\\\\\\[(\\w+)\\\\\\]")
+	qualifier             = regexp.MustCompile(`(^|\W)(?:[A-Za-z_]\w*\.)+([A-Za-z_*])`)
+	regexQualifier        = regexp.MustCompile(`(^|[^\\\w])(?:[A-Za-z_]\w*\\?\.)+([A-Za-z_]|\\\*)`)
+	stringLiteral         = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numberLiteral         = regexp.MustCompile(`(^|[^\w$:.])(?:0[xX][0-9A-Fa-f]+|\d+(?:\.\d+)?(?:[eE][-+]?\d+)?)\b`)
+)
+
+// normalizes the query before it is matched
+func (m QueryMatching) normalize(query string) string {
+	if m&IgnoreIdentifierQuotes != 0 {
+		query = quotedIdentifier.ReplaceAllString(query, "$1$2$3")
+	}
+	if m&IgnoreSchemaPrefixes != 0 {
+		query = qualifier.ReplaceAllString(query, "$1$2")
+	}
+	if m&FingerprintLiterals != 0 {
+		query = stringLiteral.ReplaceAllString(query, "?")
+		query = numberLiteral.ReplaceAllString(query, "${1}?")
+	}
+	if m&IgnoreCase != 0 {
+		query = lower(query, false)
+	}
+	return query
+}
+
+// the expected regex normalized like queries are, the same
+// regex is returned if there is nothing to normalize
+func (m QueryMatching) adapt(re *regexp.Regexp) *regexp.Regexp {
+	expr := re.String()
+	if m&IgnoreIdentifierQuotes != 0 {
+		expr = quotedRegexIdentifier.ReplaceAllString(expr, "$1$2$3")
+	}
+	if m&IgnoreSchemaPrefixes != 0 {
+		expr = regexQualifier.ReplaceAllString(expr, "$1$2")
+	}
+	if m&IgnoreCase != 0 {
+		expr = lower(expr, true)
+	}
+	if m&AnchorRegexes != 0 {
+		expr = "^(?:" + expr + ")$"
+	}
+	if expr == re.String() {
+		return re
+	}
+	if adapted, err := regexp.Compile(expr); err == nil {
+		return adapted
+	}
+	return re
+}
+
+// lowercases everything outside of string literals. Letters of
+// regex escapes, like \S or \p{Lu}, are left as they are
+func lower(s string, regex bool) string {
+	rs := []rune(s)
+	quoted := false
+	for i := 0; i < len(rs); i++ {
+		switch {
+		case rs[i] == '\'':
+			quoted = !quoted
+		case quoted:
+		case regex && rs[i] == '\\' && i+1 < len(rs):
+			i++
+			if (rs[i] == 'p' || rs[i] == 'P') && i+1 < len(rs) && rs[i+1] == '{' {
+				for i < len(rs) && rs[i] != '}' {
+					i++
+				}
+			}
+		default:
+			rs[i] = unicode.ToLower(rs[i])
+		}
+	}
+	return string(rs)
+}
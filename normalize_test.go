@@ -0,0 +1,179 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"regexp"
+	"testing"
+)
+
+func TestQueryNormalization(t *testing.T) {
+	m := IgnoreIdentifierQuotes | IgnoreSchemaPrefixes
+	cases := map[string]string{
+		"SELECT `id`, `name` FROM `shop`.`users`":           "SELECT id, name FROM users",
+		`SELECT "id", "name" FROM "public"."users"`:         "SELECT id, name FROM users",
+		"SELECT [id], [name] FROM [dbo].[users]":            "SELECT id, name FROM users",
+		"SELECT u.id, u.* FROM users u WHERE u.price > 1.5": "SELECT id, * FROM users u WHERE price > 1.5",
+	}
+	for query, expected := range cases {
+		if normalized := m.normalize(query); normalized != expected {
+			t.Errorf("expected query '%s' to be normalized to '%s', but got '%s'", query, expected, normalized)
+		}
+	}
+
+	regexes := map[string]string{
+		"SELECT (.+) FROM `users`":            "SELECT (.+) FROM users",
+		`SELECT (.+) FROM \[dbo\]\.\[users\]`: `SELECT (.+) FROM users`,
+		`SELECT u\.\* FROM public.users`:      `SELECT \* FROM users`,
+		`SELECT (.+) FROM users.*`:            `SELECT (.+) FROM users.*`,
+		`\d+\.\d+`:                            `\d+\.\d+`,
+	}
+	for expr, expected := range regexes {
+		if adapted := m.adapt(regexp.MustCompile(expr)).String(); adapted != expected {
+			t.Errorf("expected regex '%s' to be normalized to '%s', but got '%s'", expr, expected, adapted)
+		}
+	}
+
+	if re := regexp.MustCompile("SELECT `id`"); ExactQueryMatching.adapt(re) != re {
+		t.Errorf("expected the same regex, when queries are matched exactly")
+	}
+}
+
+func TestQueryMatchingOfDialects(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectQuery(`SELECT (.+) FROM "public"\."users" WHERE "id" = \?`).
+		WillReturnRow([]string{"name"}, "gedi")
+	SetQueryMatching(IgnoreIdentifierQuotes | IgnoreSchemaPrefixes)
+	defer SetQueryMatching(ExactQueryMatching)
+	ExpectQuery(`SELECT (.+) FROM users WHERE id = \?`).
+		WillReturnRow([]string{"name"}, "gedi")
+
+	var name string
+	for _, query := range []string{
+		"SELECT `name` FROM `shop`.`users` WHERE `id` = ?",
+		"SELECT [name] FROM [dbo].[users] WHERE [id] = ?",
+	} {
+		if err = db.QueryRow(query, 1).Scan(&name); err != nil {
+			t.Errorf("error '%s' was not expected while selecting with query '%s'", err, query)
+		}
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestIgnoreCaseKeepsStringLiterals(t *testing.T) {
+	if q := IgnoreCase.normalize("SELECT Name FROM Users WHERE title = 'It''s Mine'"); q != "select name from users where title = 'It''s Mine'" {
+		t.Errorf("expected only string literals to keep their case, but got '%s'", q)
+	}
+
+	regexes := map[string]string{
+		`SELECT (.+) FROM Users WHERE title = 'Mine'`: `select (.+) from users where title = 'Mine'`,
+		`SELECT \S+ FROM \p{Lu}\w*`:                   `select \S+ from \p{Lu}\w*`,
+		`INSERT INTO [A-Z]+`:                          `insert into [a-z]+`,
+	}
+	for expr, expected := range regexes {
+		if adapted := IgnoreCase.adapt(regexp.MustCompile(expr)).String(); adapted != expected {
+			t.Errorf("expected regex '%s' to be lowercased to '%s', but got '%s'", expr, expected, adapted)
+		}
+	}
+}
+
+func TestQueryMatchingIgnoringCase(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	SetQueryMatching(IgnoreCase)
+	defer SetQueryMatching(ExactQueryMatching)
+	ExpectExec("UPDATE users SET status = 'Active'").WillReturnRowsAffected(1)
+	if _, err = db.Exec("Update Users Set status = 'active'"); err == nil {
+		t.Errorf("expected an error, since string literals must match case")
+	}
+
+	ExpectExec("UPDATE users SET status = 'Active'").WillReturnRowsAffected(1)
+	ExpectExec("update users set status = 'Active'").WillReturnRowsAffected(1)
+	for _, query := range []string{"Update Users Set status = 'Active'", "UPDATE users SET status = 'Active'"} {
+		if _, err = db.Exec(query); err != nil {
+			t.Errorf("error '%s' was not expected while updating with query '%s'", err, query)
+		}
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestAnchoredRegexes(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	SetQueryMatching(AnchorRegexes)
+	defer SetQueryMatching(ExactQueryMatching)
+
+	ExpectQuery("SELECT").WillReturnRow([]string{"id"}, 1)
+	var id int
+	if err = db.QueryRow("SELECT id FROM users").Scan(&id); err == nil {
+		t.Errorf("expected an error, since the regex matches only a part of the query")
+	}
+
+	ExpectQuery(`SELECT (.+) FROM users WHERE id = \?`).WillReturnRow([]string{"id"}, 1)
+	ExpectQuery("SELECT").Unanchored().WillReturnRow([]string{"id"}, 2)
+	ExpectCall("refresh_stats").WillReturnResult(NewResult(0, 0))
+
+	if err = db.QueryRow("SELECT id FROM users WHERE id = ?", 1).Scan(&id); err != nil {
+		t.Errorf("error '%s' was not expected while selecting with a regex matching the whole query", err)
+	}
+	if err = db.QueryRow("SELECT id FROM orders").Scan(&id); err != nil || id != 2 {
+		t.Errorf("expected an unanchored regex to match a part of the query, but got %d and error: %v", id, err)
+	}
+	if _, err = db.Exec("CALL refresh_stats()"); err != nil {
+		t.Errorf("error '%s' was not expected while calling a procedure", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestFingerprintLiterals(t *testing.T) {
+	queries := map[string]string{
+		"SELECT * FROM users WHERE name = 'O''Brien' AND age > 21":     "SELECT * FROM users WHERE name = ? AND age > ?",
+		"SELECT * FROM t1 WHERE id IN (1,2, 3.5) AND flags = 0x1F":     "SELECT * FROM t1 WHERE id IN (?,?, ?) AND flags = ?",
+		"UPDATE users SET score = -1.5e3 WHERE id = $1 AND org = :2":   "UPDATE users SET score = -? WHERE id = $1 AND org = :2",
+		"INSERT INTO logs (msg, level) VALUES ('disk 95% full', 4), 5": "INSERT INTO logs (msg, level) VALUES (?, ?), ?",
+	}
+	for query, expected := range queries {
+		if fp := FingerprintLiterals.normalize(query); fp != expected {
+			t.Errorf("expected query '%s' to be fingerprinted as '%s', but got '%s'", query, expected, fp)
+		}
+	}
+
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	SetQueryMatching(FingerprintLiterals)
+	defer SetQueryMatching(ExactQueryMatching)
+
+	ExpectExec(`UPDATE users SET status = \? WHERE id = \?`).WillReturnRowsAffected(1)
+	ExpectExec(`^UPDATE users SET status = \? WHERE id = \?$`).WithArgs(7).WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE users SET status = 'active' WHERE id = 5"); err != nil {
+		t.Errorf("error '%s' was not expected while updating with inlined literals", err)
+	}
+	if _, err = db.Exec("UPDATE users SET status = 'active' WHERE id = ?", 7); err != nil {
+		t.Errorf("error '%s' was not expected while updating with inlined and bound values", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
@@ -0,0 +1,132 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+)
+
+// Option configures a mock as it is created by New or NewMock.
+// New resets the settings of the package level mock to defaults
+// before the options are applied, so that none of them leak from
+// one test into the next. Those include the settings made by the
+// package functions, like Chaos, Latency, Permissive, RecycleConns,
+// AutoIncrement or Passthrough
+type Option func(*MockDB)
+
+// MonitorPingsOption sets whether pings are matched against
+// expectations, declared with ExpectPing. By default pings are
+// incidental and always succeed
+func MonitorPingsOption(monitor bool) Option {
+	return func(m *MockDB) {
+		m.conn.monitorPings = monitor
+	}
+}
+
+// DirectCallsOption sets whether Exec and Query calls may be made
+// directly on connections. Disabled, connections act like the ones
+// of a driver, which implements neither driver.Execer nor Queryer,
+// so database/sql prepares a statement for every call. By default
+// direct calls are enabled
+func DirectCallsOption(enabled bool) Option {
+	return func(m *MockDB) {
+		m.conn.noDirect = !enabled
+	}
+}
+
+// QueryMatchingOption sets how queries and expected
+// regular expressions are normalized, like SetQueryMatching
+func QueryMatchingOption(matching QueryMatching) Option {
+	return func(m *MockDB) {
+		m.conn.matching = matching
+		m.conn.adapt()
+	}
+}
+
+// PlaceholderStyleOption sets which placeholders are counted
+// in prepared statements, like SetPlaceholderStyle
+func PlaceholderStyleOption(style PlaceholderStyle) Option {
+	return func(m *MockDB) {
+		m.conn.placeholders = style
+	}
+}
+
+// MatchExpectationsInOrderOption sets whether expectations are
+// matched in declaration order, like MatchExpectationsInOrder
+func MatchExpectationsInOrderOption(ordered bool) Option {
+	return func(m *MockDB) {
+		m.conn.unordered = !ordered
+	}
+}
+
+// SQLFormatOption sets how queries are printed
+// in failure messages, like SetSQLFormat
+func SQLFormatOption(format SQLFormat) Option {
+	return func(m *MockDB) {
+		m.conn.format = format
+	}
+}
+
+// VerbosityOption sets how much context failure
+// messages include, like SetVerbosity
+func VerbosityOption(v Verbosity) Option {
+	return func(m *MockDB) {
+		m.conn.verbosity = v
+	}
+}
+
+// MaxConnsOption makes opening a connection fail with the error, or
+// ErrTooManyConns if it is nil, while n connections are open, like an
+// overloaded database does. So that pool sizing and backpressure may
+// be tested. Zero allows any number of connections
+func MaxConnsOption(n int, err error) Option {
+	return func(m *MockDB) {
+		m.conn.maxConns, m.conn.maxConnsErr = n, err
+	}
+}
+
+// ValueConverterOption sets the converter of arguments of statements,
+// whether prepared or not, and of values of the returned rows, so that
+// conversions of a driver, like bools sent as integers or truncated
+// strings, may be emulated and the code under test sees the values it
+// would in production. The converter must return values of the types
+// database/sql/driver.IsValue accepts
+func ValueConverterOption(converter driver.ValueConverter) Option {
+	return func(m *MockDB) {
+		m.conn.converter = converter
+	}
+}
+
+// applies the options to the mock on top of the default settings
+func (m *MockDB) configure(opts []Option) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.defaults()
+	for _, opt := range opts {
+		opt(m)
+	}
+}
+
+// resets the settings, which may be changed by options or
+// by the setters of the package level mock, to defaults
+func (c *conn) defaults() {
+	c.monitorPings, c.noDirect, c.noAmbiguity, c.unordered = false, false, false, false
+	c.placeholders, c.format, c.verbosity = AnyPlaceholders, PlainSQL, NormalOutput
+	c.maxConns, c.maxConnsErr = 0, nil
+	c.converter, c.tracer, c.stackDepth = nil, nil, 0
+	c.broadRegexes = AllowBroadPatterns
+	c.invalid, c.faults, c.chaos, c.permissive = false, nil, nil, nil
+	c.latency, c.jitter = 0, 0
+	c.connLifetime, c.connCalls = 0, 0
+	c.passthrough = nil
+	c.autoStart, c.autoStep, c.autoNext = 0, 0, 0
+	if c.matching != ExactQueryMatching {
+		c.matching = ExactQueryMatching
+		c.adapt()
+	}
+}
+
+// whether pings are matched against expectations
+func (c *conn) monitoringPings() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.monitorPings
+}
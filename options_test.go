@@ -0,0 +1,235 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNewWithOptions(t *testing.T) {
+	db, err := New(QueryMatchingOption(IgnoreCase), MatchExpectationsInOrderOption(false))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer func() {
+		SetQueryMatching(ExactQueryMatching)
+		MatchExpectationsInOrder(true)
+	}()
+
+	ExpectExec("update articles").WillReturnResult(NewResult(0, 1))
+	ExpectQuery("select title from articles").WillReturnRow([]string{"title"}, "hello")
+
+	var title string
+	if err = db.QueryRow("SELECT title FROM articles").Scan(&title); err != nil {
+		t.Errorf("error '%s' was not expected while querying articles", err)
+	}
+	if _, err = db.Exec("UPDATE articles SET title = ?", "hi"); err != nil {
+		t.Errorf("error '%s' was not expected while updating articles", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestNewResetsSettings(t *testing.T) {
+	db, err := New(MatchExpectationsInOrderOption(false), MaxConnsOption(1, nil))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	db.Close()
+
+	SetPlaceholderStyle(PermissivePlaceholders)
+	db, err = New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	mock.conn.mu.Lock()
+	unordered, maxConns, style := mock.conn.unordered, mock.conn.maxConns, mock.conn.placeholders
+	mock.conn.mu.Unlock()
+	if unordered || maxConns != 0 {
+		t.Errorf("expected New without options not to inherit the options of a previous New")
+	}
+	if style != AnyPlaceholders {
+		t.Errorf("expected New without options to reset the placeholder style")
+	}
+	db.Close()
+}
+
+func TestNewResetsSettingsOfPackageFunctions(t *testing.T) {
+	other, _, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer other.Close()
+
+	InvalidateConn()
+	FailAfter(1, fmt.Errorf("connection lost"))
+	Chaos(1, 0.5)
+	Latency(time.Second, time.Second)
+	RecycleConns(time.Second, 1)
+	Permissive(nil, nil)
+	AutoIncrement(1, 1)
+	Passthrough(other)
+
+	db, err := New()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	c := mock.conn
+	c.mu.Lock()
+	clean := !c.invalid && c.faults == nil && c.chaos == nil && c.latency == 0 && c.jitter == 0 &&
+		c.connLifetime == 0 && c.connCalls == 0 && c.permissive == nil && c.autoStep == 0 && c.passthrough == nil
+	c.mu.Unlock()
+	if !clean {
+		t.Errorf("expected New to reset the settings made by package functions")
+	}
+	db.Close()
+}
+
+// converts bools to integers and truncates strings, like some drivers do
+type mysqlConverter struct{}
+
+func (mysqlConverter) ConvertValue(v interface{}) (driver.Value, error) {
+	switch v := v.(type) {
+	case bool:
+		if v {
+			return int64(1), nil
+		}
+		return int64(0), nil
+	case string:
+		if len(v) > 5 {
+			return v[:5], nil
+		}
+		return v, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
+
+func TestValueConverterOption(t *testing.T) {
+	db, m, err := NewMock(ValueConverterOption(mysqlConverter{}))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectPrepare()
+	m.ExpectExec("UPDATE articles").WithArgs("hello", int64(1), int64(7)).WillReturnResult(NewResult(0, 1))
+
+	stmt, err := db.Prepare("UPDATE articles SET title = ?, published = ? WHERE id = ?")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	if _, err = stmt.Exec("hello world", true, 7); err != nil {
+		t.Errorf("error '%s' was not expected while executing a statement", err)
+	}
+	stmt.Close()
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestMaxConnsOption(t *testing.T) {
+	db, mock, err := NewMock(MaxConnsOption(2, nil))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+	mock.ExpectRollback()
+
+	tx1, err := db.Begin()
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while beginning a transaction", err)
+	}
+	tx2, err := db.Begin()
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while beginning a transaction", err)
+	}
+	if _, err = db.Begin(); err != ErrTooManyConns {
+		t.Errorf("expected ErrTooManyConns, since 2 connections are open, but got: %v", err)
+	}
+
+	if err = tx1.Rollback(); err != nil {
+		t.Errorf("error '%s' was not expected while rolling back", err)
+	}
+	tx3, err := db.Begin()
+	if err != nil {
+		t.Fatalf("error '%s' was not expected, since a connection was released to the pool", err)
+	}
+	tx2.Rollback()
+	tx3.Rollback()
+
+	if err = mock.ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations were not met: %s", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestDirectCallsOption(t *testing.T) {
+	db, m, err := NewMock(DirectCallsOption(false))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectPrepare()
+	m.ExpectExec("UPDATE articles").WithArgs("hi").WillReturnResult(NewResult(0, 1))
+	m.ExpectPrepare().WillReturnError(driver.ErrBadConn)
+	m.ExpectPrepare()
+	m.ExpectQuery("SELECT title FROM articles").WillReturnRow([]string{"title"}, "hello")
+
+	if _, err = db.Exec("UPDATE articles SET title = ?", "hi"); err != nil {
+		t.Errorf("error '%s' was not expected while updating articles", err)
+	}
+	// the bad connection is retried on another one
+	var title string
+	if err = db.QueryRow("SELECT title FROM articles").Scan(&title); err != nil || title != "hello" {
+		t.Errorf("expected the title to be queried through a prepared statement, but got %s and error: %v", title, err)
+	}
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	var calls []string
+	for _, call := range m.History() {
+		calls = append(calls, call.Call)
+	}
+	expected := []string{"sql.ping", "sql.prepare", "sql.exec", "sql.prepare", "sql.prepare", "sql.query"}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected calls %v, but got %v", expected, calls)
+	}
+	for i := range calls {
+		if calls[i] != expected[i] {
+			t.Errorf("expected calls %v, but got %v", expected, calls)
+			break
+		}
+	}
+	db.Close()
+}
+
+func TestValueConverterOptionOfRows(t *testing.T) {
+	db, m, err := NewMock(ValueConverterOption(mysqlConverter{}))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectQuery("SELECT (.+) FROM articles").WillReturnRows(NewRows([]string{"title", "published"}).AddRow("hello world", true))
+
+	var title string
+	var published interface{}
+	if err = db.QueryRow("SELECT title, published FROM articles").Scan(&title, &published); err != nil {
+		t.Errorf("error '%s' was not expected while selecting an article", err)
+	}
+	if title != "hello" || published != int64(1) {
+		t.Errorf("expected the row to be converted like the driver does, but got %q and %v", title, published)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
@@ -0,0 +1,17 @@
+//go:build !go1.9
+// +build !go1.9
+
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// output parameters are supported since go1.9 only
+func setOutParams(args []driver.Value, values []driver.Value) error {
+	if len(values) > 0 {
+		return fmt.Errorf("output parameters require sql.Out, which is available since go1.9")
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+//go:build go1.9
+// +build go1.9
+
+package sqlmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// CheckNamedValue accepts sql.Out arguments for procedure calls
+// and converts everything else by the value converter of the mock,
+// if it is set, or leaves it to the default converter
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) (err error) {
+	if _, ok := nv.Value.(sql.Out); ok {
+		return nil
+	}
+	c.mu.Lock()
+	converter := c.converter
+	c.mu.Unlock()
+	if converter == nil {
+		return driver.ErrSkip
+	}
+	nv.Value, err = converter.ConvertValue(nv.Value)
+	return err
+}
+
+// assigns values to the sql.Out arguments in order
+func setOutParams(args []driver.Value, values []driver.Value) error {
+	var n int
+	for _, arg := range args {
+		out, ok := arg.(sql.Out)
+		if !ok || n == len(values) {
+			continue
+		}
+
+		dest := reflect.ValueOf(out.Dest)
+		if dest.Kind() != reflect.Ptr || dest.IsNil() {
+			return fmt.Errorf("output parameter %d destination must be a non nil pointer, but got %T", n+1, out.Dest)
+		}
+
+		if values[n] == nil {
+			dest.Elem().Set(reflect.Zero(dest.Elem().Type()))
+		} else {
+			val := reflect.ValueOf(values[n])
+			if !val.Type().ConvertibleTo(dest.Elem().Type()) {
+				return fmt.Errorf("output parameter %d value %T cannot be assigned to %T", n+1, values[n], out.Dest)
+			}
+			dest.Elem().Set(val.Convert(dest.Elem().Type()))
+		}
+		n++
+	}
+
+	if n != len(values) {
+		return fmt.Errorf("%d output parameters were expected to be returned, but the call has only %d sql.Out arguments", len(values), n)
+	}
+	return nil
+}
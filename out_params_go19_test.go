@@ -0,0 +1,62 @@
+//go:build go1.9
+// +build go1.9
+
+package sqlmock
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestCallWithOutParams(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectCall("order_totals").
+		WithArgs(5, sql.Out{}, sql.Out{}).
+		WillReturnOutParams(3, 25.5)
+
+	var count int
+	var total float64
+	_, err = db.Exec("CALL order_totals(?, ?, ?)", 5, sql.Out{Dest: &count}, sql.Out{Dest: &total})
+	if err != nil {
+		t.Errorf("error '%s' was not expected while calling a procedure", err)
+	}
+
+	if count != 3 {
+		t.Errorf("expected count output parameter to be 3, but got %d", count)
+	}
+
+	if total != 25.5 {
+		t.Errorf("expected total output parameter to be 25.5, but got %f", total)
+	}
+
+	ExpectCall("order_totals").WillReturnOutParams(3, 25.5)
+
+	if _, err = db.Exec("CALL order_totals(?, ?)", 5, sql.Out{Dest: &count}); err == nil {
+		t.Error("an error was expected, since more output parameters were returned than requested")
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestValueConverterOptionOfDirectCalls(t *testing.T) {
+	db, m, err := NewMock(ValueConverterOption(mysqlConverter{}))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectExec("UPDATE articles").WithArgs("hello", int64(1), int64(7)).WillReturnResult(NewResult(0, 1))
+
+	if _, err = db.Exec("UPDATE articles SET title = ?, published = ? WHERE id = ?", "hello world", true, 7); err != nil {
+		t.Errorf("error '%s' was not expected, since the args are converted like the driver does", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
@@ -0,0 +1,82 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+)
+
+// Passthrough delegates Exec and Query calls, which no expectation nor
+// registered table matches, to the given database, like an in-memory
+// SQLite. So that only the interesting calls are mocked, while the rest
+// hit a lightweight real engine. Calls are delegated outside of any
+// transaction, since transactions of the mock are not begun on the
+// database, and rows are read before they are returned. The database
+// must not be one of the same mock. Persists until turned off with a
+// nil database
+func Passthrough(db *sql.DB) {
+	mock.Passthrough(db)
+}
+
+// Passthrough delegates calls no expectation matches to the database
+func (m *MockDB) Passthrough(db *sql.DB) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.passthrough = db
+}
+
+// execs the query on the passthrough database
+func (c *conn) passExec(query string, args []driver.Value) (driver.Result, error) {
+	res, err := c.passthrough.Exec(query, passArgs(args)...)
+	if err != nil {
+		return nil, err
+	}
+	r := &result{}
+	if r.insertID, err = res.LastInsertId(); err != nil {
+		return res, nil // the errors of the result are kept
+	}
+	if r.rowsAffected, err = res.RowsAffected(); err != nil {
+		return res, nil
+	}
+	return r, nil
+}
+
+// queries the passthrough database and reads the rows
+func (c *conn) passQuery(query string, args []driver.Value) (driver.Rows, error) {
+	rs, err := c.passthrough.Query(query, passArgs(args)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	columns, err := rs.Columns()
+	if err != nil {
+		return nil, err
+	}
+	// columns of a database may repeat, like the ids of a join,
+	// so they are not validated the way NewRows does
+	read := &rows{cols: columns}
+	for rs.Next() {
+		values := make([]interface{}, len(columns))
+		dest := make([]interface{}, len(columns))
+		for i := range values {
+			dest[i] = &values[i]
+		}
+		if err = rs.Scan(dest...); err != nil {
+			return nil, err
+		}
+		row := make([]driver.Value, len(values))
+		for i, v := range values {
+			row[i] = v
+		}
+		read.AddRow(row...)
+	}
+	return read, rs.Err()
+}
+
+func passArgs(args []driver.Value) []interface{} {
+	values := make([]interface{}, len(args))
+	for i, arg := range args {
+		values[i] = arg
+	}
+	return values
+}
@@ -0,0 +1,74 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestPassthrough(t *testing.T) {
+	// another mock stands in for a real database
+	real, engine, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	engine.ExpectExec("INSERT INTO users").WithArgs("gedi").WillReturnResult(NewResult(7, 1))
+	engine.ExpectQuery("SELECT name FROM users").WillReturnRow([]string{"name"}, "gedi")
+
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	Passthrough(real)
+	defer Passthrough(nil)
+	ExpectExec("DELETE FROM users").WillReturnRowsAffected(3)
+
+	res, err := db.Exec("INSERT INTO users (name) VALUES (?)", "gedi")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while inserting", err)
+	}
+	if id, _ := res.LastInsertId(); id != 7 {
+		t.Errorf("expected the result of the database, but got insert id %d", id)
+	}
+	var name string
+	if err = db.QueryRow("SELECT name FROM users WHERE id = ?", 7).Scan(&name); err != nil || name != "gedi" {
+		t.Errorf("expected the row read from the database, but got %s and error: %v", name, err)
+	}
+	if res, err = db.Exec("DELETE FROM users"); err != nil {
+		t.Fatalf("error '%s' was not expected while deleting", err)
+	}
+	if n, _ := res.RowsAffected(); n != 3 {
+		t.Errorf("expected the expectation to intercept the call, but got %d rows affected", n)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+	if err = engine.ExpectationsWereMet(); err != nil {
+		t.Errorf("expected the unmatched calls to reach the database: %s", err)
+	}
+	real.Close()
+}
+
+func TestPassthroughOfRepeatedColumns(t *testing.T) {
+	real, engine, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	// a join of a database returns the ids of both tables
+	joined := &rows{cols: []string{"id", "id"}}
+	joined.AddRow(1, 2)
+	engine.ExpectQuery("SELECT a.id, b.id FROM a JOIN b").WillReturnRows(joined)
+
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	m.Passthrough(real)
+
+	var a, b int
+	if err = db.QueryRow("SELECT a.id, b.id FROM a JOIN b ON b.a_id = a.id").Scan(&a, &b); err != nil || a != 1 || b != 2 {
+		t.Errorf("expected the ids of the join, but got %d, %d and error: %v", a, b, err)
+	}
+	db.Close()
+	real.Close()
+}
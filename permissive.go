@@ -0,0 +1,26 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+)
+
+// canned results of the permissive mode, returned
+// for every call without matching expectations
+type permissive struct {
+	result driver.Result
+	rows   driver.Rows
+}
+
+func (p *permissive) exec() driver.Result {
+	if p.result == nil {
+		return &result{}
+	}
+	return p.result
+}
+
+func (p *permissive) query() driver.Rows {
+	if p.rows == nil {
+		return &rows{}
+	}
+	return cloneRows(p.rows)
+}
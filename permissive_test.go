@@ -0,0 +1,73 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"sync"
+	"testing"
+)
+
+func TestPermissiveModeSucceedsWithoutExpectations(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	Permissive(NewResult(7, 1), NewRows([]string{"id", "title"}).AddRow(1, "one").AddRow(2, "two"))
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			res, err := db.Exec("UPDATE articles SET title = ? WHERE id = ?", "hello", i)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if id, _ := res.LastInsertId(); id != 7 {
+				t.Errorf("expected the canned result, but got last insert id %d", id)
+			}
+
+			tx, err := db.Begin()
+			if err != nil {
+				errs <- err
+				return
+			}
+			var n int
+			if err = tx.QueryRow("SELECT COUNT(*) FROM articles WHERE id = ?", i).Scan(&n, new(string)); err != nil {
+				errs <- err
+			}
+			if err = tx.Commit(); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("error '%s' was not expected in permissive mode", err)
+	}
+
+	stmt, err := db.Prepare("DELETE FROM articles WHERE id = ?")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while preparing a statement", err)
+	}
+	if _, err = stmt.Exec(1); err != nil {
+		t.Errorf("error '%s' was not expected while executing a statement", err)
+	}
+
+	if h := History(); len(h) != 0 {
+		t.Errorf("expected no calls to be recorded in permissive mode, but got %d", len(h))
+	}
+
+	StopPermissive()
+	if _, err = db.Exec("UPDATE articles SET title = ?", "hello"); err == nil {
+		t.Errorf("expected an error, since expectations are matched again")
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Package pgconnerr provides constructors of common PostgreSQL errors,
+shaped like pgconn.PgError of github.com/jackc/pgx, to be returned by
+sqlmock expectations:
+
+	sqlmock.ExpectExec("INSERT INTO users").
+		WillReturnError(pgconnerr.UniqueViolation("users_email_key"))
+
+Error classification code which inspects the SQLSTATE code can be
+tested against the mock without a real database.
+*/
+package pgconnerr
+
+// error codes of the errors in this catalog
+const (
+	UniqueViolationCode      = "23505"
+	ForeignKeyViolationCode  = "23503"
+	NotNullViolationCode     = "23502"
+	CheckViolationCode       = "23514"
+	SerializationFailureCode = "40001"
+	DeadlockDetectedCode     = "40P01"
+	LockNotAvailableCode     = "55P03"
+	QueryCanceledCode        = "57014"
+	TooManyConnectionsCode   = "53300"
+	ReadOnlyTransactionCode  = "25006"
+	UndefinedTableCode       = "42P01"
+	UndefinedColumnCode      = "42703"
+	SyntaxErrorCode          = "42601"
+)
+
+// PgError has the same fields as pgconn.PgError
+type PgError struct {
+	Severity            string
+	SeverityUnlocalized string
+	Code                string
+	Message             string
+	Detail              string
+	Hint                string
+	Position            int32
+	InternalPosition    int32
+	InternalQuery       string
+	Where               string
+	SchemaName          string
+	TableName           string
+	ColumnName          string
+	DataTypeName        string
+	ConstraintName      string
+	File                string
+	Line                int32
+	Routine             string
+}
+
+func (pe *PgError) Error() string {
+	return pe.Severity + ": " + pe.Message + " (SQLSTATE " + pe.Code + ")"
+}
+
+// SQLState returns the SQLSTATE code of the error
+func (pe *PgError) SQLState() string {
+	return pe.Code
+}
+
+// New creates an error with the given code and message
+func New(code string, message string) *PgError {
+	return &PgError{Severity: "ERROR", SeverityUnlocalized: "ERROR", Code: code, Message: message}
+}
+
+// UniqueViolation is returned when a unique constraint is violated
+func UniqueViolation(constraint string) *PgError {
+	e := New(UniqueViolationCode, `duplicate key value violates unique constraint "`+constraint+`"`)
+	e.ConstraintName = constraint
+	return e
+}
+
+// ForeignKeyViolation is returned when a foreign key constraint is violated
+func ForeignKeyViolation(table, constraint string) *PgError {
+	e := New(ForeignKeyViolationCode, `insert or update on table "`+table+`" violates foreign key constraint "`+constraint+`"`)
+	e.TableName, e.ConstraintName = table, constraint
+	return e
+}
+
+// NotNullViolation is returned when NULL is stored in a NOT NULL column
+func NotNullViolation(table, column string) *PgError {
+	e := New(NotNullViolationCode, `null value in column "`+column+`" of relation "`+table+`" violates not-null constraint`)
+	e.TableName, e.ColumnName = table, column
+	return e
+}
+
+// CheckViolation is returned when a check constraint is violated
+func CheckViolation(table, constraint string) *PgError {
+	e := New(CheckViolationCode, `new row for relation "`+table+`" violates check constraint "`+constraint+`"`)
+	e.TableName, e.ConstraintName = table, constraint
+	return e
+}
+
+// SerializationFailure is returned when a serializable transaction must be retried
+func SerializationFailure() *PgError {
+	return New(SerializationFailureCode, "could not serialize access due to concurrent update")
+}
+
+// Deadlock is returned when the transaction was chosen as a deadlock victim
+func Deadlock() *PgError {
+	return New(DeadlockDetectedCode, "deadlock detected")
+}
+
+// LockWaitTimeout is returned when a lock could not be acquired in time or with NOWAIT
+func LockWaitTimeout() *PgError {
+	return New(LockNotAvailableCode, "canceling statement due to lock timeout")
+}
+
+// QueryCanceled is returned when a statement timed out or was canceled
+func QueryCanceled() *PgError {
+	return New(QueryCanceledCode, "canceling statement due to statement timeout")
+}
+
+// TooManyConnections is returned when the server connection limit is reached
+func TooManyConnections() *PgError {
+	e := New(TooManyConnectionsCode, "sorry, too many clients already")
+	e.Severity, e.SeverityUnlocalized = "FATAL", "FATAL"
+	return e
+}
+
+// ReadOnlyTransaction is returned when writing in a read only transaction
+func ReadOnlyTransaction(statement string) *PgError {
+	return New(ReadOnlyTransactionCode, "cannot execute "+statement+" in a read-only transaction")
+}
+
+// UndefinedTable is returned when the relation does not exist
+func UndefinedTable(table string) *PgError {
+	return New(UndefinedTableCode, `relation "`+table+`" does not exist`)
+}
+
+// UndefinedColumn is returned when the column does not exist
+func UndefinedColumn(column string) *PgError {
+	return New(UndefinedColumnCode, `column "`+column+`" does not exist`)
+}
+
+// SyntaxError is returned when the statement can not be parsed
+func SyntaxError(near string) *PgError {
+	return New(SyntaxErrorCode, `syntax error at or near "`+near+`"`)
+}
@@ -0,0 +1,21 @@
+package pgconnerr
+
+import (
+	"testing"
+)
+
+func TestErrorShape(t *testing.T) {
+	err := ForeignKeyViolation("orders", "orders_user_id_fkey")
+	if err.SQLState() != "23503" {
+		t.Errorf("expected sql state to be 23503, but got %s", err.SQLState())
+	}
+
+	expected := `ERROR: insert or update on table "orders" violates foreign key constraint "orders_user_id_fkey" (SQLSTATE 23503)`
+	if err.Error() != expected {
+		t.Errorf("expected error message to be '%s', but got '%s'", expected, err.Error())
+	}
+
+	if err.TableName != "orders" || err.ConstraintName != "orders_user_id_fkey" {
+		t.Errorf("expected table and constraint to be set, but got %+v", err)
+	}
+}
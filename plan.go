@@ -0,0 +1,137 @@
+package sqlmock
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// ExpectationPlan describes the declared expectations, the groups
+// they form and the order they are required to be matched in. It
+// is printed in a human readable form and marshals to JSON
+type ExpectationPlan struct {
+	Ordered bool       `json:"ordered"` // whether expectations are matched in declaration order
+	Steps   []PlanStep `json:"steps"`
+}
+
+// PlanStep is either an expectation, in declaration order,
+// or a group of them, which follow the expectations
+type PlanStep struct {
+	ID          int    `json:"id"`
+	Expectation string `json:"expectation"`
+	Declared    string `json:"declared,omitempty"`
+	Times       int    `json:"times,omitempty"`   // calls to be matched, if more than one
+	Members     []int  `json:"members,omitempty"` // of a group
+	Ordered     bool   `json:"ordered,omitempty"` // whether members of a group are matched in order
+	Group       int    `json:"group,omitempty"`   // the step is a member of
+	After       []int  `json:"after,omitempty"`   // steps which must be matched before
+	Conn        string `json:"conn,omitempty"`    // the connection the step is scoped to
+	Matched     int    `json:"matched"`
+	Satisfied   bool   `json:"satisfied"`
+}
+
+// Plan describes every expectation declared since the connection was
+// last closed or reset, with the groups and the order they form. So
+// that generated expectations may be reviewed, or the plan attached
+// to a report of a failure
+func Plan() ExpectationPlan {
+	return mock.Plan()
+}
+
+// Plan describes the expectations declared on the mock
+func (m *MockDB) Plan() ExpectationPlan {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	return m.conn.plan()
+}
+
+// the plan of the declared expectations, groups are given
+// the ids following the ones of the expectations
+func (c *conn) plan() ExpectationPlan {
+	p := ExpectationPlan{Ordered: !c.unordered}
+	ids := make(map[expectation]int)
+	var groups []*expectedGroup
+	for i, e := range c.expectations {
+		ids[e] = i + 1
+		for g := e.common().group; g != nil; g = g.group {
+			if ids[g] == 0 {
+				groups = append(groups, g)
+				ids[g] = -len(groups)
+			}
+		}
+	}
+	for _, g := range groups {
+		ids[g] = len(c.expectations) - ids[g]
+	}
+
+	step := func(e expectation) PlanStep {
+		common := e.common()
+		s := PlanStep{
+			ID:          ids[e],
+			Expectation: e.String(),
+			Declared:    common.declared,
+			Matched:     common.matched,
+			Satisfied:   e.fulfilled(),
+		}
+		if common.times > 1 {
+			s.Times = common.times
+		}
+		if common.group != nil {
+			s.Group = ids[common.group]
+		}
+		for _, other := range common.after {
+			s.After = append(s.After, ids[other])
+		}
+		if common.conn != nil {
+			s.Conn = fmt.Sprintf("%s, declared at %s", common.conn, common.conn.declared)
+		}
+		return s
+	}
+	for _, e := range c.expectations {
+		p.Steps = append(p.Steps, step(e))
+	}
+	for _, g := range groups {
+		s := step(g)
+		s.Ordered = g.ordered
+		for _, member := range g.members {
+			s.Members = append(s.Members, ids[member])
+		}
+		p.Steps = append(p.Steps, s)
+	}
+	return p
+}
+
+// String lists the steps of the plan, one per line
+func (p ExpectationPlan) String() string {
+	var buf bytes.Buffer
+	if p.Ordered {
+		buf.WriteString("expectations matched in order of declaration:\n")
+	} else {
+		buf.WriteString("expectations matched in any order:\n")
+	}
+	for _, s := range p.Steps {
+		fmt.Fprintf(&buf, "%4d. %s", s.ID, s.Expectation)
+		if s.Members != nil {
+			fmt.Fprintf(&buf, " %v", s.Members)
+		}
+		if s.Times > 1 {
+			fmt.Fprintf(&buf, ", %d times", s.Times)
+		}
+		if s.After != nil {
+			fmt.Fprintf(&buf, ", after %v", s.After)
+		}
+		if s.Group != 0 {
+			fmt.Fprintf(&buf, ", in group %d", s.Group)
+		}
+		if s.Conn != "" {
+			fmt.Fprintf(&buf, ", on %s", s.Conn)
+		}
+		if s.Declared != "" {
+			fmt.Fprintf(&buf, ", declared at %s", s.Declared)
+		}
+		if s.Satisfied {
+			buf.WriteString(", satisfied")
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
@@ -0,0 +1,63 @@
+package sqlmock
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestPlan(t *testing.T) {
+	db, mock, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	begin := mock.ExpectBegin()
+	mock.InAnyOrder(
+		mock.ExpectExec("UPDATE orders").Times(2).WillReturnRowsAffected(1),
+		mock.ExpectExec("INSERT INTO audit").WillReturnRowsAffected(1),
+	)
+	mock.ExpectCommit().After(begin)
+
+	if _, err = db.Begin(); err != nil {
+		t.Errorf("error '%s' was not expected while beginning a transaction", err)
+	}
+
+	plan := mock.Plan()
+	if len(plan.Steps) != 5 {
+		t.Fatalf("expected 4 expectations and a group to be planned, but got: %+v", plan.Steps)
+	}
+	if s := plan.Steps[1]; s.ID != 2 || s.Times != 2 || s.Group != 5 || s.Satisfied {
+		t.Errorf("expected the update to be expected twice in group 5, but got: %+v", s)
+	}
+	if s := plan.Steps[4]; s.ID != 5 || s.Ordered || len(s.Members) != 2 || s.Members[0] != 2 || s.Members[1] != 3 {
+		t.Errorf("expected group 5 of the update and the insert in any order, but got: %+v", s)
+	}
+
+	lines := strings.Split(plan.String(), "\n")
+	expected := []string{
+		"expectations matched in order of declaration:",
+		"   1. begin transaction, declared at plan_test.go:",
+		"   2. exec 'UPDATE orders', 2 times, in group 5, declared at plan_test.go:",
+		"   4. commit transaction, after [1], declared at plan_test.go:",
+		"   5. group of 2 expectations in any order [2 3]",
+	}
+	for i, line := range []int{0, 1, 2, 4, 5} {
+		if !strings.HasPrefix(lines[line], expected[i]) {
+			t.Errorf("expected line %d of the plan to start with '%s', but got '%s'", line, expected[i], lines[line])
+		}
+	}
+	if !strings.HasSuffix(lines[1], ", satisfied") {
+		t.Errorf("expected the begin to be satisfied, but got '%s'", lines[1])
+	}
+
+	data, err := json.Marshal(plan)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while marshaling the plan", err)
+	}
+	var decoded ExpectationPlan
+	if err = json.Unmarshal(data, &decoded); err != nil || len(decoded.Steps) != 5 || !decoded.Ordered {
+		t.Errorf("expected the plan to be decoded from JSON, but got %+v and error: %v", decoded, err)
+	}
+}
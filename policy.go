@@ -0,0 +1,163 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// statements which write to the database
+var writeKeywords = map[string]bool{
+	"INSERT":   true,
+	"UPDATE":   true,
+	"DELETE":   true,
+	"MERGE":    true,
+	"REPLACE":  true,
+	"UPSERT":   true,
+	"CREATE":   true,
+	"ALTER":    true,
+	"DROP":     true,
+	"TRUNCATE": true,
+	"RENAME":   true,
+	"GRANT":    true,
+	"REVOKE":   true,
+	"COPY":     true,
+	"LOAD":     true,
+}
+
+// checks the statement against policies of the connection,
+// every violation is remembered to be reported on close
+func (c *conn) checkPolicies(query string) error {
+	err := c.syntax.validate(query)
+	switch {
+	case err != nil:
+	case c.readOnly && isWriteStatement(query):
+		err = fmt.Errorf("statement '%s' writes to the database, but read only access is required", query)
+	case matchesAny(c.forbidden, query):
+		err = fmt.Errorf("statement '%s' is forbidden by %s", query, matching(c.forbidden, query))
+	case c.allowed != nil && !matchesAny(c.allowed, query):
+		err = fmt.Errorf("statement '%s' is not allowed, it does not match any of %v", query, c.allowed)
+	case c.schema != nil:
+		err = c.schema.check(query)
+	}
+
+	if err != nil {
+		c.violations = append(c.violations, err)
+	}
+	return err
+}
+
+// checks rows returned for a query against the schema, if one is
+// required, a mismatch is remembered to be reported on close
+func (c *conn) checkRows(query string, rs driver.Rows) error {
+	if c.schema == nil {
+		return nil
+	}
+	err := c.schema.checkRows(query, rs)
+	if err != nil {
+		c.violations = append(c.violations, err)
+	}
+	return err
+}
+
+// fails every driver call made after no more interactions were
+// asserted, the call is remembered to be reported on close
+func (c *conn) checkInteraction(call string) error {
+	if !c.noMoreInteractions {
+		return nil
+	}
+	err := fmt.Errorf("%s was made, but no more interactions were expected", call)
+	c.violations = append(c.violations, err)
+	return err
+}
+
+// combines all violations into a single error
+func violationsError(violations []error) error {
+	if len(violations) == 1 {
+		return violations[0]
+	}
+	msgs := make([]string, len(violations))
+	for i, v := range violations {
+		msgs[i] = v.Error()
+	}
+	return fmt.Errorf("there were %d policy violations:\n%s", len(violations), strings.Join(msgs, "\n"))
+}
+
+// checks whether any of the patterns matches the query
+func matchesAny(patterns []*regexp.Regexp, query string) bool {
+	return matching(patterns, query) != nil
+}
+
+// returns the first pattern which matches the query
+func matching(patterns []*regexp.Regexp, query string) *regexp.Regexp {
+	for _, re := range patterns {
+		if re.MatchString(query) {
+			return re
+		}
+	}
+	return nil
+}
+
+// checks whether the statement modifies data or schema, including
+// data modifying statements in a WITH clause
+func isWriteStatement(query string) bool {
+	words := keywords(query)
+	if len(words) == 0 {
+		return false
+	}
+
+	if words[0] != "WITH" {
+		return writeKeywords[words[0]]
+	}
+
+	for i, w := range words {
+		switch w {
+		case "INSERT", "DELETE", "MERGE":
+			return true
+		case "UPDATE":
+			if !isRowLock(words[:i]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// whether UPDATE after the words is the strength of a row
+// lock, like FOR UPDATE or FOR NO KEY UPDATE of postgres
+func isRowLock(words []string) bool {
+	n := len(words)
+	return n > 0 && words[n-1] == "FOR" ||
+		n > 2 && words[n-3] == "FOR" && words[n-2] == "NO" && words[n-1] == "KEY"
+}
+
+// splits the statement into upper cased words, skipping
+// string literals, quoted identifiers and comments
+func keywords(query string) (words []string) {
+	for i := 0; i < len(query); i++ {
+		switch c := query[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(query, i, c)
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			if end := strings.IndexByte(query[i:], '\n'); end != -1 {
+				i += end
+			} else {
+				i = len(query)
+			}
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			if end := strings.Index(query[i+2:], "*/"); end != -1 {
+				i += end + 3
+			} else {
+				i = len(query)
+			}
+		case isLetter(c):
+			j := i
+			for ; j < len(query) && (isLetter(query[j]) || isDigit(query[j])); j++ {
+			}
+			words = append(words, strings.ToUpper(query[i:j]))
+			i = j - 1
+		}
+	}
+	return
+}
@@ -0,0 +1,151 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestWriteStatementDetection(t *testing.T) {
+	for _, q := range []string{
+		"INSERT INTO users (name) VALUES (?)",
+		"update users SET name = ?",
+		"/* audit */ DELETE FROM users",
+		"CREATE TABLE users (id INT)",
+		"TRUNCATE users",
+		"WITH moved AS (DELETE FROM queue RETURNING *) SELECT * FROM moved",
+		"WITH ids AS (SELECT id FROM users) UPDATE orders SET status = 1",
+	} {
+		if !isWriteStatement(q) {
+			t.Errorf("expected '%s' to be detected as a write statement", q)
+		}
+	}
+
+	for _, q := range []string{
+		"SELECT * FROM users",
+		"SELECT 'DELETE FROM users'",
+		"-- UPDATE users\nSELECT 1",
+		"SELECT * FROM orders FOR UPDATE",
+		"WITH ids AS (SELECT id FROM users) SELECT * FROM orders WHERE user_id IN (SELECT id FROM ids) FOR UPDATE",
+		"WITH ids AS (SELECT id FROM users) SELECT * FROM orders WHERE user_id IN (SELECT id FROM ids) FOR NO KEY UPDATE",
+		"SHOW TABLES",
+	} {
+		if isWriteStatement(q) {
+			t.Errorf("expected '%s' not to be detected as a write statement", q)
+		}
+	}
+}
+
+func TestRequireReadOnly(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	RequireReadOnly()
+	ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+	ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	var id int
+	if err = db.QueryRow("SELECT id FROM users").Scan(&id); err != nil {
+		t.Errorf("error '%s' was not expected while reading users", err)
+	}
+
+	if _, err = db.Exec("UPDATE users SET name = ?", "gedi"); err == nil {
+		t.Error("an error was expected, since writes are not allowed")
+	}
+
+	if err = db.Close(); err == nil {
+		t.Error("an error was expected while closing the database, since a write was attempted")
+	}
+}
+
+func TestForbidQueries(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ForbidQueries("FROM payments", "^DELETE")
+	ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+
+	var id int
+	if err = db.QueryRow("SELECT id FROM users").Scan(&id); err != nil {
+		t.Errorf("error '%s' was not expected while reading users", err)
+	}
+
+	if _, err = db.Query("SELECT * FROM payments"); err == nil {
+		t.Error("an error was expected, since payments table is forbidden")
+	}
+
+	if err = db.Close(); err == nil {
+		t.Error("an error was expected while closing the database, since a forbidden query was run")
+	}
+}
+
+func TestAllowOnlyQueries(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	AllowOnly("FROM users")
+	ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+
+	var id int
+	if err = db.QueryRow("SELECT id FROM users").Scan(&id); err != nil {
+		t.Errorf("error '%s' was not expected while reading users", err)
+	}
+
+	if _, err = db.Exec("UPDATE orders SET status = 1"); err == nil {
+		t.Error("an error was expected, since only users table is allowed")
+	}
+
+	if err = db.Close(); err == nil {
+		t.Error("an error was expected while closing the database, since a disallowed statement was run")
+	}
+
+	// policies are reset on close
+	db, _ = sql.Open("mock", "")
+	ExpectExec("UPDATE orders").WillReturnResult(NewResult(0, 1))
+
+	if _, err = db.Exec("UPDATE orders SET status = 1"); err != nil {
+		t.Errorf("error '%s' was not expected, since policies were reset", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestAssertNoMoreInteractions(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectExec("UPDATE users").WillReturnResult(NewResult(0, 1))
+
+	if _, err = db.Exec("UPDATE users SET name = ?", "gedi"); err != nil {
+		t.Errorf("error '%s' was not expected while updating users", err)
+	}
+
+	AssertNoMoreInteractions()
+
+	if _, err = db.Exec("DELETE FROM sessions WHERE user_id = ?", 5); err == nil {
+		t.Error("an error was expected, since no more interactions were expected")
+	}
+
+	if _, err = db.Begin(); err == nil {
+		t.Error("an error was expected, since no more interactions were expected")
+	}
+
+	err = db.Close()
+	if err == nil {
+		t.Fatal("an error was expected while closing the database, since there were more interactions")
+	}
+
+	if !strings.Contains(err.Error(), "DELETE FROM sessions WHERE user_id = ?' with args [5]") {
+		t.Errorf("expected error to report the unexpected query with its arguments, but got: %s", err)
+	}
+}
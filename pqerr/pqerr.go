@@ -0,0 +1,166 @@
+/*
+Package pqerr provides constructors of common PostgreSQL errors,
+shaped like pq.Error of github.com/lib/pq, to be returned by
+sqlmock expectations:
+
+	sqlmock.ExpectExec("INSERT INTO users").
+		WillReturnError(pqerr.UniqueViolation("users_email_key"))
+
+Error classification code which inspects the SQLSTATE code, its
+class or name can be tested against the mock without a real database.
+*/
+package pqerr
+
+// ErrorCode is a five character SQLSTATE error code, like pq.ErrorCode
+type ErrorCode string
+
+// Class returns the error class, the first two characters of the code
+func (ec ErrorCode) Class() ErrorClass {
+	return ErrorClass(ec[0:2])
+}
+
+// Name returns the condition name of the code, if it is in this catalog
+func (ec ErrorCode) Name() string {
+	return errorCodeNames[ec]
+}
+
+// ErrorClass is the class of an error code, like pq.ErrorClass
+type ErrorClass string
+
+// error codes of the errors in this catalog
+const (
+	UniqueViolationCode      ErrorCode = "23505"
+	ForeignKeyViolationCode  ErrorCode = "23503"
+	NotNullViolationCode     ErrorCode = "23502"
+	CheckViolationCode       ErrorCode = "23514"
+	SerializationFailureCode ErrorCode = "40001"
+	DeadlockDetectedCode     ErrorCode = "40P01"
+	LockNotAvailableCode     ErrorCode = "55P03"
+	QueryCanceledCode        ErrorCode = "57014"
+	TooManyConnectionsCode   ErrorCode = "53300"
+	ReadOnlyTransactionCode  ErrorCode = "25006"
+	UndefinedTableCode       ErrorCode = "42P01"
+	UndefinedColumnCode      ErrorCode = "42703"
+	SyntaxErrorCode          ErrorCode = "42601"
+)
+
+var errorCodeNames = map[ErrorCode]string{
+	UniqueViolationCode:      "unique_violation",
+	ForeignKeyViolationCode:  "foreign_key_violation",
+	NotNullViolationCode:     "not_null_violation",
+	CheckViolationCode:       "check_violation",
+	SerializationFailureCode: "serialization_failure",
+	DeadlockDetectedCode:     "deadlock_detected",
+	LockNotAvailableCode:     "lock_not_available",
+	QueryCanceledCode:        "query_canceled",
+	TooManyConnectionsCode:   "too_many_connections",
+	ReadOnlyTransactionCode:  "read_only_sql_transaction",
+	UndefinedTableCode:       "undefined_table",
+	UndefinedColumnCode:      "undefined_column",
+	SyntaxErrorCode:          "syntax_error",
+}
+
+// Error has the same fields as pq.Error
+type Error struct {
+	Severity         string
+	Code             ErrorCode
+	Message          string
+	Detail           string
+	Hint             string
+	Position         string
+	InternalPosition string
+	InternalQuery    string
+	Where            string
+	Schema           string
+	Table            string
+	Column           string
+	DataTypeName     string
+	Constraint       string
+	File             string
+	Line             string
+	Routine          string
+}
+
+func (err *Error) Error() string {
+	return "pq: " + err.Message
+}
+
+// New creates an error with the given code and message
+func New(code ErrorCode, message string) *Error {
+	return &Error{Severity: "ERROR", Code: code, Message: message}
+}
+
+// UniqueViolation is returned when a unique constraint is violated
+func UniqueViolation(constraint string) *Error {
+	e := New(UniqueViolationCode, `duplicate key value violates unique constraint "`+constraint+`"`)
+	e.Constraint = constraint
+	return e
+}
+
+// ForeignKeyViolation is returned when a foreign key constraint is violated
+func ForeignKeyViolation(table, constraint string) *Error {
+	e := New(ForeignKeyViolationCode, `insert or update on table "`+table+`" violates foreign key constraint "`+constraint+`"`)
+	e.Table, e.Constraint = table, constraint
+	return e
+}
+
+// NotNullViolation is returned when NULL is stored in a NOT NULL column
+func NotNullViolation(table, column string) *Error {
+	e := New(NotNullViolationCode, `null value in column "`+column+`" of relation "`+table+`" violates not-null constraint`)
+	e.Table, e.Column = table, column
+	return e
+}
+
+// CheckViolation is returned when a check constraint is violated
+func CheckViolation(table, constraint string) *Error {
+	e := New(CheckViolationCode, `new row for relation "`+table+`" violates check constraint "`+constraint+`"`)
+	e.Table, e.Constraint = table, constraint
+	return e
+}
+
+// SerializationFailure is returned when a serializable transaction must be retried
+func SerializationFailure() *Error {
+	return New(SerializationFailureCode, "could not serialize access due to concurrent update")
+}
+
+// Deadlock is returned when the transaction was chosen as a deadlock victim
+func Deadlock() *Error {
+	return New(DeadlockDetectedCode, "deadlock detected")
+}
+
+// LockWaitTimeout is returned when a lock could not be acquired in time or with NOWAIT
+func LockWaitTimeout() *Error {
+	return New(LockNotAvailableCode, "canceling statement due to lock timeout")
+}
+
+// QueryCanceled is returned when a statement timed out or was canceled
+func QueryCanceled() *Error {
+	return New(QueryCanceledCode, "canceling statement due to statement timeout")
+}
+
+// TooManyConnections is returned when the server connection limit is reached
+func TooManyConnections() *Error {
+	e := New(TooManyConnectionsCode, "sorry, too many clients already")
+	e.Severity = "FATAL"
+	return e
+}
+
+// ReadOnlyTransaction is returned when writing in a read only transaction
+func ReadOnlyTransaction(statement string) *Error {
+	return New(ReadOnlyTransactionCode, "cannot execute "+statement+" in a read-only transaction")
+}
+
+// UndefinedTable is returned when the relation does not exist
+func UndefinedTable(table string) *Error {
+	return New(UndefinedTableCode, `relation "`+table+`" does not exist`)
+}
+
+// UndefinedColumn is returned when the column does not exist
+func UndefinedColumn(column string) *Error {
+	return New(UndefinedColumnCode, `column "`+column+`" does not exist`)
+}
+
+// SyntaxError is returned when the statement can not be parsed
+func SyntaxError(near string) *Error {
+	return New(SyntaxErrorCode, `syntax error at or near "`+near+`"`)
+}
@@ -0,0 +1,37 @@
+package pqerr
+
+import (
+	"testing"
+)
+
+func TestErrorShape(t *testing.T) {
+	err := UniqueViolation("users_email_key")
+	if err.Code != "23505" {
+		t.Errorf("expected error code to be 23505, but got %s", err.Code)
+	}
+
+	if err.Code.Name() != "unique_violation" {
+		t.Errorf("expected error code name to be unique_violation, but got %s", err.Code.Name())
+	}
+
+	if err.Code.Class() != "23" {
+		t.Errorf("expected error class to be 23, but got %s", err.Code.Class())
+	}
+
+	expected := `pq: duplicate key value violates unique constraint "users_email_key"`
+	if err.Error() != expected {
+		t.Errorf("expected error message to be '%s', but got '%s'", expected, err.Error())
+	}
+
+	if err.Constraint != "users_email_key" {
+		t.Errorf("expected constraint to be set, but got '%s'", err.Constraint)
+	}
+}
+
+func TestRetryableErrors(t *testing.T) {
+	for _, err := range []*Error{Deadlock(), SerializationFailure()} {
+		if err.Code.Class() != "40" {
+			t.Errorf("expected a transaction rollback class error, but got %s", err.Code)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package sqlmock
+
+import (
+	"regexp"
+	"sort"
+)
+
+// MySQLVersionPreset expects the "SELECT VERSION()" query, which
+// GORM and some migration tools issue on open to detect the server,
+// it is answered with the given version
+func MySQLVersionPreset(version string) *ExpectationSet {
+	s := NewExpectationSet()
+	s.ExpectQuery(`^SELECT VERSION\(\)$`).
+		WillReturnRows(NewRows([]string{"VERSION()"}).AddRow(version))
+	return s
+}
+
+// MySQLTimeZonePreset expects the "SET time_zone" statement issued
+// when a session time zone is configured for the connection
+func MySQLTimeZonePreset(tz string) *ExpectationSet {
+	s := NewExpectationSet()
+	s.ExpectExec(`^SET (@@session\.)?time_zone\s*=\s*'?` + regexp.QuoteMeta(tz) + `'?$`).
+		WillReturnResult(NewResult(0, 0))
+	return s
+}
+
+// MySQLVariablesPreset expects a "SHOW VARIABLES" query, it is
+// answered with the given variables ordered by name
+func MySQLVariablesPreset(vars map[string]string) *ExpectationSet {
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rows := NewRows([]string{"Variable_name", "Value"})
+	for _, name := range names {
+		rows.AddRow(name, vars[name])
+	}
+
+	s := NewExpectationSet()
+	s.ExpectQuery(`^SHOW (SESSION |GLOBAL )?VARIABLES`).WillReturnRows(rows)
+	return s
+}
+
+// PostgresVersionPreset expects the "SELECT version()" query and
+// answers it with the given server version string
+func PostgresVersionPreset(version string) *ExpectationSet {
+	s := NewExpectationSet()
+	s.ExpectQuery(`^SELECT version\(\)$`).
+		WillReturnRows(NewRows([]string{"version"}).AddRow(version))
+	return s
+}
+
+// GormMySQLPreset expects the queries GORM issues when it opens
+// a MySQL database with the default configuration, which is the
+// version query, unless SkipInitializeWithVersion is configured.
+// Session variables set through the data source name are expected
+// by MySQLTimeZonePreset or MySQLVariablesPreset on top of it
+func GormMySQLPreset(version string) *ExpectationSet {
+	return NewExpectationSet(MySQLVersionPreset(version))
+}
@@ -0,0 +1,52 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestBootstrapPresets(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	Apply(
+		GormMySQLPreset("8.0.34"),
+		MySQLTimeZonePreset("+00:00"),
+		MySQLVariablesPreset(map[string]string{"sql_mode": "STRICT_ALL_TABLES", "autocommit": "ON"}),
+	)
+
+	var version string
+	if err = db.QueryRow("SELECT VERSION()").Scan(&version); err != nil {
+		t.Errorf("error '%s' was not expected while selecting the version", err)
+	}
+	if version != "8.0.34" {
+		t.Errorf("expected version to be '8.0.34', but got '%s'", version)
+	}
+
+	if _, err = db.Exec("SET time_zone = '+00:00'"); err != nil {
+		t.Errorf("error '%s' was not expected while setting the time zone", err)
+	}
+
+	rs, err := db.Query("SHOW VARIABLES WHERE Variable_name IN ('autocommit', 'sql_mode')")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while showing variables", err)
+	}
+	var names []string
+	for rs.Next() {
+		var name, value string
+		if err = rs.Scan(&name, &value); err != nil {
+			t.Errorf("error '%s' was not expected while scanning a variable", err)
+		}
+		names = append(names, name)
+	}
+	rs.Close()
+	if len(names) != 2 || names[0] != "autocommit" || names[1] != "sql_mode" {
+		t.Errorf("expected variables to be ordered by name, but got %v", names)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
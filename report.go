@@ -0,0 +1,192 @@
+package sqlmock
+
+import (
+	"bytes"
+	"fmt"
+	"text/tabwriter"
+)
+
+// number of the most recent unexpected calls listed by AssertExpectations
+const recentUnexpectedCalls = 10
+
+// ExpectationReport describes how a declared
+// expectation was matched by driver calls
+type ExpectationReport struct {
+	Expectation string   // the expectation declared
+	Matches     int      // number of calls matched against it
+	Calls       []string // the calls matched against it, in order
+	Queries     []string // of the exec and query calls it accepted, or failed as mocked, in order
+	Canceled    int      // calls which returned early, since their context was done
+	Satisfied   bool     // whether the expectation was fulfilled
+}
+
+// Report describes every expectation declared since the connection
+// was last closed or reset, in declaration order. Allows to debug a
+// failing test or to assert how many times expectations were matched
+func Report() []ExpectationReport {
+	return mock.Report()
+}
+
+// Report describes every expectation declared on the mock
+func (m *MockDB) Report() []ExpectationReport {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+
+	reports := make([]ExpectationReport, len(m.conn.expectations))
+	for i, e := range m.conn.expectations {
+		calls := e.common().calls
+		reports[i] = ExpectationReport{
+			Expectation: e.String(),
+			Matches:     len(calls),
+			Calls:       append([]string(nil), calls...),
+			Canceled:    e.common().canceled,
+			Satisfied:   e.fulfilled(),
+		}
+		if qe := queryBased(e); qe != nil {
+			reports[i].Queries = append([]string(nil), qe.queries...)
+		}
+	}
+	return reports
+}
+
+// PendingExpectations describes every expectation, which was
+// not matched yet, with the location it was declared at
+func PendingExpectations() []string {
+	return mock.PendingExpectations()
+}
+
+// PendingExpectations describes every expectation
+// of the mock, which was not matched yet
+func (m *MockDB) PendingExpectations() []string {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+
+	var pending []string
+	for _, e := range m.conn.expectations {
+		if !e.fulfilled() {
+			pending = append(pending, fmt.Sprintf("%s, declared at %s", e, e.common().declared))
+		}
+	}
+	return pending
+}
+
+// TestingT is the part of *testing.T, which AssertExpectations
+// reports a failure to
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+}
+
+// AssertExpectations verifies the expectations like ExpectationsWereMet
+// and, if they were not met, fails the test with a listing of fulfilled
+// expectations side by side with pending ones, followed by the most
+// recent calls none of the expectations matched. Returns whether the
+// expectations were met
+func AssertExpectations(t TestingT) bool {
+	if h, ok := t.(interface {
+		Helper()
+	}); ok {
+		h.Helper()
+	}
+	return mock.AssertExpectations(t)
+}
+
+// AssertExpectations fails the test, unless the expectations were met
+func (m *MockDB) AssertExpectations(t TestingT) bool {
+	if h, ok := t.(interface {
+		Helper()
+	}); ok {
+		h.Helper()
+	}
+	m.conn.mu.Lock()
+	err := m.conn.verify()
+	var listing string
+	if err != nil {
+		listing = m.conn.listing()
+	}
+	m.conn.mu.Unlock()
+
+	if err != nil {
+		t.Errorf("sqlmock: %s\n\n%s", err, listing)
+	}
+	return err == nil
+}
+
+// FailingT is the part of *testing.T, which MustExpectationsMet
+// stops the test with
+type FailingT interface {
+	TestingT
+	FailNow()
+}
+
+// MustExpectationsMet is AssertExpectations, which stops the test
+// with FailNow if the expectations were not met. So that the test
+// does not continue after the flow of database calls was violated,
+// which would only cause misleading failures of its later checks
+func MustExpectationsMet(t FailingT) {
+	if h, ok := t.(interface {
+		Helper()
+	}); ok {
+		h.Helper()
+	}
+	mock.MustExpectationsMet(t)
+}
+
+// MustExpectationsMet stops the test, unless the expectations were met
+func (m *MockDB) MustExpectationsMet(t FailingT) {
+	if h, ok := t.(interface {
+		Helper()
+	}); ok {
+		h.Helper()
+	}
+	if !m.AssertExpectations(t) {
+		t.FailNow()
+	}
+}
+
+// lists fulfilled and pending expectations in two columns,
+// followed by the most recent unexpected calls
+func (c *conn) listing() string {
+	var fulfilled, pending []string
+	for _, e := range c.expectations {
+		common := e.common()
+		if e.fulfilled() {
+			fulfilled = append(fulfilled, fmt.Sprintf("%s, matched %s", e, times(len(common.calls))))
+			continue
+		}
+		p := fmt.Sprintf("%s, declared at %s", e, common.declared)
+		if common.matched > 0 {
+			p += fmt.Sprintf(", matched only %s", times(common.matched))
+		}
+		pending = append(pending, p)
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 4, 3, ' ', 0)
+	fmt.Fprintln(w, "  FULFILLED\tPENDING")
+	for i := 0; i < len(fulfilled) || i < len(pending); i++ {
+		var left, right string
+		if i < len(fulfilled) {
+			left = fulfilled[i]
+		}
+		if i < len(pending) {
+			right = pending[i]
+		}
+		fmt.Fprintf(w, "  %s\t%s\n", left, right)
+	}
+	w.Flush()
+
+	if len(c.unexpectedCalls) > 0 {
+		fmt.Fprintf(&buf, "\n  UNEXPECTED CALLS, the most recent last\n")
+		for _, call := range c.unexpectedCalls {
+			fmt.Fprintf(&buf, "  %s\n", call)
+		}
+	}
+	return buf.String()
+}
+
+func times(n int) string {
+	if n == 1 {
+		return "once"
+	}
+	return fmt.Sprintf("%d times", n)
+}
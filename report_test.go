@@ -0,0 +1,155 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestReport(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectBegin()
+	ExpectExec("UPDATE orders").WithArgs(5).WillReturnResult(NewResult(0, 1))
+	ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if _, err = tx.Exec("UPDATE orders SET status = 1 WHERE id = ?", 5); err != nil {
+		t.Errorf("error '%s' was not expected while updating the order", err)
+	}
+
+	report := Report()
+	if len(report) != 3 {
+		t.Fatalf("expected a report of 3 expectations, but got %d", len(report))
+	}
+
+	if !report[1].Satisfied || report[1].Matches != 1 {
+		t.Errorf("expected update to be satisfied by a single call, but got %+v", report[1])
+	}
+	if call := "call to exec query 'UPDATE orders SET status = 1 WHERE id = ?' with args [5]"; report[1].Calls[0] != call {
+		t.Errorf("expected update to be matched by %s, but got %s", call, report[1].Calls[0])
+	}
+	if q := report[1].Queries; len(q) != 1 || q[0] != "UPDATE orders SET status = 1 WHERE id = ?" {
+		t.Errorf("expected update to be matched by the query as it was executed, but got %q", q)
+	}
+	if report[2].Satisfied || report[2].Matches != 0 {
+		t.Errorf("expected commit not to be satisfied yet, but got %+v", report[2])
+	}
+
+	if err = tx.Commit(); err != nil {
+		t.Errorf("an error '%s' was not expected when commiting a transaction", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestPendingExpectations(t *testing.T) {
+	ExpectBegin()
+	ExpectExec("UPDATE orders").WithArgs(5)
+	ExpectCall("order_totals")
+	defer Reset()
+
+	pending := PendingExpectations()
+	if len(pending) != 3 {
+		t.Fatalf("expected 3 pending expectations, but got %d", len(pending))
+	}
+
+	expected := []string{
+		"begin transaction, declared at report_test.go:",
+		"exec 'UPDATE orders' with args [5], declared at report_test.go:",
+		"call to procedure 'order_totals', declared at report_test.go:",
+	}
+	for i, s := range expected {
+		if !strings.HasPrefix(pending[i], s) {
+			t.Errorf("expected pending expectation to be described as '%s...', but got '%s'", s, pending[i])
+		}
+	}
+}
+
+// records failures reported by AssertExpectations
+type recordingT struct {
+	failures []string
+	stopped  bool
+}
+
+func (t *recordingT) Errorf(format string, args ...interface{}) {
+	t.failures = append(t.failures, fmt.Sprintf(format, args...))
+}
+
+func (t *recordingT) FailNow() {
+	t.stopped = true
+}
+
+func TestAssertExpectations(t *testing.T) {
+	db, mock, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.MatchExpectationsInOrder(false)
+	mock.ExpectExec("UPDATE orders").WillReturnResult(NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO audit").WillReturnResult(NewResult(1, 1))
+
+	if _, err = db.Exec("UPDATE orders SET status = 1"); err != nil {
+		t.Errorf("error '%s' was not expected while updating orders", err)
+	}
+	if _, err = db.Exec("DELETE FROM orders"); err == nil {
+		t.Errorf("expected an error, since the delete was not expected")
+	}
+
+	rt := &recordingT{}
+	if mock.AssertExpectations(rt) || len(rt.failures) != 1 {
+		t.Fatalf("expected a single failure, since the insert was not made, but got: %v", rt.failures)
+	}
+	expected := []string{
+		"sqlmock: there is a remaining expectation exec 'INSERT INTO audit', declared at report_test.go:",
+		"  FULFILLED                            PENDING\n",
+		"  exec 'UPDATE orders', matched once   exec 'INSERT INTO audit', declared at report_test.go:",
+		"  UNEXPECTED CALLS, the most recent last\n  call to exec 'DELETE FROM orders' query with args []\n",
+	}
+	for _, part := range expected {
+		if !strings.Contains(rt.failures[0], part) {
+			t.Errorf("expected the failure to contain:\n%s\nbut got:\n%s", part, rt.failures[0])
+		}
+	}
+
+	if _, err = db.Exec("INSERT INTO audit (action) VALUES (1)"); err != nil {
+		t.Errorf("error '%s' was not expected while auditing", err)
+	}
+	rt = &recordingT{}
+	if !mock.AssertExpectations(rt) || len(rt.failures) != 0 {
+		t.Errorf("expected no failures, since all expectations were met, but got: %v", rt.failures)
+	}
+}
+
+func TestMustExpectationsMet(t *testing.T) {
+	db, mock, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("UPDATE orders").WillReturnResult(NewResult(0, 1))
+
+	rt := &recordingT{}
+	if mock.MustExpectationsMet(rt); !rt.stopped || len(rt.failures) != 1 {
+		t.Errorf("expected the test to be stopped with a failure, since the update was not made, but got: %v", rt.failures)
+	}
+
+	if _, err = db.Exec("UPDATE orders SET status = 1"); err != nil {
+		t.Errorf("error '%s' was not expected while updating orders", err)
+	}
+	rt = &recordingT{}
+	if mock.MustExpectationsMet(rt); rt.stopped || len(rt.failures) != 0 {
+		t.Errorf("expected the test to continue, since all expectations were met, but got: %v", rt.failures)
+	}
+}
@@ -1,6 +1,7 @@
 package sqlmock
 
 import (
+	"database/sql"
 	"fmt"
 	"testing"
 )
@@ -34,3 +35,27 @@ func TestShouldReturnErroeSqlDriverResult(t *testing.T) {
 		t.Error("expected error, but got none")
 	}
 }
+
+func TestWillReturnRowsAffected(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectExec("UPDATE orders").WillReturnRowsAffected(3)
+
+	res, err := db.Exec("UPDATE orders SET status = 1")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while updating orders", err)
+	}
+	if n, _ := res.RowsAffected(); n != 3 {
+		t.Errorf("expected 3 affected rows, but got %d", n)
+	}
+	if id, _ := res.LastInsertId(); id != 0 {
+		t.Errorf("expected no insert id, but got %d", id)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
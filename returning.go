@@ -0,0 +1,124 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ExpectInsertReturning expects an INSERT INTO the table with a RETURNING
+// clause, which postgres and sqlite drivers run as a query, since it returns
+// rows. Unless rows are set with WillReturnRows, a row is returned for every
+// inserted row with the columns listed by RETURNING. Columns inserted with
+// placeholders return their args, columns named id, or ending with _id,
+// return ids generated by the expectation starting at 1, columns ending with
+// _at the current time, other columns return NULL
+func ExpectInsertReturning(table string) Mock {
+	return mock.ExpectInsertReturning(table)
+}
+
+// ExpectInsertReturning expects an INSERT INTO the table with a RETURNING clause
+func (m *MockDB) ExpectInsertReturning(table string) Mock {
+	return m.conn.expect(insertReturningExpectation(table))
+}
+
+// ExpectInsertReturning expects an INSERT INTO the table with a RETURNING clause
+func (s *ExpectationSet) ExpectInsertReturning(table string) Mock {
+	return s.recorder.expect(insertReturningExpectation(table))
+}
+
+func insertReturningExpectation(table string) *expectedQuery {
+	parts := strings.Split(table, ".")
+	for i, p := range parts {
+		parts[i] = "[\"`\\[]?" + regexp.QuoteMeta(p) + "[\"`\\]]?"
+	}
+	e := &expectedQuery{returning: table}
+	e.sqlRegex = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+` + strings.Join(parts, `\.`) + `[\s(].*\bRETURNING\b`)
+	e.unanchored = true // matches up to RETURNING only
+	return e
+}
+
+// the rows returned by an INSERT ... RETURNING, as ExpectInsertReturning
+// describes, lastID is the id generated last, it is advanced for every row
+func returnedRows(query string, args []driver.Value, lastID *int64) driver.Rows {
+	ts := statements(tokenize(query))
+	if len(ts) == 0 {
+		return NewRows(nil)
+	}
+	stmt := ts[0]
+
+	var inserted []string
+	_, i := qualifiedName(stmt, find(stmt, "INTO")+1)
+	if i < len(stmt) && stmt[i].isPunct("(") {
+		for _, item := range items(stmt, i) {
+			name, _ := qualifiedName(item, 0)
+			inserted = append(inserted, name)
+		}
+		i = closing(stmt, i) + 1
+	}
+
+	var columns []string
+	if r := find(stmt, "RETURNING"); r != -1 {
+		for _, item := range split(stmt[r+1:]) {
+			switch {
+			case len(item) == 1 && item[0].isPunct("*"):
+				columns = append(columns, "id")
+				for _, name := range inserted {
+					if name != "id" {
+						columns = append(columns, name)
+					}
+				}
+			case len(item) > 2 && item[len(item)-2].is("AS"):
+				columns = append(columns, strings.ToLower(item[len(item)-1].text))
+			default:
+				name, _ := qualifiedName(item, 0)
+				columns = append(columns, name)
+			}
+		}
+	}
+
+	// unnamed expressions of RETURNING may repeat or be empty,
+	// so the columns are not validated the way NewRows does
+	rs := &rows{cols: columns}
+	placeholder := 0
+	for ; i < len(stmt) && (stmt[i].is("VALUES") || stmt[i].isPunct(",")); i = closing(stmt, i) + 1 {
+		if i++; i >= len(stmt) || !stmt[i].isPunct("(") {
+			break
+		}
+		values := make(map[string]driver.Value)
+		for j, item := range items(stmt, i) {
+			for _, t := range item {
+				if t.kind != '?' {
+					continue
+				}
+				n := placeholder
+				if t.text[0] == '$' {
+					n, _ = strconv.Atoi(t.text[1:])
+					n--
+				}
+				placeholder++
+				if len(item) == 1 && j < len(inserted) && n >= 0 && n < len(args) {
+					values[inserted[j]] = args[n]
+				}
+			}
+		}
+
+		*lastID++
+		row := make([]driver.Value, len(columns))
+		for j, column := range columns {
+			v, ok := values[column]
+			switch {
+			case ok:
+			case column == "id" || strings.HasSuffix(column, "_id"):
+				v = *lastID
+			case strings.HasSuffix(column, "_at"):
+				v = time.Now()
+			}
+			row[j] = v
+		}
+		rs.AddRow(row...)
+	}
+	return rs
+}
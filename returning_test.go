@@ -0,0 +1,80 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpectInsertReturning(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectInsertReturning("users").WithArgs("gedi", 30)
+	ExpectInsertReturning("users")
+	ExpectInsertReturning("orders").WillReturnRow([]string{"id"}, 42)
+
+	_, err = db.Exec("INSERT INTO users (name, age) VALUES (?, ?) RETURNING id", "gedi", 30)
+	if err == nil || !strings.Contains(err.Error(), "it must be made with Query or QueryRow") {
+		t.Errorf("expected an error, since the insert returning rows was executed, but got: %v", err)
+	}
+
+	var (
+		id      int64
+		name    string
+		created time.Time
+	)
+	err = db.QueryRow(`INSERT INTO "users" (name, age) VALUES ($1, $2) RETURNING id, name, created_at`, "gedi", 30).Scan(&id, &name, &created)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while inserting a user", err)
+	}
+	if id != 1 || name != "gedi" || created.IsZero() {
+		t.Errorf("expected the generated id 1, the inserted name and the creation time, but got %d, %s, %s", id, name, created)
+	}
+
+	rs, err := db.Query("INSERT INTO users (name, age) VALUES (?, lower(?)), (?, ?) RETURNING id AS user_id, age", "a", "b", "c", 5)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while inserting users", err)
+	}
+	var ids, ages []int64
+	for rs.Next() {
+		var age sql.NullInt64
+		if err = rs.Scan(&id, &age); err != nil {
+			t.Errorf("error '%s' was not expected while scanning", err)
+		}
+		ids, ages = append(ids, id), append(ages, age.Int64)
+	}
+	rs.Close()
+	if len(ids) != 2 || ids[0] != 1 || ids[1] != 2 || ages[0] != 0 || ages[1] != 5 {
+		t.Errorf("expected a row for every inserted row with the generated ids and inserted ages, but got %v and %v", ids, ages)
+	}
+
+	if err = db.QueryRow("INSERT INTO orders (user_id) VALUES (?) RETURNING id", 1).Scan(&id); err != nil || id != 42 {
+		t.Errorf("expected the rows set for the expectation, but got %d and error: %v", id, err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestExpectInsertReturningUnnamedExpressions(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	m.ExpectInsertReturning("users")
+
+	var id int64
+	var one, two interface{}
+	err = db.QueryRow("INSERT INTO users (name) VALUES (?) RETURNING id, 1, 2", "gedi").Scan(&id, &one, &two)
+	if err != nil || id != 1 {
+		t.Errorf("expected the generated id and the unnamed expressions, but got %d and error: %v", id, err)
+	}
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
@@ -3,8 +3,10 @@ package sqlmock
 import (
 	"database/sql/driver"
 	"encoding/csv"
+	"fmt"
 	"io"
 	"strings"
+	"sync"
 )
 
 // Rows interface allows to construct rows
@@ -15,8 +17,38 @@ type Rows interface {
 	FromCSVString(s string) Rows
 }
 
+// rows which can be cloned with a fresh cursor, so that
+// the same fixture can be returned by many expectations
+type cloner interface {
+	clone() driver.Rows
+}
+
+// returns rows with a fresh cursor if they support it
+func cloneRows(rs driver.Rows) driver.Rows {
+	if c, ok := rs.(cloner); ok {
+		return c.clone()
+	}
+	return rs
+}
+
+// the converter of row values, which is the one of the mock
+// returning the rows, if it is set, or the default converter
+type rowConverter struct {
+	converter driver.ValueConverter
+}
+
+// rows whose values may be converted by another converter
+type converting interface {
+	convertBy(converter driver.ValueConverter)
+}
+
+func (r *rowConverter) convertBy(converter driver.ValueConverter) {
+	r.converter = converter
+}
+
 // a struct which implements database/sql/driver.Rows
 type rows struct {
+	rowConverter
 	cols []string
 	rows [][]driver.Value
 	pos  int
@@ -34,6 +66,10 @@ func (r *rows) Err() error {
 	return nil
 }
 
+func (r *rows) clone() driver.Rows {
+	return &rows{cols: r.cols, rows: r.rows}
+}
+
 // advances to next row
 func (r *rows) Next(dest []driver.Value) error {
 	r.pos++
@@ -41,26 +77,113 @@ func (r *rows) Next(dest []driver.Value) error {
 		return io.EOF // per interface spec
 	}
 
-	for i, col := range r.rows[r.pos-1] {
-		dest[i] = col
-	}
+	return convertRow(r.converter, r.pos, r.cols, r.rows[r.pos-1], dest)
+}
 
+// a row value which fails to be scanned
+type scanError struct {
+	err error
+}
+
+func (e *scanError) Error() string {
+	return e.err.Error()
+}
+
+// ScanError creates a row value which makes scanning of that
+// column fail, since it cannot be converted into a value type.
+// The other columns of the row are scanned as usual. A custom
+// sql.Scanner or an interface{} receives the value, which is the
+// error, so that they may handle it. Allows to simulate partially
+// corrupt data, for example:
+//
+//	rs := NewRows([]string{"id", "title"}).AddRow(1, ScanError(err))
+func ScanError(err error) driver.Value {
+	return &scanError{err}
+}
+
+// a row value which is returned as raw driver bytes
+type rawBytes struct {
+	b      []byte
+	dbType string
+}
+
+// RawBytes creates a row value which is returned to database/sql as is,
+// the way drivers like MySQL return every column of a text protocol
+// result, so that it may be scanned into sql.RawBytes or parsed by the
+// caller. The database type, like "DECIMAL" or "JSON", is reported as
+// the database type name of the column, for example:
+//
+//	rs := NewRows([]string{"id", "doc"}).AddRow(1, RawBytes(doc, "JSON"))
+func RawBytes(b []byte, dbType string) driver.Value {
+	return &rawBytes{b, dbType}
+}
+
+// converts row values into dest, using the given converter or the
+// default parameter converter, if it is nil, for every column. A value of type func() driver.Value
+// is generated as the row is read, for example:
+//
+//	rs := NewRows([]string{"id", "read_at"}).AddRow(1, func() driver.Value { return time.Now() })
+func convertRow(converter driver.ValueConverter, pos int, cols []string, row []driver.Value, dest []driver.Value) error {
+	for i, col := range row {
+		if gen, ok := col.(func() driver.Value); ok {
+			col = gen()
+		}
+		if se, ok := col.(*scanError); ok {
+			dest[i] = se // passed as is to fail on scan of the column
+			continue
+		}
+		if rb, ok := col.(*rawBytes); ok {
+			dest[i] = rb.b
+			continue
+		}
+		if s, ok := decimalString(col); ok {
+			col = s // big numbers are sent as decimal strings
+		}
+		if converter == nil {
+			converter = driver.DefaultParameterConverter
+		}
+		v, err := converter.ConvertValue(col)
+		if err != nil {
+			return fmt.Errorf("row %d column '%s' value %+v could not be converted to a driver value: %s", pos, cols[i], col, err)
+		}
+		dest[i] = v
+	}
 	return nil
 }
 
 // NewRows allows Rows to be created from a group of
 // sql driver.Value or from the CSV string and
-// to be used as sql driver.Rows
+// to be used as sql driver.Rows. Panics if a column
+// name is empty or declared more than once
 func NewRows(columns []string) Rows {
+	validateColumns(columns)
 	return &rows{cols: columns}
 }
 
+// panics on an empty or duplicate column name, which would
+// only confuse the code mapping columns to struct fields later
+func validateColumns(columns []string) {
+	seen := make(map[string]int, len(columns))
+	for i, col := range columns {
+		if col == "" {
+			panic(fmt.Sprintf("column %d in %q has an empty name", i+1, columns))
+		}
+		if j, ok := seen[col]; ok {
+			panic(fmt.Sprintf("column %d '%s' in %q duplicates column %d", i+1, col, columns, j+1))
+		}
+		seen[col] = i
+	}
+}
+
 // AddRow adds a row which is built from arguments
 // in the same column order, returns sql driver.Rows
-// compatible interface
+// compatible interface. Values are converted with
+// driver.DefaultParameterConverter when the row is read,
+// so types like int32, custom string types or driver.Valuer
+// implementations may be used directly
 func (r *rows) AddRow(values ...driver.Value) Rows {
 	if len(values) != len(r.cols) {
-		panic("Expected number of values to match number of columns")
+		panic(r.columnMismatch(len(values), values))
 	}
 
 	row := make([]driver.Value, len(r.cols))
@@ -75,8 +198,7 @@ func (r *rows) AddRow(values ...driver.Value) Rows {
 // FromCSVString adds rows from CSV string.
 // Returns sql driver.Rows compatible interface
 func (r *rows) FromCSVString(s string) Rows {
-	res := strings.NewReader(strings.TrimSpace(s))
-	csvReader := csv.NewReader(res)
+	csvReader := newCSVReader(strings.NewReader(strings.TrimSpace(s)))
 
 	for {
 		res, err := csvReader.Read()
@@ -84,37 +206,273 @@ func (r *rows) FromCSVString(s string) Rows {
 			break
 		}
 
-		row := make([]driver.Value, len(r.cols))
-		for i, v := range res {
-			row[i] = []byte(strings.TrimSpace(v))
+		if len(res) != len(r.cols) {
+			panic(r.columnMismatch(len(res), res))
 		}
-		r.rows = append(r.rows, row)
+		r.rows = append(r.rows, csvRow(res))
 	}
 	return r
 }
 
+// a CSV reader, which leaves the number of fields to be validated
+// against columns, so that the error may name them
+func newCSVReader(r io.Reader) *csv.Reader {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = -1
+	return csvReader
+}
+
+// row values of a CSV record, which are
+// returned as bytes like drivers do
+func csvRow(record []string) []driver.Value {
+	row := make([]driver.Value, len(record))
+	for i, v := range record {
+		row[i] = []byte(strings.TrimSpace(v))
+	}
+	return row
+}
+
+// the database type of the column, declared
+// by the first raw bytes value in that column
+func (r *rows) dbType(index int) string {
+	for _, row := range r.rows {
+		if rb, ok := row[index].(*rawBytes); ok {
+			return rb.dbType
+		}
+	}
+	return ""
+}
+
+// builds a panic message for a row which does not
+// match the number of declared columns
+func (r *rows) columnMismatch(n int, values interface{}) string {
+	return fmt.Sprintf("row %d has %d values %+v, but %d columns %v were declared", len(r.rows)+1, n, values, len(r.cols), r.cols)
+}
+
+// a struct which implements database/sql/driver.Rows
+// by generating every row on demand
+type generatedRows struct {
+	rowConverter
+	cols []string
+	n    int
+	gen  func(i int) []driver.Value
+	pos  int
+}
+
+// NewRowsGenerator creates sql driver.Rows which produce n rows
+// on demand, by calling gen with a zero based row index each time
+// the next row is read. Rows are never materialized, so it is
+// suitable to mock very large result sets. Columns are
+// validated like NewRows does
+func NewRowsGenerator(columns []string, n int, gen func(i int) []driver.Value) driver.Rows {
+	validateColumns(columns)
+	return &generatedRows{cols: columns, n: n, gen: gen}
+}
+
+func (r *generatedRows) Columns() []string {
+	return r.cols
+}
+
+func (r *generatedRows) Close() error {
+	return nil
+}
+
+func (r *generatedRows) clone() driver.Rows {
+	return &generatedRows{cols: r.cols, n: r.n, gen: r.gen}
+}
+
+// generates the next row
+func (r *generatedRows) Next(dest []driver.Value) error {
+	if r.pos >= r.n {
+		return io.EOF // per interface spec
+	}
+	r.pos++
+
+	row := r.gen(r.pos - 1)
+	if len(row) != len(r.cols) {
+		return fmt.Errorf("generated row %d has %d values %+v, but %d columns %v were declared", r.pos, len(row), row, len(r.cols), r.cols)
+	}
+	return convertRow(r.converter, r.pos, r.cols, row, dest)
+}
+
+// a struct which implements database/sql/driver.Rows
+// by parsing every row from CSV on demand
+type csvRows struct {
+	rowConverter
+	cols []string
+	r    *csv.Reader
+	pos  int
+}
+
+// NewRowsFromCSV creates sql driver.Rows, which parse rows from the
+// CSV reader only when the next row is read, so that large fixtures,
+// like files, are never loaded into memory at once. Since the reader
+// is consumed, the rows may be returned by a single query only
+func NewRowsFromCSV(columns []string, r io.Reader) driver.Rows {
+	validateColumns(columns)
+	return &csvRows{cols: columns, r: newCSVReader(r)}
+}
+
+func (r *csvRows) Columns() []string {
+	return r.cols
+}
+
+func (r *csvRows) Close() error {
+	return nil
+}
+
+// parses the next row
+func (r *csvRows) Next(dest []driver.Value) error {
+	record, err := r.r.Read()
+	if err != nil {
+		return err // io.EOF per interface spec
+	}
+	r.pos++
+
+	if len(record) != len(r.cols) {
+		return fmt.Errorf("csv row %d has %d values %+v, but %d columns %v were declared", r.pos, len(record), record, len(r.cols), r.cols)
+	}
+	return convertRow(r.converter, r.pos, r.cols, csvRow(record), dest)
+}
+
+// a struct which implements database/sql/driver.Rows
+// by receiving every row from a channel
+type chanRows struct {
+	rowConverter
+	cols   []string
+	ch     <-chan []driver.Value
+	pos    int
+	closed chan struct{}
+	once   sync.Once
+}
+
+// NewRowsFromChan creates sql driver.Rows, which receive every row from
+// the channel as the next row is read, until the channel is closed. So
+// that a test may produce rows while the code under test reads them, to
+// test streaming consumers. The returned channel is closed once the rows
+// are closed, so the producer can stop when the consumer stops early:
+//
+//	rs, closed := NewRowsFromChan([]string{"id"}, ch)
+//	go func() {
+//		defer close(ch)
+//		for i := 0; ; i++ {
+//			select {
+//			case ch <- []driver.Value{i}:
+//			case <-closed:
+//				return
+//			}
+//		}
+//	}()
+//
+// Since the channel is consumed, the rows may be returned by a single
+// query only. Columns are validated like NewRows does
+func NewRowsFromChan(columns []string, ch <-chan []driver.Value) (driver.Rows, <-chan struct{}) {
+	validateColumns(columns)
+	r := &chanRows{cols: columns, ch: ch, closed: make(chan struct{})}
+	return r, r.closed
+}
+
+func (r *chanRows) Columns() []string {
+	return r.cols
+}
+
+func (r *chanRows) Close() error {
+	r.once.Do(func() { close(r.closed) })
+	return nil
+}
+
+// receives the next row
+func (r *chanRows) Next(dest []driver.Value) error {
+	row, ok := <-r.ch
+	if !ok {
+		return io.EOF // per interface spec
+	}
+	r.pos++
+
+	if len(row) != len(r.cols) {
+		return fmt.Errorf("received row %d has %d values %+v, but %d columns %v were declared", r.pos, len(row), row, len(r.cols), r.cols)
+	}
+	return convertRow(r.converter, r.pos, r.cols, row, dest)
+}
+
 // RowsFromCSVString creates Rows from CSV string
 // to be used for mocked queries. Returns sql driver Rows interface
 // ** DEPRECATED ** will be removed in the future, use Rows.FromCSVString
 func RowsFromCSVString(columns []string, s string) driver.Rows {
-	rs := &rows{}
-	rs.cols = columns
+	return NewRows(columns).FromCSVString(s)
+}
 
-	r := strings.NewReader(strings.TrimSpace(s))
-	csvReader := csv.NewReader(r)
+// verifies how the rows returned by an
+// expectation were consumed by the caller
+type rowsTracker struct {
+	mustBeClosed    bool
+	mustBeFullyRead bool
+	returned        []*trackedRows
+}
 
-	for {
-		r, err := csvReader.Read()
-		if err != nil || r == nil {
-			break
-		}
+// wraps the returned rows, if they are to be verified
+func (t *rowsTracker) track(c *conn, rs driver.Rows) driver.Rows {
+	tr := c.counted(rs)
+	if t.mustBeClosed || t.mustBeFullyRead {
+		t.returned = append(t.returned, tr)
+	}
+	return tr
+}
 
-		row := make([]driver.Value, len(columns))
-		for i, v := range r {
-			v := strings.TrimSpace(v)
-			row[i] = []byte(v)
+// reports the first returned rows, which were not consumed as required
+func (t *rowsTracker) leak(e expectation) error {
+	for i, tr := range t.returned {
+		switch {
+		case t.mustBeClosed && !tr.closed:
+			return fmt.Errorf("rows %d returned by %s, declared at %s, were never closed", i+1, e, e.common().declared)
+		case t.mustBeFullyRead && !tr.eof:
+			return fmt.Errorf("rows %d returned by %s, declared at %s, were not fully read", i+1, e, e.common().declared)
 		}
-		rs.rows = append(rs.rows, row)
 	}
-	return rs
+	return nil
+}
+
+// rows returned by a query, which are counted as they are read
+// and hold the connection of the call being made until closed.
+// Their values are converted by the converter of the mock, if set
+func (c *conn) counted(rs driver.Rows) *trackedRows {
+	if rc, ok := rs.(converting); ok && c.converter != nil {
+		rc.convertBy(c.converter)
+	}
+	if c.caller != nil {
+		c.caller.hold(1)
+	}
+	return &trackedRows{Rows: rs, conn: c, session: c.caller}
+}
+
+// rows which remember whether they were closed or fully read
+type trackedRows struct {
+	driver.Rows
+	conn    *conn
+	session *session // the rows hold, if any
+	closed  bool
+	eof     bool
+}
+
+func (r *trackedRows) Close() error {
+	r.conn.mu.Lock()
+	if !r.closed && r.session != nil {
+		r.session.hold(-1)
+	}
+	r.closed = true
+	r.conn.mu.Unlock()
+	return r.Rows.Close()
+}
+
+func (r *trackedRows) Next(dest []driver.Value) error {
+	err := r.Rows.Next(dest)
+	r.conn.mu.Lock()
+	switch err {
+	case nil:
+		r.conn.stats.RowsReturned++
+	case io.EOF:
+		r.eof = true
+	}
+	r.conn.mu.Unlock()
+	return err
 }
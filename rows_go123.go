@@ -0,0 +1,91 @@
+//go:build go1.23
+// +build go1.23
+
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// a struct which implements database/sql/driver.Rows
+// by pulling every row from an iterator on demand
+type seqRows struct {
+	rowConverter
+	cols []string
+	seq  iter.Seq2[[]any, error]
+	next func() ([]any, error, bool)
+	stop func()
+	pos  int
+}
+
+// NewRowsFromSeq creates sql driver.Rows, which pull every row from the
+// iterator only when the next row is read, so that lazily computed rows
+// may be returned. The iteration stops once the rows are closed. Since
+// every query iterates the sequence anew, the rows may be returned by
+// several queries, if the sequence may be iterated several times:
+//
+//	rs := NewRowsFromSeq([]string{"id"}, func(yield func([]any) bool) {
+//		for i := 1; yield([]any{i}); i++ {
+//		}
+//	})
+//
+// Columns are validated like NewRows does
+func NewRowsFromSeq(columns []string, seq iter.Seq[[]any]) driver.Rows {
+	return NewRowsFromSeq2(columns, func(yield func([]any, error) bool) {
+		for row := range seq {
+			if !yield(row, nil) {
+				return
+			}
+		}
+	})
+}
+
+// NewRowsFromSeq2 creates sql driver.Rows like NewRowsFromSeq, from an
+// iterator of rows and errors. A non nil error is returned by the read
+// of the row, like a failure of the connection in the middle of a result
+func NewRowsFromSeq2(columns []string, seq iter.Seq2[[]any, error]) driver.Rows {
+	validateColumns(columns)
+	return &seqRows{cols: columns, seq: seq}
+}
+
+func (r *seqRows) Columns() []string {
+	return r.cols
+}
+
+func (r *seqRows) Close() error {
+	if r.stop != nil {
+		r.stop()
+	}
+	return nil
+}
+
+func (r *seqRows) clone() driver.Rows {
+	return &seqRows{cols: r.cols, seq: r.seq}
+}
+
+// pulls the next row
+func (r *seqRows) Next(dest []driver.Value) error {
+	if r.next == nil {
+		r.next, r.stop = iter.Pull2(r.seq)
+	}
+	row, err, ok := r.next()
+	if !ok {
+		return io.EOF // per interface spec
+	}
+	if err != nil {
+		return err
+	}
+	r.pos++
+
+	if len(row) != len(r.cols) {
+		return fmt.Errorf("pulled row %d has %d values %+v, but %d columns %v were declared", r.pos, len(row), row, len(r.cols), r.cols)
+	}
+	values := make([]driver.Value, len(row))
+	for i, v := range row {
+		values[i] = v
+	}
+	return convertRow(r.converter, r.pos, r.cols, values, dest)
+}
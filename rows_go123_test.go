@@ -0,0 +1,73 @@
+//go:build go1.23
+// +build go1.23
+
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestRowsFromSeq(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	pulled := 0
+	rs := NewRowsFromSeq([]string{"id", "title"}, func(yield func([]any) bool) {
+		for i := 1; i <= 5; i++ {
+			pulled = i
+			if !yield([]any{i, "post"}) {
+				return
+			}
+		}
+	})
+	m.ExpectQuery("SELECT (.+) FROM posts").WillReturnRows(rs).Times(2)
+
+	for query := 1; query <= 2; query++ {
+		rows, err := db.Query("SELECT id, title FROM posts")
+		if err != nil {
+			t.Fatalf("error '%s' was not expected while querying posts", err)
+		}
+		var id int
+		var title string
+		for i := 0; i < 2 && rows.Next(); i++ {
+			if err = rows.Scan(&id, &title); err != nil {
+				t.Errorf("error '%s' was not expected while scanning a post", err)
+			}
+		}
+		rows.Close()
+		if id != 2 || pulled != 2 {
+			t.Errorf("expected query %d to pull 2 rows lazily, but it read id %d and pulled %d", query, id, pulled)
+		}
+	}
+
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
+
+func TestRowsFromSeq2ReturnsErrors(t *testing.T) {
+	failure := errors.New("connection lost")
+	rs := NewRowsFromSeq2([]string{"id"}, func(yield func([]any, error) bool) {
+		if yield([]any{1}, nil) {
+			yield(nil, failure)
+		}
+	})
+
+	dest := make([]driver.Value, 1)
+	if err := rs.Next(dest); err != nil || dest[0] != int64(1) {
+		t.Fatalf("expected the first row to be read, but got %v and error: %v", dest, err)
+	}
+	if err := rs.Next(dest); err != failure {
+		t.Errorf("expected the error of the sequence, but got: %v", err)
+	}
+	if err := rs.Next(dest); err != io.EOF {
+		t.Errorf("expected io.EOF once the sequence ended, but got %v", err)
+	}
+	rs.Close()
+}
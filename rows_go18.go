@@ -0,0 +1,21 @@
+//go:build go1.8
+// +build go1.8
+
+package sqlmock
+
+import (
+	"database/sql/driver"
+)
+
+// ColumnTypeDatabaseTypeName implements driver.RowsColumnTypeDatabaseTypeName
+func (r *rows) ColumnTypeDatabaseTypeName(index int) string {
+	return r.dbType(index)
+}
+
+// ColumnTypeDatabaseTypeName implements driver.RowsColumnTypeDatabaseTypeName
+func (r *trackedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if tn, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return tn.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
@@ -0,0 +1,43 @@
+//go:build go1.8
+// +build go1.8
+
+package sqlmock
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestRawBytesDatabaseTypeName(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectQuery("SELECT (.+) FROM products").
+		WillReturnRows(NewRows([]string{"id", "price", "name"}).
+			AddRow(1, RawBytes([]byte("10.50"), "DECIMAL"), "apple")).
+		MustBeClosed()
+
+	rs, err := db.Query("SELECT id, price, name FROM products")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while selecting products", err)
+	}
+
+	types, err := rs.ColumnTypes()
+	if err != nil {
+		t.Errorf("error '%s' was not expected while reading column types", err)
+	}
+	var names []string
+	for _, ct := range types {
+		names = append(names, ct.DatabaseTypeName())
+	}
+	if len(names) != 3 || names[0] != "" || names[1] != "DECIMAL" || names[2] != "" {
+		t.Errorf("expected only the raw column to have a database type, but got %q", names)
+	}
+	rs.Close()
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
@@ -0,0 +1,396 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestAddRowShouldPanicOnColumnMismatch(t *testing.T) {
+	defer func() {
+		e := recover()
+		if e == nil {
+			t.Fatal("expected a panic, since the number of values does not match columns")
+		}
+		msg, ok := e.(string)
+		if !ok || !strings.Contains(msg, "[id title]") {
+			t.Errorf("expected panic message to name the columns, but got: %v", e)
+		}
+	}()
+	NewRows([]string{"id", "title"}).AddRow(1)
+}
+
+func TestFromCSVStringShouldPanicOnColumnMismatch(t *testing.T) {
+	defer func() {
+		e := recover()
+		if e == nil {
+			t.Fatal("expected a panic, since the number of values does not match columns")
+		}
+		msg, ok := e.(string)
+		if !ok || !strings.Contains(msg, "row 2") {
+			t.Errorf("expected panic message to name the offending row, but got: %v", e)
+		}
+	}()
+	NewRows([]string{"id", "title"}).FromCSVString("1,one\n2,two,extra")
+}
+
+func TestNewRowsShouldPanicOnInvalidColumns(t *testing.T) {
+	cases := map[string][]string{
+		`column 2 in ["id" "" "title"] has an empty name`:          {"id", "", "title"},
+		`column 3 'id' in ["id" "title" "id"] duplicates column 1`: {"id", "title", "id"},
+	}
+	for expected, cols := range cases {
+		func() {
+			defer func() {
+				if msg, _ := recover().(string); msg != expected {
+					t.Errorf("expected columns %q to panic with \"%s\", but got: %s", cols, expected, msg)
+				}
+			}()
+			NewRows(cols)
+		}()
+	}
+}
+
+type status string
+
+type point struct {
+	x, y int
+}
+
+type valuer struct {
+	v string
+}
+
+func (v valuer) Value() (driver.Value, error) {
+	return v.v, nil
+}
+
+func TestAddRowShouldConvertValues(t *testing.T) {
+	rs := NewRows([]string{"num", "status", "valuer"}).AddRow(int32(5), status("active"), valuer{"val"})
+
+	dest := make([]driver.Value, 3)
+	if err := rs.Next(dest); err != nil {
+		t.Fatalf("error '%s' was not expected while reading the row", err)
+	}
+
+	if v, ok := dest[0].(int64); !ok || v != 5 {
+		t.Errorf("expected int32 to be converted to int64(5), but got %T(%v)", dest[0], dest[0])
+	}
+
+	if v, ok := dest[1].(string); !ok || v != "active" {
+		t.Errorf("expected custom string type to be converted to string 'active', but got %T(%v)", dest[1], dest[1])
+	}
+
+	if v, ok := dest[2].(string); !ok || v != "val" {
+		t.Errorf("expected valuer to be converted to string 'val', but got %T(%v)", dest[2], dest[2])
+	}
+}
+
+func TestAddRowShouldFailOnUnconvertibleValue(t *testing.T) {
+	rs := NewRows([]string{"id", "location"}).AddRow(1, point{1, 2})
+
+	dest := make([]driver.Value, 2)
+	err := rs.Next(dest)
+	if err == nil {
+		t.Fatal("expected an error, since a struct cannot be converted to a driver value")
+	}
+
+	if !strings.Contains(err.Error(), "location") {
+		t.Errorf("expected error to name the column, but got: %s", err)
+	}
+}
+
+func TestAddRowShouldGenerateValuesOnRead(t *testing.T) {
+	seq := 0
+	next := func() driver.Value {
+		seq++
+		return seq
+	}
+	rs := NewRows([]string{"id", "title"}).AddRow(next, "one").AddRow(next, "two")
+
+	if seq != 0 {
+		t.Errorf("expected values to be generated as rows are read, but %d were generated", seq)
+	}
+
+	dest := make([]driver.Value, 2)
+	for i := 1; i <= 2; i++ {
+		if err := rs.Next(dest); err != nil {
+			t.Fatalf("error '%s' was not expected while reading row %d", err, i)
+		}
+		if dest[0] != int64(i) {
+			t.Errorf("expected generated id to be %d, but got %T(%v)", i, dest[0], dest[0])
+		}
+	}
+}
+
+func TestRowsGenerator(t *testing.T) {
+	calls := 0
+	rs := NewRowsGenerator([]string{"id", "title"}, 3, func(i int) []driver.Value {
+		calls++
+		return []driver.Value{i + 1, fmt.Sprintf("title %d", i+1)}
+	})
+
+	if calls != 0 {
+		t.Errorf("expected rows to be generated lazily, but generator was called %d times", calls)
+	}
+
+	dest := make([]driver.Value, 2)
+	for i := 1; i <= 3; i++ {
+		if err := rs.Next(dest); err != nil {
+			t.Fatalf("error '%s' was not expected while reading row %d", err, i)
+		}
+		if dest[0] != int64(i) {
+			t.Errorf("expected id to be %d, but got %v", i, dest[0])
+		}
+		if calls != i {
+			t.Errorf("expected generator to be called %d times, but it was called %d times", i, calls)
+		}
+	}
+
+	if err := rs.Next(dest); err != io.EOF {
+		t.Errorf("expected io.EOF after the last generated row, but got %v", err)
+	}
+}
+
+func TestRowsGeneratorColumnMismatch(t *testing.T) {
+	rs := NewRowsGenerator([]string{"id", "title"}, 1, func(i int) []driver.Value {
+		return []driver.Value{i}
+	})
+
+	if err := rs.Next(make([]driver.Value, 2)); err == nil {
+		t.Error("expected an error, since the generated row does not match columns")
+	}
+}
+
+// a reader which fails, once everything before it was read
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	return 0, r.err
+}
+
+func TestRowsFromCSVAreParsedOnDemand(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	broken := fmt.Errorf("fixture is truncated")
+	fixture := io.MultiReader(strings.NewReader("1, one\n2,two\n"), failingReader{broken})
+	ExpectQuery("SELECT (.+) FROM items").
+		WillReturnRows(NewRowsFromCSV([]string{"id", "title"}, fixture))
+
+	rs, err := db.Query("SELECT id, title FROM items")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while selecting items", err)
+	}
+
+	var titles []string
+	for rs.Next() {
+		var id int
+		var title string
+		if err = rs.Scan(&id, &title); err != nil {
+			t.Errorf("error '%s' was not expected while scanning an item", err)
+		}
+		titles = append(titles, title)
+	}
+	if strings.Join(titles, ",") != "one,two" {
+		t.Errorf("expected the rows before the failure to be read, but got %v", titles)
+	}
+	if rs.Err() != broken {
+		t.Errorf("expected the error of the reader, but got: %v", rs.Err())
+	}
+	rs.Close()
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestRowsFromCSVColumnMismatch(t *testing.T) {
+	rs := NewRowsFromCSV([]string{"id", "title"}, strings.NewReader("1,one,extra"))
+
+	if err := rs.Next(make([]driver.Value, 2)); err == nil || !strings.Contains(err.Error(), "csv row 1") {
+		t.Errorf("expected an error naming the row, since it does not match columns, but got: %v", err)
+	}
+}
+
+func TestRowsFromChanStopProducerOnClose(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ch := make(chan []driver.Value)
+	rs, closed := NewRowsFromChan([]string{"id"}, ch)
+	m.ExpectQuery("SELECT id FROM events").WillReturnRows(rs)
+
+	produced := make(chan int)
+	go func() {
+		defer close(ch)
+		i := 0
+		defer func() { produced <- i }()
+		for ; ; i++ {
+			select {
+			case ch <- []driver.Value{i}:
+			case <-closed:
+				return
+			}
+		}
+	}()
+
+	rows, err := db.Query("SELECT id FROM events")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while querying events", err)
+	}
+	var id int
+	for i := 0; i < 3 && rows.Next(); i++ {
+		if err = rows.Scan(&id); err != nil {
+			t.Errorf("error '%s' was not expected while scanning an event", err)
+		}
+	}
+	rows.Close()
+
+	if n := <-produced; n != 3 {
+		t.Errorf("expected the producer to stop after 3 rows were read, but it produced %d", n)
+	}
+	if id != 2 {
+		t.Errorf("expected the last id read to be 2, but got %d", id)
+	}
+	db.Close()
+}
+
+func TestRowsFromChanEndWithChannel(t *testing.T) {
+	ch := make(chan []driver.Value, 2)
+	ch <- []driver.Value{1, "one"}
+	ch <- []driver.Value{2}
+	close(ch)
+	rs, _ := NewRowsFromChan([]string{"id", "title"}, ch)
+
+	dest := make([]driver.Value, 2)
+	if err := rs.Next(dest); err != nil {
+		t.Fatalf("error '%s' was not expected while reading the first row", err)
+	}
+	if err := rs.Next(dest); err == nil || !strings.Contains(err.Error(), "received row 2 has 1 values") {
+		t.Errorf("expected an error for a row with too few values, but got: %v", err)
+	}
+	if err := rs.Next(dest); err != io.EOF {
+		t.Errorf("expected io.EOF once the channel is closed, but got %v", err)
+	}
+}
+
+func TestRowsMustBeClosedOrFullyRead(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rs := NewRows([]string{"id"}).AddRow(1).AddRow(2)
+	ExpectQuery("SELECT (.+) FROM users").WillReturnRows(rs).MustBeClosed()
+
+	users, err := db.Query("SELECT id FROM users")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while selecting users", err)
+	}
+
+	err = ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "were never closed") {
+		t.Errorf("expected users rows to be reported as never closed, but got %v", err)
+	}
+	users.Close()
+
+	ExpectQuery("SELECT (.+) FROM orders").WillReturnRows(rs).MustBeFullyRead()
+	orders, err := db.Query("SELECT id FROM orders")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while selecting orders", err)
+	}
+	orders.Next()
+	orders.Close()
+
+	err = ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "were not fully read") {
+		t.Errorf("expected orders rows to be reported as not fully read, but got %v", err)
+	}
+
+	Reset()
+	ExpectQuery("SELECT (.+) FROM orders").WillReturnRows(rs).MustBeFullyRead().MustBeClosed()
+	orders, err = db.Query("SELECT id FROM orders")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while selecting orders", err)
+	}
+	for orders.Next() {
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestWillReturnRow(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectQuery("SELECT (.+) FROM users WHERE id = ?").
+		WithArgs(5).
+		WillReturnRow([]string{"id", "name"}, 5, "gedi")
+
+	var id int
+	var name string
+	if err = db.QueryRow("SELECT id, name FROM users WHERE id = ?", 5).Scan(&id, &name); err != nil {
+		t.Errorf("error '%s' was not expected while selecting a user", err)
+	}
+	if id != 5 || name != "gedi" {
+		t.Errorf("expected user 5 gedi, but got %d %s", id, name)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestRawBytesRowValues(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectQuery("SELECT (.+) FROM documents").
+		WillReturnRows(NewRows([]string{"id", "body"}).
+			AddRow(RawBytes([]byte("1"), "INT"), RawBytes([]byte(`{"a":1}`), "JSON")).
+			AddRow(RawBytes([]byte("2"), "INT"), RawBytes(nil, "JSON")))
+
+	rs, err := db.Query("SELECT id, body FROM documents")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while selecting documents", err)
+	}
+
+	var bodies []string
+	for rs.Next() {
+		var id int
+		var body sql.RawBytes
+		if err = rs.Scan(&id, &body); err != nil {
+			t.Errorf("error '%s' was not expected while scanning raw bytes", err)
+		}
+		if body == nil {
+			bodies = append(bodies, "NULL")
+			continue
+		}
+		bodies = append(bodies, string(body))
+	}
+	rs.Close()
+
+	if strings.Join(bodies, ", ") != `{"a":1}, NULL` {
+		t.Errorf("expected raw bodies to be scanned as returned, but got %v", bodies)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
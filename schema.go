@@ -0,0 +1,764 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schema describes tables and their columns, as created by DDL
+// statements, like the ones of migration files. Statements may be
+// validated against it with RequireSchema
+type Schema struct {
+	tables map[string]*schemaTable
+	names  []string // of tables, in order of creation
+}
+
+type schemaTable struct {
+	name    string
+	columns []string          // unknown if nil, like the ones of views
+	types   map[string]string // of the columns, lowercased, if declared
+}
+
+// ParseSchema builds a schema from DDL statements, applied in
+// the given order. CREATE TABLE, CREATE VIEW, ALTER TABLE, DROP
+// TABLE and RENAME TABLE are understood, other statements, like
+// CREATE INDEX or data inserted by a migration, are ignored
+func ParseSchema(ddl ...string) (*Schema, error) {
+	s := &Schema{tables: make(map[string]*schemaTable)}
+	for _, d := range ddl {
+		if err := s.apply(d); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// migration tools mark the part of a file, which reverts the migration
+var downMigration = regexp.MustCompile(`(?im)^\s*--\s*\+(?:goose|migrate)\s+down\b`)
+
+// LoadSchema builds a schema from migration files, applied in the
+// given order, like the sorted result of filepath.Glob. Files of down
+// migrations, named *.down.sql, and the down sections of goose or
+// sql-migrate files are skipped
+func LoadSchema(paths ...string) (*Schema, error) {
+	s := &Schema{tables: make(map[string]*schemaTable)}
+	for _, path := range paths {
+		if strings.HasSuffix(path, ".down.sql") {
+			continue
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		ddl := string(data)
+		if loc := downMigration.FindStringIndex(ddl); loc != nil {
+			ddl = ddl[:loc[0]]
+		}
+		if err = s.apply(ddl); err != nil {
+			return nil, fmt.Errorf("migration '%s': %s", path, err)
+		}
+	}
+	return s, nil
+}
+
+// Tables lists the tables and views of the schema in order of creation
+func (s *Schema) Tables() []string {
+	return append([]string(nil), s.names...)
+}
+
+// Columns lists the columns of the table in order of creation,
+// nil if the table does not exist or its columns are not known
+func (s *Schema) Columns(table string) []string {
+	if t := s.tables[strings.ToLower(table)]; t != nil && t.columns != nil {
+		return append([]string(nil), t.columns...)
+	}
+	return nil
+}
+
+// words which start a table constraint instead of a column definition
+var constraintWords = map[string]bool{
+	"CONSTRAINT": true,
+	"PRIMARY":    true,
+	"UNIQUE":     true,
+	"KEY":        true,
+	"INDEX":      true,
+	"FOREIGN":    true,
+	"CHECK":      true,
+	"FULLTEXT":   true,
+	"SPATIAL":    true,
+	"EXCLUDE":    true,
+	"LIKE":       true,
+	"PERIOD":     true,
+}
+
+// applies DDL statements to the schema
+func (s *Schema) apply(ddl string) error {
+	for _, ts := range statements(tokenize(ddl)) {
+		var err error
+		switch {
+		case ts[0].is("CREATE"):
+			err = s.create(ts)
+		case ts[0].is("ALTER") && len(ts) > 1 && ts[1].is("TABLE"):
+			err = s.alter(ts)
+		case ts[0].is("DROP") && len(ts) > 1 && (ts[1].is("TABLE") || ts[1].is("VIEW")):
+			i := skipWords(ts, 2, "IF", "EXISTS")
+			for i < len(ts) {
+				name, next := qualifiedName(ts, i)
+				s.drop(name)
+				if next >= len(ts) || !ts[next].isPunct(",") {
+					break
+				}
+				i = next + 1
+			}
+		case ts[0].is("RENAME") && len(ts) > 1 && ts[1].is("TABLE"):
+			for i := 2; i+2 < len(ts); {
+				from, next := qualifiedName(ts, i)
+				if next >= len(ts) || !ts[next].is("TO") {
+					break
+				}
+				to, next := qualifiedName(ts, next+1)
+				s.rename(from, to)
+				if next >= len(ts) || !ts[next].isPunct(",") {
+					break
+				}
+				i = next + 1
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applies CREATE TABLE and CREATE VIEW statements
+func (s *Schema) create(ts []token) error {
+	i := skipWords(ts, 1, "OR", "REPLACE", "TEMP", "TEMPORARY", "UNLOGGED", "GLOBAL", "LOCAL", "MATERIALIZED")
+	if i >= len(ts) || !ts[i].is("TABLE") && !ts[i].is("VIEW") {
+		return nil
+	}
+	i = skipWords(ts, i+1, "IF", "NOT", "EXISTS")
+	if i >= len(ts) || !ts[i].ident() {
+		return fmt.Errorf("statement '%s' does not name the table it creates", join(ts))
+	}
+	name, i := qualifiedName(ts, i)
+	t := &schemaTable{name: name, types: make(map[string]string)}
+	if i < len(ts) && ts[i].isPunct("(") {
+		t.columns = []string{}
+		for _, item := range items(ts, i) {
+			if !constraintWords[strings.ToUpper(item[0].text)] || item[0].kind == 'q' {
+				t.add(item)
+			}
+		}
+	}
+	s.drop(name)
+	s.tables[name] = t
+	s.names = append(s.names, name)
+	return nil
+}
+
+// applies ALTER TABLE statements, which add, drop or rename columns or rename the table
+func (s *Schema) alter(ts []token) error {
+	i := skipWords(ts, 2, "IF", "EXISTS", "ONLY")
+	if i >= len(ts) {
+		return nil
+	}
+	name, i := qualifiedName(ts, i)
+	t := s.tables[name]
+	if t == nil {
+		return fmt.Errorf("statement '%s' alters table '%s', which was not created", join(ts), name)
+	}
+	for _, action := range split(ts[i:]) {
+		j := 1
+		switch {
+		case action[0].is("ADD"):
+			j = skipWords(action, j, "COLUMN", "IF", "NOT", "EXISTS")
+			if j < len(action) && (action[j].kind == 'q' || !constraintWords[strings.ToUpper(action[j].text)]) {
+				t.add(action[j:])
+			}
+		case action[0].is("DROP"):
+			if j < len(action) && (constraintWords[strings.ToUpper(action[j].text)] || action[j].is("DEFAULT")) {
+				continue
+			}
+			j = skipWords(action, j, "COLUMN", "IF", "EXISTS")
+			if j < len(action) {
+				t.remove(strings.ToLower(action[j].text))
+			}
+		case action[0].is("RENAME") && len(action) > 2 && action[1].is("TO"):
+			to, _ := qualifiedName(action, 2)
+			s.rename(t.name, to)
+		case action[0].is("RENAME") || action[0].is("CHANGE"):
+			j = skipWords(action, j, "COLUMN")
+			if j+1 < len(action) && !constraintWords[strings.ToUpper(action[j].text)] {
+				to := j + 1
+				if action[to].is("TO") {
+					to++
+				}
+				if to < len(action) {
+					t.renameColumn(strings.ToLower(action[j].text), strings.ToLower(action[to].text))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) drop(name string) {
+	if s.tables[name] == nil {
+		return
+	}
+	delete(s.tables, name)
+	for i, n := range s.names {
+		if n == name {
+			s.names = append(s.names[:i], s.names[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *Schema) rename(from, to string) {
+	t := s.tables[from]
+	if t == nil {
+		return
+	}
+	delete(s.tables, from)
+	t.name = to
+	s.tables[to] = t
+	for i, n := range s.names {
+		if n == from {
+			s.names[i] = to
+		}
+	}
+}
+
+func (t *schemaTable) has(column string) bool {
+	if t.columns == nil {
+		return true // not known
+	}
+	for _, c := range t.columns {
+		if c == column {
+			return true
+		}
+	}
+	return false
+}
+
+// adds the column defined by the tokens, its name followed by its type
+func (t *schemaTable) add(def []token) {
+	column := strings.ToLower(def[0].text)
+	if t.columns == nil || t.has(column) {
+		return
+	}
+	t.columns = append(t.columns, column)
+	if len(def) > 1 && def[1].kind == 'w' {
+		t.types[column] = strings.ToLower(def[1].text)
+	}
+}
+
+func (t *schemaTable) remove(column string) {
+	for i, c := range t.columns {
+		if c == column {
+			t.columns = append(t.columns[:i], t.columns[i+1:]...)
+			delete(t.types, column)
+			return
+		}
+	}
+}
+
+func (t *schemaTable) renameColumn(from, to string) {
+	for i, c := range t.columns {
+		if c == from {
+			t.columns[i] = to
+			if typ, ok := t.types[from]; ok {
+				delete(t.types, from)
+				t.types[to] = typ
+			}
+		}
+	}
+}
+
+// statements which are validated against the schema
+var schemaChecked = map[string]bool{
+	"SELECT":  true,
+	"INSERT":  true,
+	"UPDATE":  true,
+	"DELETE":  true,
+	"REPLACE": true,
+	"WITH":    true,
+}
+
+// words which may follow a table reference, so they are not its alias
+var clauseWords = map[string]bool{
+	"WHERE": true, "JOIN": true, "INNER": true, "LEFT": true, "RIGHT": true, "FULL": true,
+	"OUTER": true, "CROSS": true, "NATURAL": true, "STRAIGHT_JOIN": true, "ON": true,
+	"USING": true, "GROUP": true, "ORDER": true, "LIMIT": true, "OFFSET": true, "FETCH": true,
+	"HAVING": true, "WINDOW": true, "UNION": true, "EXCEPT": true, "INTERSECT": true,
+	"SET": true, "VALUES": true, "DEFAULT": true, "SELECT": true, "RETURNING": true,
+	"OUTPUT": true, "FOR": true, "USE": true, "FORCE": true, "IGNORE": true, "LOCK": true,
+	"PARTITION": true, "TABLESAMPLE": true, "OVERRIDING": true, "AS": true,
+}
+
+// schemas of the database catalog, which are never validated
+var catalogSchemas = map[string]bool{
+	"information_schema": true,
+	"pg_catalog":         true,
+	"sys":                true,
+	"mysql":              true,
+}
+
+// a table referenced by a statement
+type tableRef struct {
+	name      string
+	qualifier string
+	alias     string
+	columns   []string // certainly columns of the table, like the ones of INSERT
+}
+
+// checks whether the tables and columns the statement references
+// are in the schema. Columns are checked, where they are certainly
+// columns of a table: in the column list of INSERT, in SET of UPDATE
+// and when qualified by the name or the alias of a table
+func (s *Schema) check(query string) error {
+	ts := tokenize(query)
+	if len(ts) == 0 || !schemaChecked[strings.ToUpper(ts[0].text)] {
+		return nil
+	}
+
+	refs := references(ts)
+	scope := make(map[string]*schemaTable)
+	for _, r := range refs {
+		table := s.tables[r.name]
+		if table == nil {
+			return fmt.Errorf("statement '%s' references table '%s', which is not in the schema", query, r.name)
+		}
+		scope[r.name] = table
+		if r.alias != "" {
+			scope[r.alias] = table
+		}
+	}
+	for _, r := range refs {
+		for _, column := range r.columns {
+			if table := s.tables[r.name]; !table.has(column) {
+				return fmt.Errorf("statement '%s' references column '%s' of table '%s', which is not in the schema", query, column, table.name)
+			}
+		}
+	}
+	for i := 0; i+2 < len(ts); i++ {
+		if !ts[i].ident() || !ts[i+1].isPunct(".") || ts[i+2].kind != 'w' && ts[i+2].kind != 'q' {
+			continue
+		}
+		if (i > 0 && ts[i-1].isPunct(".")) || (i+3 < len(ts) && ts[i+3].isPunct(".")) {
+			continue // qualified by a schema
+		}
+		table := scope[strings.ToLower(ts[i].text)]
+		if column := strings.ToLower(ts[i+2].text); table != nil && !table.has(column) {
+			return fmt.Errorf("statement '%s' references column '%s' of table '%s', which is not in the schema", query, column, table.name)
+		}
+	}
+	return nil
+}
+
+// the tables the statement references, except for the ones of
+// common table expressions and of the catalog of the database
+func references(ts []token) (refs []tableRef) {
+	ctes := make(map[string]bool)
+	for i := 0; i+2 < len(ts); i++ {
+		if ts[i].ident() && ts[i+1].is("AS") && ts[i+2].isPunct("(") {
+			ctes[strings.ToLower(ts[i].text)] = true
+		}
+	}
+
+	selects := []bool{true} // whether FROM may follow at the depth of parentheses
+	for i := 0; i < len(ts); i++ {
+		t := ts[i]
+		switch {
+		case t.isPunct("("):
+			selects = append(selects, false)
+			continue
+		case t.isPunct(")"):
+			if len(selects) > 1 {
+				selects = selects[:len(selects)-1]
+			}
+			continue
+		case t.is("SELECT") || t.is("DELETE"):
+			selects[len(selects)-1] = true
+			continue
+		case t.is("FROM") && selects[len(selects)-1], t.is("JOIN"), t.is("INTO"):
+		case t.is("UPDATE") && (i == 0 || !ts[i-1].is("FOR") && !ts[i-1].is("DO") && !ts[i-1].is("KEY")):
+		default:
+			continue
+		}
+
+		found, last := tableRefs(ts, i+1, t.is("FROM"), t.is("INTO"))
+		if len(found) == 1 {
+			r := &found[0]
+			switch {
+			case t.is("INTO") && last+1 < len(ts) && ts[last+1].isPunct("("):
+				for _, item := range items(ts, last+1) {
+					r.columns = append(r.columns, strings.ToLower(item[0].text))
+				}
+			case t.is("UPDATE") && last+1 < len(ts) && ts[last+1].is("SET"):
+				for _, item := range split(ts[last+2:]) {
+					if len(item) > 1 && item[0].ident() && item[1].isPunct("=") {
+						r.columns = append(r.columns, strings.ToLower(item[0].text))
+					}
+					if len(item) > 3 && item[1].isPunct(".") && item[3].isPunct("=") {
+						r.columns = append(r.columns, strings.ToLower(item[2].text))
+					}
+				}
+			}
+		}
+		for _, r := range found {
+			if !ctes[r.name] && !catalogSchemas[r.qualifier] && r.name != "dual" {
+				refs = append(refs, r)
+			}
+		}
+		i = last
+	}
+	return refs
+}
+
+// parses the table references starting at i, a comma separated list of
+// them if list is set, and returns the index of the last token parsed.
+// Functions, like generate_series(...), and subqueries are not tables,
+// unless the reference is the one of INSERT INTO t (columns)
+func tableRefs(ts []token, i int, list, into bool) (refs []tableRef, last int) {
+	for {
+		if i >= len(ts) || !ts[i].ident() || ts[i].kind == 'w' && clauseWords[strings.ToUpper(ts[i].text)] {
+			return refs, i - 1
+		}
+		var r tableRef
+		start := i
+		r.name, i = qualifiedName(ts, start)
+		if i > start+1 {
+			r.qualifier = strings.ToLower(ts[i-3].text)
+		}
+		if !into && i < len(ts) && ts[i].isPunct("(") {
+			return refs, start - 1
+		}
+		if i < len(ts) && ts[i].is("AS") {
+			i++
+		}
+		if i < len(ts) && ts[i].ident() && !(ts[i].kind == 'w' && clauseWords[strings.ToUpper(ts[i].text)]) {
+			r.alias = strings.ToLower(ts[i].text)
+			i++
+		}
+		refs = append(refs, r)
+		if !list || i >= len(ts) || !ts[i].isPunct(",") {
+			return refs, i - 1
+		}
+		i++
+	}
+}
+
+// a lexical token of a statement
+type token struct {
+	text string
+	kind byte // 'w' word, 'q' quoted identifier, 's' string, 'n' number, '?' placeholder, 'p' punctuation
+}
+
+func (t token) is(word string) bool {
+	return t.kind == 'w' && strings.EqualFold(t.text, word)
+}
+
+func (t token) isPunct(p string) bool {
+	return t.kind == 'p' && t.text == p
+}
+
+func (t token) ident() bool {
+	return t.kind == 'w' || t.kind == 'q'
+}
+
+// splits statements into tokens, skipping comments. Quoted identifiers
+// are unquoted, dollar quoted strings of postgres are single tokens
+func tokenize(q string) (ts []token) {
+	for i := 0; i < len(q); i++ {
+		c := q[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		case c == '-' && strings.HasPrefix(q[i:], "--"):
+			if end := strings.IndexByte(q[i:], '\n'); end != -1 {
+				i += end
+			} else {
+				i = len(q)
+			}
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			if end := strings.Index(q[i+2:], "*/"); end != -1 {
+				i += end + 3
+			} else {
+				i = len(q)
+			}
+		case c == '\'':
+			j := skipQuoted(q, i, c)
+			if j == len(q) {
+				j-- // not terminated
+			}
+			ts = append(ts, token{q[i : j+1], 's'})
+			i = j
+		case c == '"' || c == '`' || c == '[':
+			end := c
+			if c == '[' {
+				end = ']'
+			}
+			j := skipQuoted(q, i, end)
+			ts = append(ts, token{q[i+1 : j], 'q'})
+			i = j
+		case c == '$' && i+1 < len(q) && isDigit(q[i+1]):
+			j := i + 1
+			for j < len(q) && isDigit(q[j]) {
+				j++
+			}
+			ts = append(ts, token{q[i:j], '?'})
+			i = j - 1
+		case c == '$':
+			tag := q[i:]
+			if end := strings.IndexByte(q[i+1:], '$'); end != -1 {
+				tag = q[i : i+end+2]
+			}
+			j := len(q)
+			if end := strings.Index(q[i+len(tag):], tag); end != -1 {
+				j = i + len(tag) + end + len(tag)
+			}
+			ts = append(ts, token{q[i:j], 's'})
+			i = j - 1
+		case (c == ':' || c == '@') && i+1 < len(q) && isLetter(q[i+1]):
+			j := i + 1
+			for j < len(q) && (isLetter(q[j]) || isDigit(q[j])) {
+				j++
+			}
+			ts = append(ts, token{q[i:j], '?'})
+			i = j - 1
+		case c == '?':
+			ts = append(ts, token{"?", '?'})
+		case isLetter(c):
+			j := i
+			for j < len(q) && (isLetter(q[j]) || isDigit(q[j]) || q[j] == '$') {
+				j++
+			}
+			ts = append(ts, token{q[i:j], 'w'})
+			i = j - 1
+		case isDigit(c):
+			j := i
+			for j < len(q) && (isDigit(q[j]) || q[j] == '.') {
+				j++
+			}
+			ts = append(ts, token{q[i:j], 'n'})
+			i = j - 1
+		default:
+			ts = append(ts, token{string(c), 'p'})
+		}
+	}
+	return ts
+}
+
+// splits tokens into statements separated by semicolons
+func statements(ts []token) (stmts [][]token) {
+	start := 0
+	for i := 0; i <= len(ts); i++ {
+		if i == len(ts) || ts[i].isPunct(";") {
+			if i > start {
+				stmts = append(stmts, ts[start:i])
+			}
+			start = i + 1
+		}
+	}
+	return stmts
+}
+
+// splits tokens at commas, which are not within parentheses
+func split(ts []token) (parts [][]token) {
+	depth, start := 0, 0
+	for i := 0; i <= len(ts); i++ {
+		switch {
+		case i == len(ts) || depth == 0 && ts[i].isPunct(","):
+			if i > start {
+				parts = append(parts, ts[start:i])
+			}
+			start = i + 1
+		case ts[i].isPunct("("):
+			depth++
+		case ts[i].isPunct(")"):
+			depth--
+		}
+	}
+	return parts
+}
+
+// the comma separated items within the parentheses opened at i
+func items(ts []token, i int) [][]token {
+	depth := 0
+	for j := i; j < len(ts); j++ {
+		switch {
+		case ts[j].isPunct("("):
+			depth++
+		case ts[j].isPunct(")"):
+			if depth--; depth == 0 {
+				return split(ts[i+1 : j])
+			}
+		}
+	}
+	return split(ts[i+1:])
+}
+
+// the lowercased last part of a name qualified by dots
+// starting at i, and the index of the token following it
+func qualifiedName(ts []token, i int) (string, int) {
+	var name string
+	for i < len(ts) && ts[i].ident() {
+		name = strings.ToLower(ts[i].text)
+		if i+1 >= len(ts) || !ts[i+1].isPunct(".") {
+			return name, i + 1
+		}
+		i += 2
+	}
+	return name, i
+}
+
+// skips the given words starting at i
+func skipWords(ts []token, i int, words ...string) int {
+next:
+	for i < len(ts) {
+		for _, w := range words {
+			if ts[i].is(w) {
+				i++
+				continue next
+			}
+		}
+		break
+	}
+	return i
+}
+
+// joins the tokens of a statement, for messages
+func join(ts []token) string {
+	texts := make([]string, len(ts))
+	for i, t := range ts {
+		texts[i] = t.text
+	}
+	return strings.Join(texts, " ")
+}
+
+// categories of column types, values are checked to be compatible with
+var typeCategories = map[string]string{
+	"int": "integer", "integer": "integer", "smallint": "integer", "bigint": "integer",
+	"tinyint": "integer", "mediumint": "integer", "serial": "integer", "bigserial": "integer",
+	"smallserial": "integer", "int2": "integer", "int4": "integer", "int8": "integer",
+	"real": "float", "float": "float", "double": "float", "float4": "float", "float8": "float",
+	"numeric": "float", "decimal": "float", "money": "float",
+	"bool": "bool", "boolean": "bool", "bit": "bool",
+	"text": "text", "varchar": "text", "char": "text", "character": "text", "nvarchar": "text",
+	"nchar": "text", "tinytext": "text", "mediumtext": "text", "longtext": "text",
+	"citext": "text", "uuid": "text", "enum": "text",
+	"blob": "bytes", "bytea": "bytes", "binary": "bytes", "varbinary": "bytes",
+	"tinyblob": "bytes", "mediumblob": "bytes", "longblob": "bytes",
+	"date": "time", "time": "time", "datetime": "time", "timestamp": "time", "timestamptz": "time",
+}
+
+// checks that the columns of rows returned for a query of a single
+// table are in it and their values compatible with the column types.
+// Columns may also be named by an alias or a function of the query
+func (s *Schema) checkRows(query string, rs driver.Rows) error {
+	r, ok := rs.(*rows)
+	ts := tokenize(query)
+	if !ok || len(ts) == 0 || !ts[0].is("SELECT") {
+		return nil
+	}
+	refs := references(ts)
+	if len(refs) != 1 || s.tables[refs[0].name] == nil || s.tables[refs[0].name].columns == nil {
+		return nil
+	}
+	table := s.tables[refs[0].name]
+
+	named := make(map[string]bool)
+	for i := 0; i+1 < len(ts); i++ {
+		switch {
+		case ts[i].is("AS") && ts[i+1].ident():
+			named[strings.ToLower(ts[i+1].text)] = true
+		case ts[i].ident() && ts[i+1].isPunct("("):
+			named[strings.ToLower(ts[i].text)] = true
+		}
+	}
+	for i, col := range r.cols {
+		column := strings.ToLower(col)
+		if named[column] {
+			continue
+		}
+		if !table.has(column) {
+			return fmt.Errorf("rows of query '%s' have column '%s', which is not in table '%s'", query, col, table.name)
+		}
+		category := typeCategories[table.types[column]]
+		for _, row := range r.rows {
+			if i < len(row) && !compatible(category, row[i]) {
+				return fmt.Errorf("rows of query '%s' have value %v of type %T in column '%s', which is not compatible with its type %s in table '%s'",
+					query, row[i], row[i], col, table.types[column], table.name)
+			}
+		}
+	}
+	return nil
+}
+
+// whether a value may be scanned from a column of the type category,
+// values of unknown categories, generated ones and scan errors are
+// always accepted. Raw bytes are parsed like a text protocol payload
+func compatible(category string, v driver.Value) bool {
+	switch b := v.(type) {
+	case nil, func() driver.Value, *scanError:
+		return true
+	case *rawBytes:
+		v = b.b
+	}
+	if b, ok := v.([]byte); ok {
+		switch category {
+		case "integer", "float", "bool":
+			v = string(b)
+		}
+	}
+	switch category {
+	case "integer":
+		if n, ok := number(v); ok {
+			return n.IsInt()
+		}
+		if s, ok := v.(string); ok {
+			_, err := strconv.ParseInt(s, 10, 64)
+			return err == nil
+		}
+		return false
+	case "float":
+		if _, ok := number(v); ok {
+			return true
+		}
+		_, ok := decimal(v)
+		return ok
+	case "bool":
+		switch v := v.(type) {
+		case bool:
+			return true
+		case int64:
+			return v == 0 || v == 1
+		case int:
+			return v == 0 || v == 1
+		case string:
+			_, err := strconv.ParseBool(v)
+			return err == nil
+		}
+		return false
+	case "text", "bytes":
+		switch v.(type) {
+		case string, []byte:
+			return true
+		}
+		return false
+	case "time":
+		switch v.(type) {
+		case time.Time, string, []byte:
+			return true
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,244 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testDDL = `
+-- users of the shop
+CREATE TABLE IF NOT EXISTS public.users (
+	id SERIAL PRIMARY KEY,
+	"Email" VARCHAR(255) NOT NULL CHECK (email <> ''),
+	name TEXT DEFAULT 'anonymous; really',
+	CONSTRAINT users_email_key UNIQUE (email)
+);
+CREATE TABLE orders (
+	id BIGINT,
+	user_id INT REFERENCES users (id),
+	total NUMERIC(10, 2),
+	PRIMARY KEY (id),
+	FOREIGN KEY (user_id) REFERENCES users (id)
+);
+CREATE INDEX orders_user_id ON orders (user_id);
+CREATE TABLE tmp (id INT);
+ALTER TABLE orders ADD COLUMN status TEXT, DROP COLUMN total, ADD CONSTRAINT positive CHECK (id > 0);
+ALTER TABLE users RENAME COLUMN name TO full_name;
+DROP TABLE IF EXISTS tmp;
+CREATE VIEW active_users AS SELECT * FROM users;
+CREATE FUNCTION touch() RETURNS trigger AS $$ BEGIN NEW.updated = now(); RETURN NEW; END; $$ LANGUAGE plpgsql;
+`
+
+func TestParseSchema(t *testing.T) {
+	schema, err := ParseSchema(testDDL)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while parsing the schema", err)
+	}
+
+	if tables := schema.Tables(); !reflect.DeepEqual(tables, []string{"users", "orders", "active_users"}) {
+		t.Errorf("expected tables users, orders and active_users, but got %v", tables)
+	}
+	if columns := schema.Columns("users"); !reflect.DeepEqual(columns, []string{"id", "email", "full_name"}) {
+		t.Errorf("expected columns of users to be id, email and full_name, but got %v", columns)
+	}
+	if columns := schema.Columns("ORDERS"); !reflect.DeepEqual(columns, []string{"id", "user_id", "status"}) {
+		t.Errorf("expected columns of orders to be id, user_id and status, but got %v", columns)
+	}
+	if columns := schema.Columns("active_users"); columns != nil {
+		t.Errorf("expected columns of the view to be unknown, but got %v", columns)
+	}
+
+	if _, err = ParseSchema("ALTER TABLE missing ADD COLUMN id INT"); err == nil {
+		t.Errorf("expected an error, since the altered table was not created")
+	}
+}
+
+func TestLoadSchemaFromMigrations(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrations")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while creating a directory", err)
+	}
+	defer os.RemoveAll(dir)
+
+	migrations := map[string]string{
+		"001_users.up.sql":   "CREATE TABLE users (id INT, name TEXT);",
+		"001_users.down.sql": "DROP TABLE users;",
+		"002_orders.sql":     "-- +goose Up\nCREATE TABLE orders (id INT);\n-- +goose Down\nDROP TABLE orders;\n",
+	}
+	for name, ddl := range migrations {
+		if err = ioutil.WriteFile(filepath.Join(dir, name), []byte(ddl), 0644); err != nil {
+			t.Fatalf("error '%s' was not expected while writing a migration", err)
+		}
+	}
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.sql"))
+	schema, err := LoadSchema(paths...)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while loading migrations", err)
+	}
+	if tables := schema.Tables(); !reflect.DeepEqual(tables, []string{"users", "orders"}) {
+		t.Errorf("expected down migrations to be skipped, but got tables %v", tables)
+	}
+}
+
+func TestSchemaCheck(t *testing.T) {
+	schema, err := ParseSchema(testDDL)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while parsing the schema", err)
+	}
+
+	valid := []string{
+		"SELECT u.id, o.status FROM users u JOIN orders AS o ON o.user_id = u.id WHERE u.email = ?",
+		"SELECT * FROM users, orders o WHERE orders.user_id = users.id",
+		"SELECT EXTRACT(YEAR FROM created) FROM public.users",
+		"SELECT * FROM (SELECT id FROM orders) AS sub WHERE sub.anything = 1",
+		"WITH recent AS (SELECT * FROM orders) SELECT recent.id FROM recent",
+		"SELECT * FROM generate_series(1, 10)",
+		"SELECT * FROM information_schema.tables",
+		"INSERT INTO orders (id, user_id, status) VALUES (?, ?, 'new') RETURNING id",
+		"UPDATE users SET email = ?, full_name = ? WHERE id = ?",
+		"SELECT id FROM users WHERE id = 1 FOR UPDATE",
+		"DELETE FROM orders WHERE status = 'from nowhere'",
+		"SELECT au.whatever FROM active_users au",
+		"CREATE TABLE anything (id INT)",
+	}
+	for _, query := range valid {
+		if err := schema.check(query); err != nil {
+			t.Errorf("error '%s' was not expected for a valid statement", err)
+		}
+	}
+
+	invalid := map[string]string{
+		"SELECT * FROM user": "table 'user'",
+		"SELECT * FROM users JOIN order_items ON id = item_id": "table 'order_items'",
+		"SELECT u.name FROM users u":                           "column 'name' of table 'users'",
+		"INSERT INTO orders (id, total) VALUES (?, ?)":         "column 'total' of table 'orders'",
+		"UPDATE users SET name = ? WHERE id = ?":               "column 'name' of table 'users'",
+		"DELETE FROM tmp":                                      "table 'tmp'",
+	}
+	for query, reference := range invalid {
+		err := schema.check(query)
+		if err == nil || !strings.Contains(err.Error(), "references "+reference+", which is not in the schema") {
+			t.Errorf("expected an error referencing %s for statement '%s', but got: %v", reference, query, err)
+		}
+	}
+}
+
+func TestRequireSchema(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	schema, err := ParseSchema("CREATE TABLE users (id INT, name TEXT)")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while parsing the schema", err)
+	}
+	RequireSchema(schema)
+	ExpectExec("UPDATE users").WillReturnRowsAffected(1)
+	ExpectExec("UPDATE users").WillReturnRowsAffected(1)
+
+	if _, err = db.Exec("UPDATE users SET name = ?", "gedi"); err != nil {
+		t.Errorf("error '%s' was not expected, the statement matches the schema", err)
+	}
+	if _, err = db.Exec("UPDATE users SET nmae = ?", "gedi"); err == nil {
+		t.Errorf("expected an error, since the column is not in the schema")
+	}
+
+	if err = db.Close(); err == nil || !strings.Contains(err.Error(), "column 'nmae' of table 'users'") {
+		t.Errorf("expected the violation to be reported on close, but got: %v", err)
+	}
+}
+
+func TestRowsCheckedAgainstSchema(t *testing.T) {
+	schema, err := ParseSchema(
+		"CREATE TABLE users (id INT, name VARCHAR(255), active BOOLEAN, created TIMESTAMP)",
+		"ALTER TABLE users RENAME COLUMN name TO username",
+	)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while parsing the schema", err)
+	}
+
+	cases := []struct {
+		query string
+		rows  Rows
+		err   string
+	}{
+		{"SELECT id, username FROM users", NewRows([]string{"id", "username"}).AddRow(1, "gedi"), ""},
+		{"SELECT COUNT(*), MAX(id) AS last FROM users", NewRows([]string{"count", "last"}).AddRow(2, 5), ""},
+		{"SELECT id, active, created FROM users", NewRows([]string{"id", "active", "created"}).AddRow("7", "t", "2016-01-02"), ""},
+		{"SELECT u.id, o.id FROM users u JOIN orders o ON o.user_id = u.id", NewRows([]string{"id", "total"}).AddRow(1, 2), ""},
+		{"SELECT id, name FROM users", NewRows([]string{"id", "name"}).AddRow(1, "gedi"), "column 'name', which is not in table 'users'"},
+		{"SELECT id FROM users", NewRows([]string{"id"}).AddRow(1).AddRow("one"), "value one of type string in column 'id'"},
+		{"SELECT active FROM users", NewRows([]string{"active"}).AddRow(int64(2)), "not compatible with its type boolean"},
+		{"SELECT username FROM users", NewRows([]string{"username"}).AddRow(3.5), "value 3.5 of type float64"},
+	}
+	for _, c := range cases {
+		err := schema.checkRows(c.query, c.rows.(driver.Rows))
+		if c.err == "" && err != nil {
+			t.Errorf("error '%s' was not expected for the rows of query '%s'", err, c.query)
+		}
+		if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("expected an error containing '%s' for the rows of query '%s', but got: %v", c.err, c.query, err)
+		}
+	}
+}
+
+func TestRequireSchemaChecksRows(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	schema, err := ParseSchema("CREATE TABLE users (id INT, email TEXT)")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while parsing the schema", err)
+	}
+	RequireSchema(schema)
+	ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"id", "emial"}).AddRow(1, "gedi@example.com"))
+
+	if _, err = db.Query("SELECT id, email FROM users"); err == nil {
+		t.Errorf("expected an error, since the rows have a column not in the schema")
+	}
+
+	if err = db.Close(); err == nil || !strings.Contains(err.Error(), "column 'emial'") {
+		t.Errorf("expected the violation to be reported on close, but got: %v", err)
+	}
+}
+
+func TestRequireSchemaAcceptsRawValues(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	schema, err := ParseSchema("CREATE TABLE users (id INT, email TEXT)")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while parsing the schema", err)
+	}
+	RequireSchema(schema)
+	rs := NewRows([]string{"id", "email"}).
+		AddRow([]byte("1"), "gedi@example.com").
+		AddRow(RawBytes([]byte("2"), "INT"), "gedi@example.com").
+		AddRow(ScanError(fmt.Errorf("corrupt")), "gedi@example.com")
+	ExpectQuery("SELECT (.+) FROM users").WillReturnRows(rs)
+
+	rows, err := db.Query("SELECT id, email FROM users")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected, since the values are compatible with the schema", err)
+	}
+	if err = rows.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing rows", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
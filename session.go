@@ -0,0 +1,258 @@
+package sqlmock
+
+import (
+	"fmt"
+	"time"
+)
+
+// ErrTooManyConns is returned by opening a connection beyond the
+// limit set by MaxConnsOption, unless another error was given
+var ErrTooManyConns = fmt.Errorf("too many connections")
+
+// a connection opened by database/sql. Every one of them
+// shares the expectations and settings of the mock
+type session struct {
+	*conn
+	bad    bool       // reported as bad, so it is discarded by database/sql
+	leased bool       // in use, since it was opened or taken from the pool
+	stmt   *statement // the driver call being made is made through, if any
+	opened time.Time
+	calls  int      // driver calls made on the connection
+	stack  []string // of the driver call being made, if stacks are recorded
+	pool   *pool    // the connection belongs to
+	faults *faults  // the connection fails by, if any
+	rows   int      // returned on the connection and not closed yet
+}
+
+// counts the rows opened or closed on the connection, a connection
+// with open rows is held by them, rather than leaked
+func (s *session) hold(rows int) {
+	held := s.rows > 0
+	s.rows += rows
+	switch {
+	case !held && s.rows > 0:
+		s.pool.held++
+	case held && s.rows == 0:
+		s.pool.held--
+	}
+}
+
+// a fault schedule, which makes driver calls
+// on a connection past the first ones fail
+type faults struct {
+	after int
+	err   error
+	calls int // made since the schedule was set
+}
+
+// the connections of a database opened on the mock, whose
+// expectations are verified once the last of them is closed,
+// unless they are verified as the database is closed
+type pool struct {
+	open     int
+	held     int  // by open rows
+	deferred bool // to closing the database
+	closed   bool // the database, so the expectations were verified
+}
+
+// opens a connection of the pool to the mock, or of the pool
+// shared by databases opened without a connector, if it is nil,
+// unless as many connections as allowed are open
+func (c *conn) open(p *pool) (*session, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.maxConns > 0 && c.openConns >= c.maxConns {
+		if c.maxConnsErr != nil {
+			return nil, c.maxConnsErr
+		}
+		return nil, ErrTooManyConns
+	}
+	if p == nil {
+		if c.shared == nil {
+			c.shared = &pool{}
+		}
+		p = c.shared
+	}
+	c.openConns++
+	p.open++
+	return &session{conn: c, leased: true, opened: time.Now(), pool: p}, nil
+}
+
+// whether the connection outlived the lifetime or the number of
+// calls connections are recycled after, if any. An expired connection
+// is reported as bad, so that database/sql opens a replacement
+func (s *session) expired() bool {
+	return s.connLifetime > 0 && time.Since(s.opened) >= s.connLifetime ||
+		s.connCalls > 0 && s.calls >= s.connCalls
+}
+
+// locks the mock for a driver call made on this connection
+func (s *session) lock() {
+	s.mu.Lock()
+	s.caller = s
+}
+
+// a dedicated connection, like the one of db.Conn,
+// which expectations may be scoped to
+type expectedConn struct {
+	commonExpectation
+	session *session // bound by the first expectation matched on it
+	pooled  bool     // returned to the pool between calls, never closed
+}
+
+func (e *expectedConn) String() string {
+	if e.pooled {
+		return "pooled connection"
+	}
+	return "dedicated connection"
+}
+
+// registers a dedicated connection, which is verified on its
+// own, since it is not matched by any driver call
+func (c *conn) expectConn(pooled bool) Mock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e := &expectedConn{pooled: pooled}
+	e.declared = callSite()
+	c.conns = append(c.conns, e)
+	return &mockedExpectation{c, e}
+}
+
+// the dedicated connection the expectation,
+// or one of its groups, is scoped to
+func dedicatedConn(e expectation) *expectedConn {
+	for {
+		common := e.common()
+		if common.conn != nil {
+			return common.conn
+		}
+		if common.group == nil {
+			return nil
+		}
+		e = common.group
+	}
+}
+
+// checks whether the expectation may be matched by the call
+// being handled, if it is scoped to a dedicated connection
+func (c *conn) onCaller(e expectation) bool {
+	dc := dedicatedConn(e)
+	return dc == nil || dc.session == nil || dc.session == c.caller
+}
+
+// binds the dedicated connection the expectation is scoped
+// to, if any, to the connection making the call
+func (c *conn) bind(e expectation) {
+	if dc := dedicatedConn(e); dc != nil && dc.session == nil {
+		dc.session = c.caller
+	}
+}
+
+// checks whether dedicated connections were used and closed
+func (c *conn) verifyConns() error {
+	for _, dc := range c.conns {
+		if dc.session == nil {
+			return fmt.Errorf("%s, declared at %s, was never used", dc, dc.declared)
+		}
+		if reportsReleasedConns && !dc.pooled && dc.session.leased {
+			return fmt.Errorf("%s, declared at %s, was never closed", dc, dc.declared)
+		}
+	}
+	return nil
+}
+
+// the part of context.Context a driver call may be canceled
+// with, declared here so that it is available before go1.7
+type canceler interface {
+	Done() <-chan struct{}
+	Err() error
+}
+
+// the part of context.Context which carries the deadline of a call
+type deadliner interface {
+	Deadline() (time.Time, bool)
+}
+
+// checks whether the context of the call has a deadline within the
+// duration the matched expectation requires, if it requires one. A
+// call made without a context has no deadline
+func checkDeadline(e expectation, ctx canceler, call string) error {
+	within := e.common().deadline
+	if within <= 0 {
+		return nil
+	}
+	var deadline time.Time
+	ok := false
+	if d, is := ctx.(deadliner); is {
+		deadline, ok = d.Deadline()
+	}
+	if !ok {
+		return fmt.Errorf("%s has no deadline, but one within %s was required", call, within)
+	}
+	if left := deadline.Sub(time.Now()); left > within {
+		return fmt.Errorf("%s has a deadline in %s, but one within %s was required", call, left, within)
+	}
+	return nil
+}
+
+// checks the context of the call with the check of the
+// matched expectation, if it has one. A call made without
+// a context fails the check
+func checkContext(e expectation, ctx canceler, call string) error {
+	check := e.common().ctxCheck
+	if check == nil {
+		return nil
+	}
+	c, ok := ctx.(Context)
+	if !ok {
+		return fmt.Errorf("%s has no context, but %s checks it", call, e)
+	}
+	if err := check(c); err != nil {
+		return fmt.Errorf("%s failed the context check of %s: %s", call, e, err)
+	}
+	return nil
+}
+
+// waits for the delay of the matched expectation without holding the
+// lock, unless the call is canceled by ctx first, which may be nil.
+// Calls which time out or block wait until ctx is done, the calls
+// returning early are counted as canceled
+func (s *session) delay(e expectation, ctx canceler) error {
+	common := e.common()
+	if common.delay <= 0 && !common.timeout && !common.blocking {
+		return nil
+	}
+	if (common.timeout || common.blocking) && (ctx == nil || ctx.Done() == nil) {
+		if common.blocking {
+			return fmt.Errorf("%s blocks until the call is canceled, but its context never is", e)
+		}
+		return errDeadlineExceeded // the context would never be done
+	}
+	s.mu.Unlock()
+	err := wait(common, ctx)
+	s.lock()
+	if err != nil {
+		common.canceled++
+	}
+	return err
+}
+
+// waits for the delay of the expectation, or until ctx is done
+func wait(e *commonExpectation, ctx canceler) error {
+	if e.timeout || e.blocking {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	if ctx == nil {
+		time.Sleep(e.delay)
+		return nil
+	}
+	timer := time.NewTimer(e.delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
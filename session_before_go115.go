@@ -0,0 +1,8 @@
+//go:build !go1.15
+// +build !go1.15
+
+package sqlmock
+
+// database/sql does not report connections returned
+// to the pool before Go 1.15
+const reportsReleasedConns = false
@@ -0,0 +1,8 @@
+//go:build go1.15
+// +build go1.15
+
+package sqlmock
+
+// database/sql validates connections returned to the pool
+// since Go 1.15, so the ones left in use are known
+const reportsReleasedConns = true
@@ -0,0 +1,182 @@
+//go:build go1.15
+// +build go1.15
+
+package sqlmock
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExpectationsScopedToDedicatedConnection(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	ctx := context.Background()
+
+	dc := ExpectConn()
+	ExpectExec("CREATE TEMPORARY TABLE tmp").OnConn(dc).WillReturnRowsAffected(0)
+	ExpectQuery("SELECT (.+) FROM users").WillReturnRow([]string{"id"}, 1)
+	ExpectQuery("SELECT (.+) FROM tmp").OnConn(dc).WillReturnRow([]string{"id"}, 2)
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Errorf("error '%s' was not expected while grabbing a dedicated connection", err)
+	}
+	if _, err = conn.ExecContext(ctx, "CREATE TEMPORARY TABLE tmp (id INT)"); err != nil {
+		t.Errorf("error '%s' was not expected while creating a temporary table", err)
+	}
+
+	var id int
+	if err = db.QueryRow("SELECT id FROM users").Scan(&id); err != nil {
+		t.Errorf("error '%s' was not expected while selecting on another connection", err)
+	}
+	if err = db.QueryRow("SELECT id FROM tmp").Scan(&id); err == nil {
+		t.Errorf("expected an error, since the temporary table is selected on another connection")
+	}
+	if err = conn.QueryRowContext(ctx, "SELECT id FROM tmp").Scan(&id); err != nil || id != 2 {
+		t.Errorf("expected to select from the temporary table on the dedicated connection, but got %d and error: %v", id, err)
+	}
+
+	if err = conn.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the dedicated connection", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestDedicatedConnectionMustBeClosed(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	ctx := context.Background()
+
+	dc := ExpectConn()
+	ExpectExec("SELECT pg_advisory_lock").OnConn(dc).WillReturnRowsAffected(0)
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Errorf("error '%s' was not expected while grabbing a dedicated connection", err)
+	}
+	if _, err = conn.ExecContext(ctx, "SELECT pg_advisory_lock(1)"); err != nil {
+		t.Errorf("error '%s' was not expected while locking", err)
+	}
+
+	err = ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "dedicated connection, declared at session_go115_test.go") || !strings.Contains(err.Error(), "was never closed") {
+		t.Errorf("expected an error, since the dedicated connection is still in use, but got: %v", err)
+	}
+
+	conn.Close()
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestDedicatedConnectionMustBeUsed(t *testing.T) {
+	db, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectConn()
+
+	if err = db.Close(); err == nil || !strings.Contains(err.Error(), "was never used") {
+		t.Errorf("expected an error, since the dedicated connection was never used, but got: %v", err)
+	}
+}
+
+func TestRecycleConns(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	RecycleConns(0, 2)
+	ExpectExec("UPDATE users").WillReturnRowsAffected(1)
+	ExpectExec("UPDATE users").WillReturnRowsAffected(1)
+
+	stmt, err := db.Prepare("UPDATE users SET name = ?")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err = stmt.Exec("gedi"); err != nil {
+			t.Errorf("error '%s' was not expected while updating", err)
+		}
+	}
+	// the connection expired after the prepare and the first exec,
+	// so the statement was prepared again on its replacement
+	if n := Stats().Prepares; n != 2 {
+		t.Errorf("expected the statement to be prepared on 2 connections, but it was on %d", n)
+	}
+	if err = stmt.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the statement", err)
+	}
+	if err = ExpectationsWereMet(); err != nil {
+		t.Errorf("expectations were not met, since discarding an expired connection does not reset them: %s", err)
+	}
+
+	RecycleConns(time.Millisecond, 0)
+	ExpectExec("UPDATE users").WillReturnRowsAffected(1)
+	ExpectExec("UPDATE users").WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE users SET name = ?", "gedi"); err != nil {
+		t.Errorf("error '%s' was not expected while updating", err)
+	}
+	time.Sleep(2 * time.Millisecond)
+	if _, err = db.Exec("UPDATE users SET name = ?", "gedi"); err != nil {
+		t.Errorf("error '%s' was not expected while updating on a replacement of the expired connection", err)
+	}
+
+	RecycleConns(0, 0)
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestIdleConnectionsClosedByThePoolKeepExpectations(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectExec("A").WillReturnRowsAffected(1)
+	m.ExpectExec("B").WillReturnRowsAffected(1)
+
+	ctx := context.Background()
+	var conns []*sql.Conn
+	for i := 0; i < 3; i++ {
+		conn, err := db.Conn(ctx)
+		if err != nil {
+			t.Fatalf("error '%s' was not expected while taking a connection", err)
+		}
+		conns = append(conns, conn)
+	}
+	if _, err = conns[0].ExecContext(ctx, "A"); err != nil {
+		t.Errorf("error '%s' was not expected while executing A", err)
+	}
+	// the pool keeps two idle connections and closes the third one
+	for _, conn := range conns {
+		if err = conn.Close(); err != nil {
+			t.Errorf("error '%s' was not expected while returning a connection", err)
+		}
+	}
+
+	if _, err = db.Exec("B"); err != nil {
+		t.Errorf("error '%s' was not expected, B is still expected", err)
+	}
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+
+	m.ExpectExec("C").WillReturnRowsAffected(1)
+	if err = db.Close(); err == nil || !strings.Contains(err.Error(), "exec 'C'") {
+		t.Errorf("expected closing the last connection to report the pending exec, but got: %v", err)
+	}
+}
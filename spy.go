@@ -0,0 +1,238 @@
+package sqlmock
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+)
+
+// Spy records the calls made on a database opened by NewSpy, which
+// are passed through to the wrapped driver, so that an integration
+// test may verify what SQL ran and with which args, without stubbing
+type Spy struct {
+	driver  driver.Driver
+	dsn     string
+	mu      sync.Mutex
+	history []RecordedCall
+}
+
+// routes connections to spies by their data source name
+type spyDriver struct {
+	mu    sync.Mutex
+	spies map[string]*Spy
+	seq   int
+}
+
+var spyDriverInstance = &spyDriver{spies: make(map[string]*Spy)}
+
+func init() {
+	sql.Register("sqlmock_spy", spyDriverInstance)
+}
+
+// opens a connection of the wrapped driver of the spy
+func (d *spyDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	s, ok := d.spies[name]
+	d.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("there is no spy with the data source name %s", name)
+	}
+	c, err := s.driver.Open(s.dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &spyConn{Conn: c, spy: s}, nil
+}
+
+// NewSpy opens a database of the driver with the data source name, like
+// sql.Open would, whose every call is passed through to the driver and
+// recorded by the returned spy. Pings and the opening of connections are
+// not recorded
+func NewSpy(d driver.Driver, dsn string) (*sql.DB, *Spy, error) {
+	s := &Spy{driver: d, dsn: dsn}
+	spyDriverInstance.mu.Lock()
+	spyDriverInstance.seq++
+	name := fmt.Sprintf("sqlmock_spy_%d", spyDriverInstance.seq)
+	spyDriverInstance.spies[name] = s
+	spyDriverInstance.mu.Unlock()
+
+	db, err := sql.Open("sqlmock_spy", name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, s, nil
+}
+
+// History lists the calls recorded by the spy, in the
+// order they were made, with their arguments and results
+func (s *Spy) History() []RecordedCall {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]RecordedCall(nil), s.history...)
+}
+
+// DumpHistory writes the History as indented JSON, like DumpHistory of a mock
+func (s *Spy) DumpHistory(w io.Writer) error {
+	data, err := json.MarshalIndent(s.History(), "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(append(data, '\n'))
+	return err
+}
+
+// Reset forgets the recorded calls
+func (s *Spy) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history = nil
+}
+
+// Executed checks whether an exec or a query, which matches the regex
+// and, if given, the args, was recorded. The args may be matched by an
+// Argument, like the ones of WithArgs. It returns an error listing the
+// recorded calls, if none matches
+func (s *Spy) Executed(sqlRegexStr string, args ...driver.Value) error {
+	re := regexp.MustCompile(sqlRegexStr)
+	history := s.History()
+next:
+	for _, call := range history {
+		if call.Call != "sql.exec" && call.Call != "sql.query" || !re.MatchString(call.Query) {
+			continue
+		}
+		if args != nil {
+			if len(args) != len(call.Args) {
+				continue
+			}
+			for i, arg := range args {
+				if !argMatches(arg, call.Args[i], comparison{numericValues: true}) {
+					continue next
+				}
+			}
+		}
+		return nil
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "no exec or query matching '%s'", re)
+	if args != nil {
+		fmt.Fprintf(&buf, " with args %+v", args)
+	}
+	buf.WriteString(" was made, the calls made are:")
+	for _, call := range history {
+		fmt.Fprintf(&buf, "\n  %s", call.Call)
+		if call.Query != "" {
+			fmt.Fprintf(&buf, " '%s' with args %+v", call.Query, call.Args)
+		}
+	}
+	return fmt.Errorf("%s", buf.String())
+}
+
+// records the call, the result is a *driver.Result
+// or *driver.Rows, if the call has one
+func (s *Spy) record(name, query string, args []driver.Value, result interface{}, err error) {
+	if err == driver.ErrSkip {
+		return // database/sql falls back to another call
+	}
+	call := recordedCall(name, query, args, result, err)
+	s.mu.Lock()
+	s.history = append(s.history, call)
+	s.mu.Unlock()
+}
+
+// a connection of the wrapped driver
+type spyConn struct {
+	driver.Conn
+	spy *Spy
+}
+
+func (c *spyConn) Prepare(query string) (driver.Stmt, error) {
+	stmt, err := c.Conn.Prepare(query)
+	c.spy.record("sql.prepare", query, nil, nil, err)
+	if err != nil {
+		return nil, err
+	}
+	return &spyStmt{Stmt: stmt, spy: c.spy, query: query}, nil
+}
+
+func (c *spyConn) Begin() (driver.Tx, error) {
+	tx, err := c.Conn.Begin()
+	c.spy.record("sql.begin", "", nil, nil, err)
+	if err != nil {
+		return nil, err
+	}
+	return &spyTx{Tx: tx, spy: c.spy}, nil
+}
+
+// Exec implements driver.Execer, if the wrapped connection does
+func (c *spyConn) Exec(query string, args []driver.Value) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.Execer)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	res, err := execer.Exec(query, args)
+	c.spy.record("sql.exec", query, args, &res, err)
+	return res, err
+}
+
+// Query implements driver.Queryer, if the wrapped connection does
+func (c *spyConn) Query(query string, args []driver.Value) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.Queryer)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	rs, err := queryer.Query(query, args)
+	c.spy.record("sql.query", query, args, &rs, err)
+	return rs, err
+}
+
+// a statement prepared by the wrapped driver
+type spyStmt struct {
+	driver.Stmt
+	spy   *Spy
+	query string
+}
+
+func (stmt *spyStmt) Exec(args []driver.Value) (driver.Result, error) {
+	res, err := stmt.Stmt.Exec(args)
+	stmt.spy.record("sql.exec", stmt.query, args, &res, err)
+	return res, err
+}
+
+func (stmt *spyStmt) Query(args []driver.Value) (driver.Rows, error) {
+	rs, err := stmt.Stmt.Query(args)
+	stmt.spy.record("sql.query", stmt.query, args, &rs, err)
+	return rs, err
+}
+
+// ColumnConverter implements driver.ColumnConverter,
+// args are converted like the wrapped statement does
+func (stmt *spyStmt) ColumnConverter(idx int) driver.ValueConverter {
+	if cc, ok := stmt.Stmt.(driver.ColumnConverter); ok {
+		return cc.ColumnConverter(idx)
+	}
+	return driver.DefaultParameterConverter
+}
+
+// a transaction of the wrapped driver
+type spyTx struct {
+	driver.Tx
+	spy *Spy
+}
+
+func (tx *spyTx) Commit() error {
+	err := tx.Tx.Commit()
+	tx.spy.record("sql.commit", "", nil, nil, err)
+	return err
+}
+
+func (tx *spyTx) Rollback() error {
+	err := tx.Tx.Rollback()
+	tx.spy.record("sql.rollback", "", nil, nil, err)
+	return err
+}
@@ -0,0 +1,90 @@
+//go:build go1.8
+// +build go1.8
+
+package sqlmock
+
+import (
+	"context"
+	"database/sql/driver"
+)
+
+// BeginTx implements driver.ConnBeginTx, options are
+// passed only if the wrapped connection supports them
+func (c *spyConn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	b, ok := c.Conn.(driver.ConnBeginTx)
+	if !ok {
+		return c.Begin()
+	}
+	tx, err := b.BeginTx(ctx, opts)
+	c.spy.record("sql.begin", "", nil, nil, err)
+	if err != nil {
+		return nil, err
+	}
+	return &spyTx{Tx: tx, spy: c.spy}, nil
+}
+
+// PrepareContext implements driver.ConnPrepareContext
+func (c *spyConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	p, ok := c.Conn.(driver.ConnPrepareContext)
+	if !ok {
+		return c.Prepare(query)
+	}
+	stmt, err := p.PrepareContext(ctx, query)
+	c.spy.record("sql.prepare", query, nil, nil, err)
+	if err != nil {
+		return nil, err
+	}
+	return &spyStmt{Stmt: stmt, spy: c.spy, query: query}, nil
+}
+
+// ExecContext implements driver.ExecerContext
+func (c *spyConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return c.Exec(query, values(args))
+	}
+	res, err := execer.ExecContext(ctx, query, args)
+	c.spy.record("sql.exec", query, values(args), &res, err)
+	return res, err
+}
+
+// QueryContext implements driver.QueryerContext
+func (c *spyConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return c.Query(query, values(args))
+	}
+	rs, err := queryer.QueryContext(ctx, query, args)
+	c.spy.record("sql.query", query, values(args), &rs, err)
+	return rs, err
+}
+
+// Ping implements driver.Pinger, if the wrapped connection does
+func (c *spyConn) Ping(ctx context.Context) error {
+	if p, ok := c.Conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+// ExecContext implements driver.StmtExecContext
+func (stmt *spyStmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := stmt.Stmt.(driver.StmtExecContext)
+	if !ok {
+		return stmt.Exec(values(args))
+	}
+	res, err := execer.ExecContext(ctx, args)
+	stmt.spy.record("sql.exec", stmt.query, values(args), &res, err)
+	return res, err
+}
+
+// QueryContext implements driver.StmtQueryContext
+func (stmt *spyStmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := stmt.Stmt.(driver.StmtQueryContext)
+	if !ok {
+		return stmt.Query(values(args))
+	}
+	rs, err := queryer.QueryContext(ctx, args)
+	stmt.spy.record("sql.query", stmt.query, values(args), &rs, err)
+	return rs, err
+}
@@ -0,0 +1,26 @@
+//go:build go1.9
+// +build go1.9
+
+package sqlmock
+
+import (
+	"database/sql/driver"
+)
+
+// CheckNamedValue implements driver.NamedValueChecker,
+// args are checked like the wrapped connection does
+func (c *spyConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := c.Conn.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+// CheckNamedValue implements driver.NamedValueChecker,
+// args are checked like the wrapped statement does
+func (stmt *spyStmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if checker, ok := stmt.Stmt.(driver.NamedValueChecker); ok {
+		return checker.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
@@ -0,0 +1,73 @@
+package sqlmock
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSpy(t *testing.T) {
+	// the spy wraps another mock, which stands in for a real database
+	_, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	m.ExpectBegin()
+	m.ExpectExec("INSERT INTO users").WillReturnResult(NewResult(1, 1))
+	m.ExpectCommit()
+	m.ExpectQuery("SELECT name FROM users").WillReturnRow([]string{"name"}, "gedi")
+
+	db, spy, err := NewSpy(driverInstance, m.(*MockDB).dsn)
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while opening a spied database", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while beginning a transaction", err)
+	}
+	if _, err = tx.Exec("INSERT INTO users (name) VALUES (?)", "gedi"); err != nil {
+		t.Errorf("error '%s' was not expected while inserting", err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while committing", err)
+	}
+	var name string
+	if err = db.QueryRow("SELECT name FROM users WHERE id = ?", 1).Scan(&name); err != nil || name != "gedi" {
+		t.Errorf("expected the row of the wrapped driver, but got %s and error: %v", name, err)
+	}
+
+	if err = spy.Executed("^INSERT INTO users", "gedi"); err != nil {
+		t.Errorf("expected the insert to be recorded: %s", err)
+	}
+	if err = spy.Executed("^SELECT name FROM users", 1); err != nil {
+		t.Errorf("expected the query to be recorded: %s", err)
+	}
+	err = spy.Executed("^DELETE FROM users")
+	if err == nil || !strings.Contains(err.Error(), "sql.exec 'INSERT INTO users (name) VALUES (?)' with args [gedi]") {
+		t.Errorf("expected an error listing the calls, since no delete was made, but got: %v", err)
+	}
+
+	calls := spy.History()
+	expected := []string{"sql.begin", "sql.exec", "sql.commit", "sql.query"}
+	if len(calls) != len(expected) {
+		t.Fatalf("expected %d recorded calls, but got %+v", len(expected), calls)
+	}
+	for i, call := range calls {
+		if call.Call != expected[i] {
+			t.Errorf("expected call %d to be %s, but got %s", i, expected[i], call.Call)
+		}
+	}
+	var buf bytes.Buffer
+	if err = spy.DumpHistory(&buf); err != nil || !strings.Contains(buf.String(), `"call": "sql.commit"`) {
+		t.Errorf("expected the history to be dumped, but got %s and error: %v", buf.String(), err)
+	}
+	spy.Reset()
+	if calls = spy.History(); len(calls) != 0 {
+		t.Errorf("expected no calls after reset, but got %+v", calls)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
@@ -0,0 +1,176 @@
+package sqlmock
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SQLFormat defines how queries and expected regular
+// expressions are printed in failure messages
+type SQLFormat int
+
+const (
+	// PlainSQL prints queries quoted, on a single line
+	PlainSQL SQLFormat = iota
+	// PrettySQL prints queries indented on their own lines, broken
+	// before every clause and wrapped at commas when they are long,
+	// so that long queries generated by ORMs remain readable
+	PrettySQL
+	// HighlightedSQL prints queries like PrettySQL does, with clause
+	// keywords highlighted by ANSI escape codes for terminals
+	HighlightedSQL
+)
+
+const (
+	sqlIndent    = "    "
+	sqlWrapWidth = 80
+)
+
+var sqlClause = regexp.MustCompile(`(?i)\b(?:(?:(?:LEFT|RIGHT|FULL|INNER|OUTER|CROSS)\s+)*JOIN|SELECT|FROM|WHERE|GROUP\s+BY|ORDER\s+BY|HAVING|LIMIT|OFFSET|UNION(?:\s+ALL)?|INSERT\s+INTO|VALUES|UPDATE|SET|DELETE\s+FROM|RETURNING|ON\s+CONFLICT)\b`)
+
+// formats the query or regex for a failure message
+func (f SQLFormat) format(query string) string {
+	if f == PlainSQL {
+		return "'" + query + "'"
+	}
+
+	var b bytes.Buffer
+	b.WriteString("\n")
+	for _, clause := range clauses(query) {
+		for i, line := range wrap(clause, sqlWrapWidth) {
+			b.WriteString(sqlIndent)
+			if i > 0 {
+				b.WriteString(sqlIndent) // continued
+			} else if f == HighlightedSQL {
+				line = highlight(line)
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// formats the query or regex for a failure message
+func (c *conn) sql(query string) string {
+	return c.format.format(query)
+}
+
+// splits the query before every clause keyword, which is not quoted
+func clauses(query string) (parts []string) {
+	start := 0
+	for _, loc := range sqlClause.FindAllStringIndex(query, -1) {
+		if loc[0] == 0 || strings.Count(query[:loc[0]], "'")%2 != 0 {
+			continue
+		}
+		parts = append(parts, strings.TrimSpace(query[start:loc[0]]))
+		start = loc[0]
+	}
+	return append(parts, strings.TrimSpace(query[start:]))
+}
+
+// breaks the line after commas, so that every part fits the width if it can
+func wrap(line string, width int) (lines []string) {
+	for len(line) > width {
+		i := strings.LastIndex(line[:width], ", ")
+		if i < 0 {
+			i = strings.Index(line, ", ")
+		}
+		if i < 0 {
+			break
+		}
+		lines = append(lines, line[:i+1])
+		line = line[i+2:]
+	}
+	return append(lines, line)
+}
+
+// highlights the clause keyword the line starts with
+func highlight(line string) string {
+	loc := sqlClause.FindStringIndex(line)
+	if loc == nil || loc[0] != 0 {
+		return line
+	}
+	return "\x1b[1m" + line[:loc[1]] + "\x1b[0m" + line[loc[1]:]
+}
+
+// previews the query with args interpolated for a failure message, it is
+// empty if there are no args or placeholders to interpolate them at, or
+// if the output is terse
+func (c *conn) preview(query string, args []driver.Value) string {
+	if len(args) == 0 || c.verbosity == TerseOutput {
+		return ""
+	}
+	interpolated, ok := interpolate(query, args)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf(" (previewed with args interpolated as %s)", c.sql(interpolated))
+}
+
+// substitutes args for the ?, $n and :n placeholders of the query, which
+// are not quoted. Named placeholders are kept, since args have no names.
+// Reports whether any placeholder was substituted
+func interpolate(q string, args []driver.Value) (string, bool) {
+	var b bytes.Buffer
+	next, substituted := 0, false
+	literal := func(n int) bool {
+		if n < 0 || n >= len(args) {
+			return false
+		}
+		b.WriteString(sqlLiteral(args[n]))
+		substituted = true
+		return true
+	}
+	for i := 0; i < len(q); i++ {
+		switch c := q[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			j := skipQuoted(q, i, c)
+			if j == len(q) {
+				j--
+			}
+			b.WriteString(q[i : j+1])
+			i = j
+			continue
+		case c == '?':
+			if next++; literal(next - 1) {
+				continue
+			}
+		case (c == '$' || c == ':') && i+1 < len(q) && isDigit(q[i+1]) && (i == 0 || q[i-1] != ':'):
+			n, j := 0, i+1
+			for ; j < len(q) && isDigit(q[j]); j++ {
+				n = n*10 + int(q[j]-'0')
+			}
+			if literal(n - 1) {
+				i = j - 1
+				continue
+			}
+		}
+		b.WriteByte(q[i])
+	}
+	return b.String(), substituted
+}
+
+// the value written as an SQL literal
+func sqlLiteral(v driver.Value) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return "'" + strings.Replace(v, "'", "''", -1) + "'"
+	case []byte:
+		return "'" + strings.Replace(string(v), "'", "''", -1) + "'"
+	case time.Time:
+		return "'" + v.Format(time.RFC3339Nano) + "'"
+	case bool:
+		if v {
+			return "TRUE"
+		}
+		return "FALSE"
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,125 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+)
+
+func TestPrettySQLFormat(t *testing.T) {
+	query := "SELECT id, title FROM articles AS a INNER JOIN users AS u ON a.user_id = u.id WHERE a.title = 'select from' AND u.id = ? ORDER BY a.id"
+	expected := `
+    SELECT id, title
+    FROM articles AS a
+    INNER JOIN users AS u ON a.user_id = u.id
+    WHERE a.title = 'select from' AND u.id = ?
+    ORDER BY a.id
+`
+	if s := PrettySQL.format(query); s != expected {
+		t.Errorf("expected the query to be broken before clauses, but got:%s", s)
+	}
+
+	if s := PlainSQL.format(query); s != "'"+query+"'" {
+		t.Errorf("expected the query to be quoted, but got: %s", s)
+	}
+
+	if s := HighlightedSQL.format("SELECT id FROM articles"); !strings.Contains(s, "\x1b[1mFROM\x1b[0m articles") {
+		t.Errorf("expected clause keywords to be highlighted, but got: %q", s)
+	}
+}
+
+func TestPrettySQLWrapsLongClauses(t *testing.T) {
+	query := "SELECT " + strings.Repeat("articles.column_name, ", 8) + "articles.id FROM articles"
+	lines := strings.Split(strings.TrimSpace(PrettySQL.format(query)), "\n")
+	if len(lines) < 3 {
+		t.Fatalf("expected the long select clause to be wrapped, but got %q", lines)
+	}
+	for _, line := range lines {
+		if len(line) > sqlWrapWidth+2*len(sqlIndent) {
+			t.Errorf("expected the line to be wrapped, but got: %s", line)
+		}
+	}
+	if !strings.HasPrefix(lines[1], sqlIndent+sqlIndent+"articles.column_name") {
+		t.Errorf("expected a wrapped line to be indented further, but got: %q", lines[1])
+	}
+}
+
+func TestPrettySQLInFailureMessages(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	m.SetSQLFormat(PrettySQL)
+	m.ExpectExec("UPDATE users SET name").WillReturnRowsAffected(1)
+
+	_, err = db.Exec("UPDATE articles SET title = ? WHERE id = ?", "hello", 1)
+	if err == nil {
+		t.Fatal("expected an error for an exec, which does not match the expectation")
+	}
+	expected := `exec query 
+    UPDATE articles
+    SET title = ?
+    WHERE id = ?
+, does not match regex 
+    UPDATE users
+    SET name
+`
+	if err.Error() != expected {
+		t.Errorf("expected both queries to be pretty printed, but got:\n%s", err)
+	}
+	m.Reset()
+	db.Close()
+}
+
+func TestInterpolatedPreview(t *testing.T) {
+	cases := []struct {
+		query    string
+		args     []driver.Value
+		expected string
+	}{
+		{"SELECT * FROM users WHERE name = ? AND age > ?", []driver.Value{"O'Brien", int64(42)}, "SELECT * FROM users WHERE name = 'O''Brien' AND age > 42"},
+		{"UPDATE users SET avatar = $2, active = $3 WHERE id = $1", []driver.Value{int64(7), []byte("x"), true}, "UPDATE users SET avatar = 'x', active = TRUE WHERE id = 7"},
+		{"SELECT '?', x::int FROM t WHERE a = :1 AND b = :2", []driver.Value{nil, 1.5}, "SELECT '?', x::int FROM t WHERE a = NULL AND b = 1.5"},
+		{"SELECT * FROM t WHERE a = ? AND b = ?", []driver.Value{int64(1)}, "SELECT * FROM t WHERE a = 1 AND b = ?"},
+	}
+	for _, c := range cases {
+		if interpolated, _ := interpolate(c.query, c.args); interpolated != c.expected {
+			t.Errorf("expected query '%s' to be interpolated as '%s', but got '%s'", c.query, c.expected, interpolated)
+		}
+	}
+
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	m.ExpectQuery("SELECT (.+) FROM users").WithArgs(1).WillReturnRow([]string{"id"}, 1)
+
+	_, err = db.Query("SELECT id FROM users WHERE id = ? AND org = ?", 3, 42)
+	if err == nil || !strings.Contains(err.Error(), "(previewed with args interpolated as 'SELECT id FROM users WHERE id = 3 AND org = 42')") {
+		t.Errorf("expected the error to preview the query with args interpolated, but got: %v", err)
+	}
+	m.Reset()
+	db.Close()
+}
+
+func TestPreviewOfTheOriginalQuery(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	m.SetQueryMatching(IgnoreCase)
+	m.ExpectExec("update articles set title").WithArgs("gedi", 1).WillReturnRowsAffected(1)
+
+	_, err = db.Exec("UPDATE Articles SET Title = ? WHERE ID = ?", "hello", 1)
+	if err == nil {
+		t.Fatal("expected an error for an exec, which does not match the expectation")
+	}
+	if !strings.Contains(err.Error(), "'UPDATE Articles SET Title = ? WHERE ID = ?'") {
+		t.Errorf("expected the error to show the query as it was executed, but got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "(previewed with args interpolated as 'UPDATE Articles SET Title = 'hello' WHERE ID = 1')") {
+		t.Errorf("expected the error to preview the query as it was executed, but got: %v", err)
+	}
+	m.Reset()
+	db.Close()
+}
@@ -57,140 +57,810 @@ import (
 	"database/sql"
 	"database/sql/driver"
 	"fmt"
-	"regexp"
+	"time"
 )
 
-var mock *mockDriver
+// the mock package level functions operate on,
+// connections opened by sql.Open("mock", "") use it
+var mock *MockDB
 
 // Mock interface defines a mock which is returned
 // by any expectation and can be detailed further
 // with the methods this interface provides
 type Mock interface {
 	WithArgs(...driver.Value) Mock
+	WithArgAt(int, driver.Value) Mock
 	WillReturnError(error) Mock
+	WillDelayFor(time.Duration) Mock
+	WillTimeout() Mock
+	WillBlockUntilCanceled() Mock
+	RequireDeadlineWithin(time.Duration) Mock
+	WithContextCheck(func(Context) error) Mock
+	Times(int) Mock
+	ThenReturnRows(driver.Rows) Mock
+	ThenReturnResult(driver.Result) Mock
+	ThenReturnError(error) Mock
 	WillReturnRows(driver.Rows) Mock
+	WillReturnRow([]string, ...driver.Value) Mock
 	WillReturnResult(driver.Result) Mock
+	WillReturnRowsAffected(int64) Mock
+	WillReturnOutParams(...driver.Value) Mock
+	WithCopyRow(...driver.Value) Mock
+	After(...Mock) Mock
+	MustBeClosed() Mock
+	MustBeFullyRead() Mock
+	Unanchored() Mock
+	OnConn(Mock) Mock
+	InTransaction() Mock
+	MustReuseStatement() Mock
+	ThroughStatement(Mock) Mock
+	WithConflictTarget(...string) Mock
+	WithUpdateSet(...string) Mock
 }
 
-type mockDriver struct {
+// mock of a single expectation, returned
+// when the expectation is declared
+type mockedExpectation struct {
 	conn *conn
-}
-
-func (d *mockDriver) Open(dsn string) (driver.Conn, error) {
-	return mock.conn, nil
+	e    expectation
 }
 
 func init() {
-	mock = &mockDriver{&conn{}}
-	sql.Register("mock", mock)
+	mock = &MockDB{conn: &conn{placeholders: AnyPlaceholders}}
+	sql.Register("mock", driverInstance)
 }
 
 // New creates sqlmock database connection
 // and pings it so that all expectations could be
-// asserted on Close.
-func New() (db *sql.DB, err error) {
+// asserted on Close. The options configure the package
+// level mock, like the matching of queries or the order
+// of expectations, every other setting is reset to its
+// default. When pings are monitored the database is not pinged
+func New(opts ...Option) (db *sql.DB, err error) {
+	mock.configure(opts)
 	db, err = sql.Open("mock", "")
 	if err != nil {
 		return
 	}
-	// ensure open connection, otherwise Close does not assert expectations
-	db.Ping()
+	if !mock.conn.monitoringPings() {
+		// ensure open connection, otherwise Close does not assert expectations
+		db.Ping()
+	}
 	return
 }
 
+// SetPlaceholderStyle sets which placeholders are counted in prepared
+// statements, so that database/sql validates the number of arguments
+// like a real driver would. By default ?, $n and :name placeholders are
+// counted, PermissivePlaceholders allows any number of arguments
+func SetPlaceholderStyle(style PlaceholderStyle) {
+	mock.SetPlaceholderStyle(style)
+}
+
+// SetQueryMatching sets how queries and expected regular expressions
+// are normalized before they are matched, so that one expectation
+// works for queries generated for MySQL, Postgres or SQL Server, for
+// example with IgnoreIdentifierQuotes|IgnoreSchemaPrefixes
+func SetQueryMatching(m QueryMatching) {
+	mock.SetQueryMatching(m)
+}
+
+// SetSQLFormat sets how queries and expected regular expressions
+// are printed in failure messages. PrettySQL breaks long queries,
+// generated by ORMs, into indented lines so that they are readable
+func SetSQLFormat(format SQLFormat) {
+	mock.SetSQLFormat(format)
+}
+
+// Reset clears all remaining expectations, policies and their
+// violations without asserting them, as closing the connection
+// would. Allows to run several scenarios on the same database
+func Reset() {
+	mock.Reset()
+}
+
 // ExpectBegin expects transaction to be started
 func ExpectBegin() Mock {
-	e := &expectedBegin{}
-	mock.conn.expectations = append(mock.conn.expectations, e)
-	mock.conn.active = e
-	return mock.conn
+	return mock.ExpectBegin()
 }
 
 // ExpectCommit expects transaction to be commited
 func ExpectCommit() Mock {
-	e := &expectedCommit{}
-	mock.conn.expectations = append(mock.conn.expectations, e)
-	mock.conn.active = e
-	return mock.conn
+	return mock.ExpectCommit()
 }
 
 // ExpectRollback expects transaction to be rolled back
 func ExpectRollback() Mock {
-	e := &expectedRollback{}
-	mock.conn.expectations = append(mock.conn.expectations, e)
-	mock.conn.active = e
-	return mock.conn
+	return mock.ExpectRollback()
+}
+
+// ExpectRetriedTransaction expects a transaction to fail with a
+// retryable err, like a deadlock or serialization failure, the given
+// number of times and then to succeed when retried. The body declares
+// the expectations of the statements inside the transaction and is
+// called once for every attempt. In a failing attempt the last statement
+// of the body returns err and a rollback is expected, if the body is
+// empty the commit returns err. Returns the final commit expectation
+func ExpectRetriedTransaction(failures int, err error, body func()) Mock {
+	return mock.ExpectRetriedTransaction(failures, err, body)
 }
 
 // ExpectPrepare expects Query to be prepared
 func ExpectPrepare() Mock {
-	e := &expectedPrepare{}
-	mock.conn.expectations = append(mock.conn.expectations, e)
-	mock.conn.active = e
-	return mock.conn
+	return mock.ExpectPrepare()
+}
+
+// ExpectTxPrepare expects a statement to be prepared within a transaction,
+// on its connection, like tx.Prepare does or tx.Stmt, when it binds a
+// statement prepared outside of the transaction to it and database/sql
+// prepares it anew on the connection of the transaction. A statement
+// prepared outside of a transaction fails it. ExpectPrepare matches
+// statements prepared either way
+func ExpectTxPrepare() Mock {
+	return mock.ExpectTxPrepare()
+}
+
+// ExpectBadConnThenRecover expects the next driver call, whichever it
+// is, to fail with driver.ErrBadConn. database/sql then discards the
+// connection and retries the call on a fresh one, which is matched
+// against the expectations declared afterwards. Allows to test
+// connection retry logic deterministically
+func ExpectBadConnThenRecover() Mock {
+	return mock.ExpectBadConnThenRecover()
+}
+
+// ExpectResetSession expects database/sql to reset the session of
+// a pooled connection before it is reused, which happens since go1.10.
+// Returning driver.ErrBadConn makes database/sql discard the connection.
+// Session resets are ignored unless expected
+func ExpectResetSession() Mock {
+	return mock.ExpectResetSession()
+}
+
+// ExpectPing expects the connection to be pinged, which
+// is matched only by a mock created with MonitorPingsOption
+func ExpectPing() Mock {
+	return mock.ExpectPing()
+}
+
+// InvalidateConn marks the mock connection as no longer valid, so
+// database/sql discards it instead of returning it to the pool,
+// which happens since go1.15. Expectations remain as they are
+func InvalidateConn() {
+	mock.InvalidateConn()
+}
+
+// FailAfter makes every driver call on the connection, which
+// makes the next call, past the first n ones fail with err,
+// regardless of expectations, until that connection is closed.
+// Other connections and the ones opened to replace it keep working.
+// Allows to simulate a connection which breaks in the middle of a
+// long lived service
+func FailAfter(n int, err error) {
+	mock.FailAfter(n, err)
+}
+
+// Chaos makes the given fraction of driver calls, which matched
+// an expectation, fail with one of the given errors picked at random.
+// The seed makes failures reproducible. When no errors are given,
+// ErrChaos is returned. A zero fraction turns the chaos mode off
+func Chaos(seed int64, fraction float64, errs ...error) {
+	mock.Chaos(seed, fraction, errs...)
+}
+
+// Latency delays every driver call by base plus a random jitter
+// up to the given duration, without annotating each expectation.
+// Allows to test code under realistic slow database conditions
+func Latency(base, jitter time.Duration) {
+	mock.Latency(base, jitter)
+}
+
+// RecycleConns expires every connection once it is older than the
+// lifetime or made the given number of driver calls, like the
+// ConnMaxLifetime of database/sql or a database closing sessions
+// does. Zero disables either. An expired connection is discarded
+// as it is returned to or taken from the pool, since go1.15 and
+// go1.10 respectively, and database/sql opens a replacement. So that
+// code sensitive to the loss of session state, like temporary tables
+// or session variables, may be tested across connection recycling
+func RecycleConns(lifetime time.Duration, calls int) {
+	mock.RecycleConns(lifetime, calls)
+}
+
+// Permissive makes every Exec succeed with the given result and every
+// Query return the given rows, while transactions and statements just
+// work, without matching any expectations or recording the calls in
+// the History. Allows to load test or benchmark the code around the
+// database, like serialization, pooling or goroutine fan-out, without
+// declaring thousands of expectations. Rows should support being returned many times, like
+// the ones of NewRows do. Nil values return an empty result and rows.
+// Persists until turned off with StopPermissive
+func Permissive(result driver.Result, rows driver.Rows) {
+	mock.Permissive(result, rows)
+}
+
+// StopPermissive turns the permissive mode off,
+// so that expectations are matched again
+func StopPermissive() {
+	mock.StopPermissive()
+}
+
+// RequireReadOnly makes every statement which writes to the database,
+// like INSERT, UPDATE, DELETE or DDL, fail regardless of expectations,
+// until the connection is closed. Closing the connection reports the
+// violation, even if the code under test ignored the error
+func RequireReadOnly() {
+	mock.RequireReadOnly()
+}
+
+// ForbidQueries makes every statement matching any of the given
+// regular expressions fail regardless of expectations, until the
+// connection is closed. Closing the connection reports the violation,
+// even if the code under test ignored the error
+func ForbidQueries(sqlRegexStrs ...string) {
+	mock.ForbidQueries(sqlRegexStrs...)
+}
+
+// AllowOnly makes every statement, which does not match any of the
+// given regular expressions, fail regardless of expectations, until
+// the connection is closed. Closing the connection reports the violation,
+// even if the code under test ignored the error
+func AllowOnly(sqlRegexStrs ...string) {
+	mock.AllowOnly(sqlRegexStrs...)
+}
+
+// RequireSchema makes every statement, which references a table or
+// a column not in the schema, fail regardless of expectations, until
+// the connection is closed. So that typos and drift between the code
+// and its migrations are caught without a database. Closing the
+// connection reports the violation, even if the code under test
+// ignored the error
+func RequireSchema(schema *Schema) {
+	mock.RequireSchema(schema)
+}
+
+// RequireValidSQL makes every statement, which is not valid SQL of the
+// dialect, fail regardless of expectations, until the connection is
+// closed. So that broken queries built of strings are caught, even if
+// the regex of their expectation still matches. Statements are checked
+// by a lightweight parser, rather than the grammar of the database, for
+// unterminated quotes and comments, unbalanced parentheses, dangling
+// commas and conditions, misspelled statements, inserted values not
+// matching the columns and constructs the dialect does not support
+func RequireValidSQL(dialect SQLDialect) {
+	mock.RequireValidSQL(dialect)
+}
+
+// RequireExactTimeLocation makes time arguments match the expected
+// ones only if they are in the same location, until the connection
+// is closed. By default the same instant matches in any location
+func RequireExactTimeLocation() {
+	mock.RequireExactTimeLocation()
+}
+
+// RequireExactArgTypes makes arguments match the expected values only
+// if they have the same type, until the connection is closed. So an int
+// no longer matches an int64, nor a string []byte, which surfaces type
+// mismatches real drivers care about. Note that database/sql converts
+// integers to int64 and floats to float64 before the driver sees them.
+// Argument matchers and decimal values are compared as usual
+func RequireExactArgTypes() {
+	mock.RequireExactArgTypes()
+}
+
+// MatchNumbersByValue makes numeric arguments match the expected
+// numbers if they are equal, whatever integer or floating point type
+// either of them has, until the connection is closed. So an expected
+// 5 matches int64(5) or float64(5) database/sql sends, while 5.5 no
+// longer fails to be compared with an integer
+func MatchNumbersByValue() {
+	mock.MatchNumbersByValue()
+}
+
+// RequireStatementsClosed makes verification fail, unless every
+// statement prepared until the connection is closed was closed
+// as well. database/sql closes statements left open when the
+// database is closed, so verify it with ExpectationsWereMet before
+func RequireStatementsClosed() {
+	mock.RequireStatementsClosed()
+}
+
+// AssertNoMoreInteractions makes every driver call from this point
+// on fail, until the connection is closed. Closing the connection
+// reports all of these calls with their SQL and arguments. Allows to
+// pin down where in a flow the database access is supposed to stop
+func AssertNoMoreInteractions() {
+	mock.AssertNoMoreInteractions()
+}
+
+// MatchExpectationsInOrder sets whether expectations must be matched
+// in the order they were declared, which they must by default. In
+// unordered mode every driver call matches the first unfulfilled
+// expectation, which accepts it. Partial order may be still required
+// with After. The mode remains set when the connection is closed
+func MatchExpectationsInOrder(ordered bool) {
+	mock.MatchExpectationsInOrder(ordered)
+}
+
+// InOrder groups the given expectations, which must be matched in
+// the given order. The group may be nested in other groups and used
+// with After. In ordered mode the group takes the place of its first
+// declared expectation
+func InOrder(mocks ...Mock) Mock {
+	return mock.InOrder(mocks...)
+}
+
+// InAnyOrder groups the given expectations, which may be matched in
+// any order, even in ordered mode. The group may be nested in other
+// groups and used with After. In ordered mode the group takes the
+// place of its first declared expectation, so that the expectations
+// declared after it are matched only when the whole group was
+func InAnyOrder(mocks ...Mock) Mock {
+	return mock.InAnyOrder(mocks...)
+}
+
+// InLanes groups the expectations of concurrent workers, one lane
+// for each of them. The expectations of a lane must be matched in
+// order, while the lanes are matched concurrently, so that their
+// calls interleave freely. It is a shorthand for InAnyOrder of an
+// InOrder group for every lane, which may be used like any group
+func InLanes(lanes ...[]Mock) Mock {
+	return mock.InLanes(lanes...)
 }
 
 // WillReturnError the expectation will return an error
-func (c *conn) WillReturnError(err error) Mock {
-	c.active.setError(err)
-	return c
+func (m *mockedExpectation) WillReturnError(err error) Mock {
+	m.e.setError(err)
+	return m
+}
+
+// WillDelayFor the matched call will return only after the given
+// duration, or with the error of the context of the call once it is
+// canceled, which allows to simulate a slow database, like commits
+// stalled by synchronous replication. Contexts are passed along since
+// go1.8 by BeginTx, PingContext, ExecContext and QueryContext, commits
+// and rollbacks are canceled with the context the transaction was
+// begun with
+func (m *mockedExpectation) WillDelayFor(d time.Duration) Mock {
+	m.e.common().delay = d
+	return m
+}
+
+// WillTimeout the matched call blocks until its context is done and
+// returns the error of the context, context.DeadlineExceeded once its
+// deadline passed, the way drivers return it from a query outlasting
+// the timeout. So that the classification of timeouts and the retry
+// policies of the code under test may be tested. Calls without a
+// context, or one which is never done, return context.DeadlineExceeded
+// immediately, like the statement timeout of the database would
+func (m *mockedExpectation) WillTimeout() Mock {
+	m.e.common().timeout = true
+	return m
+}
+
+// WillBlockUntilCanceled the matched call blocks until its context
+// is canceled, or its deadline passes, and returns the error of the
+// context. The cancellation is counted in the Report, so that tests
+// may verify the cancellation of a request propagates into the calls
+// to the database in flight. Calls without a context, which may be
+// canceled, fail immediately
+func (m *mockedExpectation) WillBlockUntilCanceled() Mock {
+	m.e.common().blocking = true
+	return m
+}
+
+// InTransaction the matched call fails, unless it is made within a
+// transaction, on its connection. So that tests verify statements are
+// executed through the transaction, like with tx.Stmt, rather than on
+// another connection of the pool, which a statement prepared outside
+// of the transaction and used as it is would do
+func (m *mockedExpectation) InTransaction() Mock {
+	m.e.common().inTx = true
+	return m
+}
+
+// RequireDeadlineWithin the matched call fails, unless its context
+// has a deadline, which ends within the given duration. So that the
+// tests enforce every call to the database to have a timeout. Works
+// with calls given a context since go1.8, like Exec, Query, Call,
+// Begin and Ping expectations, calls without a context always fail
+func (m *mockedExpectation) RequireDeadlineWithin(d time.Duration) Mock {
+	m.e.common().deadline = d
+	return m
+}
+
+// WithContextCheck the matched call fails, unless the given check
+// of its context passes. So that multi-tenant code may verify the
+// tenant or trace id it puts on the context reaches the database.
+// Works with calls given a context since go1.8, like Exec, Query,
+// Call, Begin and Ping expectations, calls without one always fail
+func (m *mockedExpectation) WithContextCheck(check func(ctx Context) error) Mock {
+	m.e.common().ctxCheck = check
+	return m
+}
+
+// Times expectation must be matched by the given number of calls,
+// instead of a single one. In ordered mode, the expectations declared
+// after it are matched only once it was matched as many times
+func (m *mockedExpectation) Times(n int) Mock {
+	if n < 1 {
+		panic(fmt.Sprintf("expectation %T must be matched at least once, but got %d times", m.e, n))
+	}
+	m.e.common().times = n
+	return m
+}
+
+// ThenReturnRows the next call matching a repeated expectation
+// will return Rows, instead of the response to the previous call.
+// The last response is returned to the remaining calls.
+// Works only with Query and Call expectations
+func (m *mockedExpectation) ThenReturnRows(rows driver.Rows) Mock {
+	return m.then(func() {
+		m.WillReturnRows(rows)
+		m.e.setError(nil)
+	})
+}
+
+// ThenReturnResult the next call matching a repeated expectation
+// will return a Result, instead of the response to the previous
+// call. Works only with Exec and Call expectations
+func (m *mockedExpectation) ThenReturnResult(result driver.Result) Mock {
+	return m.then(func() {
+		m.WillReturnResult(result)
+		m.e.setError(nil)
+	})
+}
+
+// ThenReturnError the next call matching a repeated expectation
+// will return an error, instead of the response to the previous call
+func (m *mockedExpectation) ThenReturnError(err error) Mock {
+	return m.then(func() {
+		m.e.setError(err)
+	})
+}
+
+// adds a response to the successive calls, the response declared
+// so far is the one to the first call
+func (m *mockedExpectation) then(respond func()) Mock {
+	common := m.e.common()
+	if common.responses == nil {
+		common.responses = []func(expectation){m.response()}
+	}
+	respond()
+	common.responses = append(common.responses, m.response())
+	common.responses[0](m.e)
+	return m
+}
+
+// restores the response declared so far on the expectation it is
+// called with, which may be a copy applied from an ExpectationSet
+func (m *mockedExpectation) response() func(expectation) {
+	err := m.e.common().err
+	switch e := m.e.(type) {
+	case *expectedQuery:
+		rows := e.rows
+		return func(e expectation) {
+			eq := e.(*expectedQuery)
+			eq.rows, eq.err = rows, err
+		}
+	case *expectedExec:
+		result := e.result
+		return func(e expectation) {
+			ee := e.(*expectedExec)
+			ee.result, ee.err = result, err
+		}
+	case *expectedCall:
+		rows, result := e.rows, e.result
+		return func(e expectation) {
+			ec := e.(*expectedCall)
+			ec.rows, ec.result, ec.err = rows, result, err
+		}
+	}
+	return func(e expectation) { e.setError(err) }
+}
+
+// After expectation may be matched only after all the given
+// expectations were matched, which allows to require partial
+// order in unordered mode. In ordered mode expectations declared
+// later can not be required, such an expectation never matches
+func (m *mockedExpectation) After(mocks ...Mock) Mock {
+	common := m.e.common()
+	for _, other := range mocks {
+		o, ok := other.(*mockedExpectation)
+		if !ok || o.conn != m.conn {
+			panic(fmt.Sprintf("expectation %T may be matched only after expectations of the same mock, but got %T", m.e, other))
+		}
+		common.after = append(common.after, o.e)
+	}
+	return m
 }
 
 // ExpectExec expects database Exec to be triggered, which will match
 // the given query string as a regular expression
 func ExpectExec(sqlRegexStr string) Mock {
-	e := &expectedExec{}
-	e.sqlRegex = regexp.MustCompile(sqlRegexStr)
-	mock.conn.expectations = append(mock.conn.expectations, e)
-	mock.conn.active = e
-	return mock.conn
+	return mock.ExpectExec(sqlRegexStr)
 }
 
 // ExpectQuery database Query to be triggered, which will match
 // the given query string as a regular expression
 func ExpectQuery(sqlRegexStr string) Mock {
-	e := &expectedQuery{}
-	e.sqlRegex = regexp.MustCompile(sqlRegexStr)
+	return mock.ExpectQuery(sqlRegexStr)
+}
 
-	mock.conn.expectations = append(mock.conn.expectations, e)
-	mock.conn.active = e
-	return mock.conn
+// ExpectCall expects a stored procedure with the given name
+// to be called, either through Exec or Query. The call syntax
+// of common dialects is understood: CALL proc(?), EXEC proc ?,
+// {call proc(?)}, {? = call proc(?)} and BEGIN proc(?); END;
+func ExpectCall(procedure string) Mock {
+	return mock.ExpectCall(procedure)
+}
+
+// ExpectCopyFrom expects a postgres COPY FROM STDIN statement for
+// the given table and columns to be prepared, as lib/pq does with
+// pq.CopyIn. Every row is sent with an Exec of the statement and the
+// final Exec without arguments returns the result of the copy
+func ExpectCopyFrom(table string, columns ...string) Mock {
+	return mock.ExpectCopyFrom(table, columns...)
 }
 
 // WithArgs expectation should be called with given arguments.
-// Works with Exec and Query expectations
-func (c *conn) WithArgs(args ...driver.Value) Mock {
-	eq, ok := c.active.(*expectedQuery)
-	if !ok {
-		ee, ok := c.active.(*expectedExec)
-		if !ok {
-			panic(fmt.Sprintf("arguments may be expected only with query based expectations, current is %T", c.active))
-		}
-		ee.args = args
-	} else {
-		eq.args = args
+// Works with Exec, Query and Call expectations. Panics if the
+// expected regex matches a single query only, being a literal
+// anchored at both ends, like with AnchorRegexes, which has as
+// many placeholders as there are args
+func (m *mockedExpectation) WithArgs(args ...driver.Value) Mock {
+	e := queryBased(m.e)
+	if e == nil {
+		panic(fmt.Sprintf("arguments may be expected only with query based expectations, current is %T", m.e))
+	}
+	e.args, e.expanded = flatten(args)
+
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	if m.conn.matching&FingerprintLiterals == 0 {
+		// fingerprinted literals are placeholders no arg is bound to
+		e.validateArgs(m.conn.placeholders)
+	}
+	return m
+}
+
+// WithArgAt expectation should be called with the given argument at
+// the zero based position n, while other arguments are not compared,
+// unless expected by WithArgs. Works with Exec, Query and Call expectations
+func (m *mockedExpectation) WithArgAt(n int, arg driver.Value) Mock {
+	e := queryBased(m.e)
+	if e == nil {
+		panic(fmt.Sprintf("arguments may be expected only with query based expectations, current is %T", m.e))
+	}
+	if e.argsAt == nil {
+		e.argsAt = make(map[int]driver.Value)
 	}
-	return c
+	e.argsAt[n] = arg
+	return m
 }
 
 // WillReturnResult expectation will return a Result.
-// Works only with Exec expectations
-func (c *conn) WillReturnResult(result driver.Result) Mock {
-	eq, ok := c.active.(*expectedExec)
-	if !ok {
-		panic(fmt.Sprintf("driver.result may be returned only by exec expectations, current is %T", c.active))
+// Works only with Exec and Call expectations
+func (m *mockedExpectation) WillReturnResult(result driver.Result) Mock {
+	switch e := m.e.(type) {
+	case *expectedExec:
+		e.result = result
+	case *expectedCall:
+		e.result = result
+	case *expectedCopyFrom:
+		e.result = result
+	default:
+		panic(fmt.Sprintf("driver.result may be returned only by exec expectations, current is %T", m.e))
 	}
-	eq.result = result
-	return c
+	return m
+}
+
+// WillReturnRowsAffected expectation will return a result with
+// the given number of rows affected and no meaningful insert id,
+// a shorthand for WillReturnResult(NewResult(0, n)).
+// Works only with Exec, Call and CopyFrom expectations
+func (m *mockedExpectation) WillReturnRowsAffected(n int64) Mock {
+	return m.WillReturnResult(NewResult(0, n))
 }
 
 // WillReturnRows expectation will return Rows.
-// Works only with Query expectations
-func (c *conn) WillReturnRows(rows driver.Rows) Mock {
-	eq, ok := c.active.(*expectedQuery)
+// Works only with Query and Call expectations
+func (m *mockedExpectation) WillReturnRows(rows driver.Rows) Mock {
+	switch e := m.e.(type) {
+	case *expectedQuery:
+		e.rows = rows
+	case *expectedCall:
+		e.rows = rows
+	default:
+		panic(fmt.Sprintf("driver.rows may be returned only by query expectations, current is %T", m.e))
+	}
+	return m
+}
+
+// WillReturnRow expectation will return a single row
+// of the given columns and values, a shorthand for
+// WillReturnRows(NewRows(columns).AddRow(values...)).
+// Works only with Query and Call expectations
+func (m *mockedExpectation) WillReturnRow(columns []string, values ...driver.Value) Mock {
+	return m.WillReturnRows(NewRows(columns).AddRow(values...))
+}
+
+// WillReturnOutParams expectation will assign the given values
+// to the sql.Out arguments of a procedure call, in order.
+// Works only with Call expectations
+func (m *mockedExpectation) WillReturnOutParams(values ...driver.Value) Mock {
+	e, ok := m.e.(*expectedCall)
+	if !ok {
+		panic(fmt.Sprintf("output parameters may be returned only by call expectations, current is %T", m.e))
+	}
+	e.out = values
+	return m
+}
+
+// WithCopyRow expects the next row copied to match given values.
+// If no rows are set, any rows are accepted.
+// Works only with CopyFrom expectations
+func (m *mockedExpectation) WithCopyRow(values ...driver.Value) Mock {
+	e, ok := m.e.(*expectedCopyFrom)
+	if !ok {
+		panic(fmt.Sprintf("copy rows may be expected only with copy from expectations, current is %T", m.e))
+	}
+	e.rows = append(e.rows, values)
+	return m
+}
+
+// MustBeClosed expects every rows returned by the expectation to be
+// closed, either explicitly or by reading all of them, which database/sql
+// does. Rows left open keep the connection busy, so it is never closed,
+// verify it with ExpectationsWereMet. Works with Query and Call expectations
+func (m *mockedExpectation) MustBeClosed() Mock {
+	m.rowsTracker("closed").mustBeClosed = true
+	return m
+}
+
+// MustBeFullyRead expects every rows returned by the expectation to
+// be read until the last one. Works with Query and Call expectations
+func (m *mockedExpectation) MustBeFullyRead() Mock {
+	m.rowsTracker("fully read").mustBeFullyRead = true
+	return m
+}
+
+// MustReuseStatement expects every call matched by the expectation to
+// be made through the same prepared statement, rather than one prepared
+// anew for every call, verify it with ExpectationsWereMet. So that tests
+// may assert a query executed many times is prepared once. Works with
+// Exec, Query and Call expectations matched several times with Times
+func (m *mockedExpectation) MustReuseStatement() Mock {
+	m.e.common().reuse = true
+	return m
+}
+
+// ThroughStatement the matched call fails, unless it is made through
+// a statement prepared by the given ExpectPrepare expectation. So that
+// tests catch code, which sends the SQL anew instead of executing the
+// handle it prepared. Works with Exec, Query and Call expectations
+func (m *mockedExpectation) ThroughStatement(prepare Mock) Mock {
+	p, ok := prepare.(*mockedExpectation)
+	if !ok || p.conn != m.conn {
+		panic(fmt.Sprintf("calls may be required only through a statement prepared by the same mock, but got %T", prepare))
+	}
+	ep, ok := p.e.(*expectedPrepare)
 	if !ok {
-		panic(fmt.Sprintf("driver.rows may be returned only by query expectations, current is %T", c.active))
+		panic(fmt.Sprintf("calls may be required only through a statement of a prepare expectation, but got %T", p.e))
 	}
-	eq.rows = rows
-	return c
+	m.e.common().through = ep
+	return m
+}
+
+// ExpectConn expects a dedicated connection, like the one of db.Conn,
+// which expectations may be scoped to with OnConn. The first one of them
+// matched binds it to the connection of the call, so that the others are
+// matched only by calls made on the same connection. The connection is
+// expected to be closed, which is verified since Go 1.15, because older
+// database/sql does not report connections returned to the pool
+func ExpectConn() Mock {
+	return mock.ExpectConn()
+}
+
+// ExpectPooledConn expects one of the connections of the pool, which
+// expectations may be scoped to with OnConn, like to the dedicated one
+// of ExpectConn. It allows to verify connection affinity of calls made
+// on the database, like an advisory lock and the work it guards being
+// done on the same connection. The connection is not expected to be
+// closed, since database/sql returns it to the pool after every call
+func ExpectPooledConn() Mock {
+	return mock.ExpectPooledConn()
+}
+
+// OnConn scopes the expectation, or a group, to the dedicated
+// connection expected with ExpectConn or ExpectPooledConn, so
+// that it is matched only by calls made on that connection
+func (m *mockedExpectation) OnConn(dedicated Mock) Mock {
+	d, ok := dedicated.(*mockedExpectation)
+	if !ok || d.conn != m.conn {
+		panic(fmt.Sprintf("expectations may be scoped only to a dedicated connection of the same mock, but got %T", dedicated))
+	}
+	dc, ok := d.e.(*expectedConn)
+	if !ok {
+		panic(fmt.Sprintf("expectations may be scoped only to a dedicated connection, but got %T", d.e))
+	}
+	m.e.common().conn = dc
+	return m
+}
+
+// Unanchored allows the expected regex to match a part of the query,
+// even if regexes are anchored with AnchorRegexes query matching.
+// Works with Exec, Query and Call expectations
+func (m *mockedExpectation) Unanchored() Mock {
+	e := queryBased(m.e)
+	if e == nil {
+		panic(fmt.Sprintf("only query based expectations may be unanchored, current is %T", m.e))
+	}
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	e.unanchored = true
+	m.conn.adapt()
+	return m
+}
+
+// WithConflictTarget expects the statement to be an upsert, which
+// handles conflicts on the given columns, or the constraint named
+// by ON CONFLICT ON CONSTRAINT, in any order. ON DUPLICATE KEY UPDATE
+// of MySQL has no conflict target, so it does not match. The conflict
+// target is parsed rather than matched by the regex, which is prone
+// to break on whitespace and quoting. Works with Exec and Query
+// expectations
+func (m *mockedExpectation) WithConflictTarget(columns ...string) Mock {
+	u := m.upsert("conflict target")
+	u.target, u.hasTarget = columns, true
+	return m
+}
+
+// WithUpdateSet expects the statement to be an upsert, which updates
+// the given columns on a conflict, in any order, by ON CONFLICT DO
+// UPDATE SET or ON DUPLICATE KEY UPDATE. Without columns it expects
+// ON CONFLICT DO NOTHING. It may be combined with WithConflictTarget,
+// both are matched independently. Works with Exec and Query expectations
+func (m *mockedExpectation) WithUpdateSet(columns ...string) Mock {
+	u := m.upsert("update set")
+	u.set, u.hasSet = columns, true
+	return m
+}
+
+// the expected conflict handling, the expectation
+// must be an exec or a query
+func (m *mockedExpectation) upsert(what string) *upsert {
+	var e *queryBasedExpectation
+	switch q := m.e.(type) {
+	case *expectedExec:
+		e = &q.queryBasedExpectation
+	case *expectedQuery:
+		e = &q.queryBasedExpectation
+	default:
+		panic(fmt.Sprintf("%s may be expected only with Exec and Query expectations, current is %T", what, m.e))
+	}
+	u := &upsert{} // copied, since copies of expectations share it
+	if e.upsert != nil {
+		*u = *e.upsert
+	}
+	e.upsert = u
+	return u
+}
+
+func (m *mockedExpectation) rowsTracker(how string) *rowsTracker {
+	switch e := m.e.(type) {
+	case *expectedQuery:
+		return &e.rowsTracker
+	case *expectedCall:
+		return &e.rowsTracker
+	}
+	panic(fmt.Sprintf("rows may be required to be %s only with query expectations, current is %T", how, m.e))
+}
+
+// ExpectationsWereMet checks whether all expectations declared so far
+// were met and no policies were violated, like closing the connection
+// does, but without clearing them. Allows to verify expectations when
+// the connection is never closed, since it is still in use
+func ExpectationsWereMet() error {
+	return mock.ExpectationsWereMet()
 }
@@ -2,7 +2,9 @@ package sqlmock
 
 import (
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
+	"strings"
 	"testing"
 	"time"
 )
@@ -498,3 +500,906 @@ func TestArgumentReflectValueTypeError(t *testing.T) {
 		t.Error("Expected error, but got none")
 	}
 }
+
+func TestSameRowsForSeveralExpectations(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rs := NewRows([]string{"id", "title"}).AddRow(5, "hello world")
+	ExpectQuery("SELECT (.+) FROM articles WHERE id = ?").WithArgs(5).WillReturnRows(rs)
+	ExpectQuery("SELECT (.+) FROM articles WHERE id = ?").WithArgs(5).WillReturnRows(rs)
+
+	var id int
+	var title string
+	for i := 0; i < 2; i++ {
+		err = db.QueryRow("SELECT id, title FROM articles WHERE id = ?", 5).Scan(&id, &title)
+		if err != nil {
+			t.Errorf("error '%s' was not expected while reading rows for query %d", err, i+1)
+		}
+		if id != 5 {
+			t.Errorf("expected mocked id to be 5, but got %d instead", id)
+		}
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestRowsWithScanError(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rs := NewRows([]string{"id", "title"}).
+		AddRow(1, "hello").
+		AddRow(2, ScanError(fmt.Errorf("corrupt title")))
+	ExpectQuery("SELECT (.+) FROM articles").WillReturnRows(rs)
+
+	rows, err := db.Query("SELECT id, title FROM articles")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while retrieving mock rows", err)
+	}
+	defer rows.Close()
+
+	var id int
+	var title string
+
+	rows.Next()
+	if err = rows.Scan(&id, &title); err != nil {
+		t.Errorf("error '%s' was not expected while scanning the first row", err)
+	}
+
+	rows.Next()
+	if err = rows.Scan(&id, &title); err == nil {
+		t.Error("an error was expected while scanning title of the second row, but got none")
+	}
+
+	if id != 2 {
+		t.Errorf("expected id of the second row to be scanned as 2, but got %d", id)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+// a column, which keeps the error it could not be scanned for
+type corruptible struct {
+	value string
+	err   error
+}
+
+func (c *corruptible) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case error:
+		c.err = v
+	case string:
+		c.value = v
+	}
+	return nil
+}
+
+func TestRowsWithScanErrorInOneColumn(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rs := NewRows([]string{"id", "title", "body"}).
+		AddRow(2, ScanError(fmt.Errorf("corrupt title")), "world")
+	ExpectQuery("SELECT (.+) FROM articles").WillReturnRows(rs)
+
+	var id int
+	var title corruptible
+	var body string
+	if err = db.QueryRow("SELECT id, title, body FROM articles").Scan(&id, &title, &body); err != nil {
+		t.Errorf("error '%s' was not expected, since the title is scanned into a scanner handling errors", err)
+	}
+	if id != 2 || body != "world" {
+		t.Errorf("expected the other columns to be scanned as 2 and world, but got %d and %s", id, body)
+	}
+	if title.err == nil || title.err.Error() != "corrupt title" {
+		t.Errorf("expected the title to be scanned as the corrupt title error, but got %v", title.err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestCallExpectations(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectCall("archive_orders").WithArgs(2014).WillReturnResult(NewResult(0, 3))
+	ExpectCall("get_user").
+		WithArgs(5).
+		WillReturnRows(NewRows([]string{"id", "name"}).AddRow(5, "gedi"))
+	ExpectCall("get_user").WithArgs(6).WillReturnError(fmt.Errorf("no such user"))
+
+	res, err := db.Exec("CALL archive_orders(?)", 2014)
+	if err != nil {
+		t.Errorf("error '%s' was not expected while calling a procedure", err)
+	}
+
+	if affected, _ := res.RowsAffected(); affected != 3 {
+		t.Errorf("expected affected rows to be 3, but got %d instead", affected)
+	}
+
+	var id int
+	var name string
+	if err = db.QueryRow("EXEC get_user ?", 5).Scan(&id, &name); err != nil {
+		t.Errorf("error '%s' was not expected while calling a procedure", err)
+	}
+
+	if name != "gedi" {
+		t.Errorf("expected name to be 'gedi', but got '%s' instead", name)
+	}
+
+	if _, err = db.Exec("CALL get_user(?)", 6); err == nil {
+		t.Error("an error was expected while calling a procedure, but got none")
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestCallExpectationProcedureMismatch(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectCall("get_user")
+
+	if _, err = db.Exec("CALL get_order(?)", 1); err == nil {
+		t.Error("an error was expected, since a different procedure was called")
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestPreparedStatementArgumentCount(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	stmt, err := db.Prepare("UPDATE articles SET title = ? WHERE id = ?")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while creating a prepared statement", err)
+	}
+
+	if _, err = stmt.Exec("hello"); err == nil {
+		t.Error("an error was expected, since the statement has two placeholders")
+	}
+
+	SetPlaceholderStyle(PermissivePlaceholders)
+	defer SetPlaceholderStyle(AnyPlaceholders)
+
+	ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, 1))
+
+	stmt, err = db.Prepare("UPDATE articles SET title = ? WHERE id = ?")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while creating a prepared statement", err)
+	}
+
+	if _, err = stmt.Exec("hello"); err != nil {
+		t.Errorf("error '%s' was not expected, since placeholders are not counted", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestBadConnectionRetry(t *testing.T) {
+	db, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectExec("INSERT INTO articles").WillReturnError(driver.ErrBadConn)
+	ExpectExec("INSERT INTO articles").WillReturnResult(NewResult(1, 1))
+
+	if _, err = db.Exec("INSERT INTO articles (title) VALUES (?)", "hello"); err != nil {
+		t.Errorf("error '%s' was not expected, since database/sql should retry on a fresh connection", err)
+	}
+
+	ExpectBadConnThenRecover()
+	ExpectQuery("SELECT (.+) FROM articles").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+
+	var id int
+	if err = db.QueryRow("SELECT id FROM articles").Scan(&id); err != nil {
+		t.Errorf("error '%s' was not expected, since database/sql should retry on a fresh connection", err)
+	}
+
+	if id != 1 {
+		t.Errorf("expected mocked id to be 1, but got %d instead", id)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestBadConnectionOnCommitAndRollback(t *testing.T) {
+	db, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectBegin()
+	ExpectBadConnThenRecover()
+	ExpectBegin()
+	ExpectBadConnThenRecover()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if err = tx.Commit(); err != driver.ErrBadConn {
+		t.Errorf("expected a bad connection error on commit, but got %v", err)
+	}
+
+	if tx, err = db.Begin(); err != nil {
+		t.Errorf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	if err = tx.Rollback(); err != driver.ErrBadConn {
+		t.Errorf("expected a bad connection error on rollback, but got %v", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestFailAfterNumberOfCalls(t *testing.T) {
+	db, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	lost := fmt.Errorf("connection lost")
+	FailAfter(2, lost)
+
+	ExpectBegin()
+	ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, 1))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	if _, err = tx.Exec("UPDATE articles SET title = ?", "hello"); err != nil {
+		t.Errorf("error '%s' was not expected while updating articles", err)
+	}
+
+	if err = tx.Commit(); err != lost {
+		t.Errorf("expected connection lost error on commit, but got %v", err)
+	}
+
+	if _, err = db.Query("SELECT * FROM articles"); err != lost {
+		t.Errorf("expected connection lost error on query, but got %v", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestFailAfterNumberOfCallsOnOneConnection(t *testing.T) {
+	db, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	// connections returned to the pool are closed
+	db.SetMaxIdleConns(0)
+
+	lost := fmt.Errorf("connection lost")
+	FailAfter(1, lost)
+
+	ExpectBegin()
+	ExpectBegin()
+	ExpectCommit()
+
+	failing, err := db.Begin()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when beginning a transaction", err)
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when beginning a transaction on another connection", err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while committing on another connection", err)
+	}
+
+	if _, err = failing.Exec("UPDATE articles SET title = ?", "hello"); err != lost {
+		t.Errorf("expected connection lost error, after another connection was closed, but got %v", err)
+	}
+	failing.Rollback()
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestLatencyOfConcurrentCalls(t *testing.T) {
+	db, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	// keep all concurrent connections idle, closing a connection asserts expectations
+	db.SetMaxIdleConns(4)
+
+	Latency(50*time.Millisecond, 10*time.Millisecond)
+	defer Latency(0, 0)
+
+	for i := 0; i < 4; i++ {
+		ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, 1))
+	}
+
+	start := time.Now()
+	errs := make(chan error)
+	for i := 0; i < 4; i++ {
+		go func() {
+			_, err := db.Exec("UPDATE articles SET title = ?", "hello")
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < 4; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("error '%s' was not expected while updating articles", err)
+		}
+	}
+
+	elapsed := time.Since(start)
+	if elapsed < 50*time.Millisecond {
+		t.Errorf("expected calls to be delayed by at least 50ms, but it took %s", elapsed)
+	}
+
+	if elapsed >= 200*time.Millisecond {
+		t.Errorf("expected concurrent calls to be delayed at the same time, but it took %s", elapsed)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestRetriedTransactionExpectations(t *testing.T) {
+	db, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	deadlock := fmt.Errorf("deadlock found when trying to get lock")
+	ExpectRetriedTransaction(2, deadlock, func() {
+		ExpectExec("UPDATE users SET balance").WillReturnResult(NewResult(0, 1))
+		ExpectExec("UPDATE orders SET status").WillReturnResult(NewResult(0, 1))
+	})
+
+	attempts := 0
+	transfer := func() error {
+		attempts++
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err = tx.Exec("UPDATE users SET balance = balance + 10 WHERE id = 1"); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if _, err = tx.Exec("UPDATE orders SET status = 1 WHERE id = 2"); err != nil {
+			tx.Rollback()
+			return err
+		}
+		return tx.Commit()
+	}
+
+	for err = transfer(); err == deadlock; err = transfer() {
+	}
+
+	if err != nil {
+		t.Errorf("error '%s' was not expected, since the transaction should succeed when retried", err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected the transaction to be attempted 3 times, but it was attempted %d times", attempts)
+	}
+
+	// empty body fails on commit
+	ExpectRetriedTransaction(1, deadlock, func() {})
+
+	tx, _ := db.Begin()
+	if err = tx.Commit(); err != deadlock {
+		t.Errorf("expected deadlock error on commit, but got %v", err)
+	}
+
+	tx, _ = db.Begin()
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while commiting the retried transaction", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestResetBetweenScenarios(t *testing.T) {
+	db, err := New()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	for _, affected := range []int64{1, 0} {
+		Reset()
+		ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, affected))
+		ExpectExec("UPDATE authors").WillReturnResult(NewResult(0, 1)) // not triggered
+
+		res, err := db.Exec("UPDATE articles SET title = ?", "hello")
+		if err != nil {
+			t.Errorf("error '%s' was not expected while updating articles", err)
+		}
+
+		if n, _ := res.RowsAffected(); n != affected {
+			t.Errorf("expected %d affected rows, but got %d", affected, n)
+		}
+	}
+
+	Reset()
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database, expectations were reset", err)
+	}
+}
+
+func TestUnorderedExpectationsWithAfter(t *testing.T) {
+	MatchExpectationsInOrder(false)
+	defer MatchExpectationsInOrder(true)
+
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	update := ExpectExec("UPDATE orders").WithArgs(5).WillReturnResult(NewResult(0, 1))
+	ExpectExec("INSERT INTO audit").WillReturnResult(NewResult(1, 1)).After(update)
+	ExpectQuery("SELECT (.+) FROM users").WillReturnRows(NewRows([]string{"id"}).AddRow(1))
+
+	if _, err = db.Exec("INSERT INTO audit (action) VALUES ('cancel')"); err == nil {
+		t.Errorf("expected an error, since audit record must be inserted after the order is updated")
+	}
+
+	var id int
+	if err = db.QueryRow("SELECT id FROM users").Scan(&id); err != nil {
+		t.Errorf("error '%s' was not expected while selecting users out of order", err)
+	}
+
+	if _, err = db.Exec("UPDATE orders SET status = 1 WHERE id = ?", 6); err == nil {
+		t.Errorf("expected an error, since args do not match any remaining expectation")
+	}
+
+	if _, err = db.Exec("UPDATE orders SET status = 1 WHERE id = ?", 5); err != nil {
+		t.Errorf("error '%s' was not expected while updating the order", err)
+	}
+
+	if _, err = db.Exec("INSERT INTO audit (action) VALUES ('cancel')"); err != nil {
+		t.Errorf("error '%s' was not expected while inserting the audit record", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestAfterLaterExpectationInOrderedMode(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	insert := ExpectExec("INSERT INTO audit").WillReturnResult(NewResult(1, 1))
+	insert.After(ExpectExec("UPDATE orders").WillReturnResult(NewResult(0, 1)))
+
+	if _, err = db.Exec("INSERT INTO audit (action) VALUES ('cancel')"); err == nil {
+		t.Errorf("expected an error, since audit record can never be inserted after the order update declared later")
+	}
+
+	Reset()
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database, expectations were reset", err)
+	}
+}
+
+func TestInAnyOrderGroupThenCommit(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	rs := func() driver.Rows { return NewRows([]string{"id"}).AddRow(1) }
+
+	ExpectBegin()
+	InAnyOrder(
+		ExpectQuery("SELECT (.+) FROM users").WillReturnRows(rs()),
+		InOrder(
+			ExpectQuery("SELECT (.+) FROM orders").WillReturnRows(rs()),
+			ExpectQuery("SELECT (.+) FROM items").WillReturnRows(rs()),
+		),
+	)
+	ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	var id int
+	if err = tx.QueryRow("SELECT id FROM items").Scan(&id); err == nil {
+		t.Errorf("expected an error, since items must be selected after orders")
+	}
+
+	for _, table := range []string{"orders", "users"} {
+		if err = tx.QueryRow("SELECT id FROM " + table).Scan(&id); err != nil {
+			t.Errorf("error '%s' was not expected while selecting %s", err, table)
+		}
+	}
+
+	if err = tx.Commit(); err == nil {
+		t.Errorf("expected an error, since commit must happen after the whole group")
+	}
+
+	if err = db.QueryRow("SELECT id FROM items").Scan(&id); err != nil {
+		t.Errorf("error '%s' was not expected while selecting items", err)
+	}
+
+	if _, ok := mock.conn.next(ofType(&expectedCommit{})).(*expectedCommit); !ok {
+		t.Errorf("expected commit to be expected next, after the whole group")
+	}
+
+	Reset()
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestTransactionLeftOpen(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectBegin()
+	ExpectExec("UPDATE orders").WillReturnResult(NewResult(0, 1))
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when beginning a transaction", err)
+	}
+
+	// runs on another connection, since the transaction holds the first one
+	if _, err = db.Exec("UPDATE orders SET status = 1"); err != nil {
+		t.Errorf("error '%s' was not expected while updating orders", err)
+	}
+
+	err = ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "was left open") {
+		t.Errorf("expected transaction to be reported as left open, but got %v", err)
+	}
+
+	err = db.Close()
+	if err == nil || !strings.Contains(err.Error(), "transaction begun at sqlmock_test.go:") {
+		t.Errorf("expected closing the database to report the transaction left open, but got %v", err)
+	}
+
+	tx.Rollback()
+}
+
+func TestStatementsMustBeClosed(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	RequireStatementsClosed()
+	ExpectExec("UPDATE orders").WillReturnResult(NewResult(0, 1))
+
+	stmt, err := db.Prepare("UPDATE orders SET status = ?")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while preparing a statement", err)
+	}
+	if _, err = stmt.Exec(1); err != nil {
+		t.Errorf("error '%s' was not expected while updating orders", err)
+	}
+
+	err = ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "statement 'UPDATE orders SET status = ?' prepared at sqlmock_test.go:") {
+		t.Errorf("expected the statement to be reported as never closed, but got %v", err)
+	}
+
+	stmt.Close()
+	if err = ExpectationsWereMet(); err != nil {
+		t.Errorf("error '%s' was not expected, the statement was closed", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestRequireExactTimeLocation(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	created := time.Date(2014, 8, 16, 12, 0, 0, 0, time.UTC)
+	local := created.In(time.FixedZone("EEST", 3*3600))
+
+	ExpectExec("UPDATE orders").WithArgs(created).WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE orders SET created = ?", local); err != nil {
+		t.Errorf("error '%s' was not expected, the same instant in another location should match", err)
+	}
+
+	RequireExactTimeLocation()
+	ExpectExec("UPDATE orders").WithArgs(created).WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE orders SET created = ?", local); err == nil {
+		t.Errorf("expected an error, since the exact time location is required")
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestRequireExactArgTypes(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	RequireExactArgTypes()
+	ExpectExec("UPDATE orders").WithArgs(5).WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE orders SET status = ?", 5); err == nil {
+		t.Errorf("expected an error, since database/sql sends an int64 while an int is expected")
+	}
+
+	ExpectExec("UPDATE orders").WithArgs(int64(5), []byte("note")).WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE orders SET status = ?, note = ?", 5, "note"); err == nil {
+		t.Errorf("expected an error, since a string is sent while []byte is expected")
+	}
+
+	ExpectExec("UPDATE orders").WithArgs(int64(5), []byte("note")).WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE orders SET status = ?, note = ?", 5, []byte("other")); err == nil {
+		t.Errorf("expected an error, since other bytes are sent")
+	}
+
+	ExpectExec("UPDATE orders").WithArgs(int64(5), []byte("note")).WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE orders SET status = ?, note = ?", 5, []byte("note")); err != nil {
+		t.Errorf("error '%s' was not expected, the arguments have the expected types", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestMatchNumbersByValue(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	MatchNumbersByValue()
+	ExpectExec("UPDATE orders").WithArgs(5, uint8(5), 2.5).WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE orders SET status = ?, priority = ?, fee = ?", 5.0, int16(5), float32(2.5)); err != nil {
+		t.Errorf("error '%s' was not expected, numerically equal values should match", err)
+	}
+
+	ExpectExec("UPDATE orders").WithArgs(5).WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE orders SET status = ?", 5.5); err == nil {
+		t.Errorf("expected an error, since 5.5 is not 5")
+	}
+
+	ExpectExec("UPDATE orders").WithArgs(5).WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE orders SET status = ?", "5"); err == nil {
+		t.Errorf("expected an error, since a string is not a number")
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestRepeatedExpectationReturnsSuccessiveResponses(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectQuery("SELECT status FROM jobs").
+		Times(4).
+		WillReturnRow([]string{"status"}, "pending").
+		ThenReturnError(fmt.Errorf("deadlock")).
+		ThenReturnRows(NewRows([]string{"status"}).AddRow("done"))
+	ExpectExec("DELETE FROM jobs").WillReturnRowsAffected(1)
+
+	var status string
+	if err = db.QueryRow("SELECT status FROM jobs").Scan(&status); err != nil || status != "pending" {
+		t.Errorf("expected the first response to be pending, but got '%s' and error: %v", status, err)
+	}
+	if err = db.QueryRow("SELECT status FROM jobs").Scan(&status); err == nil || err.Error() != "deadlock" {
+		t.Errorf("expected the second response to be a deadlock, but got: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err = db.QueryRow("SELECT status FROM jobs").Scan(&status); err != nil || status != "done" {
+			t.Errorf("expected the last response to be repeated, but got '%s' and error: %v", status, err)
+		}
+	}
+	if err = db.QueryRow("SELECT status FROM jobs").Scan(&status); err == nil {
+		t.Errorf("expected an error, since the query was expected four times only")
+	}
+	if _, err = db.Exec("DELETE FROM jobs"); err != nil {
+		t.Errorf("error '%s' was not expected while deleting jobs", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestRepeatedExpectationMatchedTooFewTimes(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectExec("UPDATE jobs").Times(3).WillReturnRowsAffected(0)
+	ExpectExec("DELETE FROM jobs").WillReturnRowsAffected(1)
+
+	for i := 0; i < 2; i++ {
+		if _, err = db.Exec("UPDATE jobs SET version = version + 1"); err != nil {
+			t.Errorf("error '%s' was not expected while updating jobs", err)
+		}
+	}
+	if err = ExpectationsWereMet(); err == nil || !strings.Contains(err.Error(), "matched only 2 of 3 times") {
+		t.Errorf("expected an error reporting the update matched too few times, but got: %v", err)
+	}
+
+	if _, err = db.Exec("UPDATE jobs SET version = version + 1"); err != nil {
+		t.Errorf("error '%s' was not expected while updating jobs", err)
+	}
+	if _, err = db.Exec("DELETE FROM jobs"); err != nil {
+		t.Errorf("error '%s' was not expected while deleting jobs", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestExpectationsScopedToPooledConnection(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	pc := ExpectPooledConn()
+	ExpectExec("SELECT pg_advisory_lock").OnConn(pc).WillReturnRowsAffected(0)
+	ExpectQuery("SELECT (.+) FROM jobs").WillReturnRow([]string{"id"}, 1)
+	ExpectExec("UPDATE jobs").OnConn(pc).WillReturnRowsAffected(1)
+
+	if _, err = db.Exec("SELECT pg_advisory_lock(1)"); err != nil {
+		t.Errorf("error '%s' was not expected while locking", err)
+	}
+	rows, err := db.Query("SELECT id FROM jobs")
+	if err != nil {
+		t.Errorf("error '%s' was not expected while selecting jobs", err)
+	}
+	// the locked connection is busy with the rows, another one is opened
+	if _, err = db.Exec("UPDATE jobs SET done = 1"); err == nil {
+		t.Errorf("expected an error, since the update is not made on the locked connection")
+	}
+	rows.Close()
+
+	if err = db.Close(); err == nil || !strings.Contains(err.Error(), "UPDATE jobs") {
+		t.Errorf("expected an error, since the update was never made on the locked connection, but got: %v", err)
+	}
+}
+
+func TestPooledConnectionIsNotExpectedToBeClosed(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	pc := ExpectPooledConn()
+	ExpectExec("SELECT pg_advisory_lock").OnConn(pc).WillReturnRowsAffected(0)
+	ExpectExec("UPDATE jobs").OnConn(pc).WillReturnRowsAffected(1)
+
+	if _, err = db.Exec("SELECT pg_advisory_lock(1)"); err != nil {
+		t.Errorf("error '%s' was not expected while locking", err)
+	}
+	if _, err = db.Exec("UPDATE jobs SET done = 1"); err != nil {
+		t.Errorf("error '%s' was not expected while updating on the locked connection", err)
+	}
+	if err = ExpectationsWereMet(); err != nil {
+		t.Errorf("error '%s' was not expected, the pooled connection is back in the pool", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestLanesInterleaveWhileOrderedWithin(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	lanes := func() {
+		InLanes(
+			[]Mock{
+				ExpectExec("UPDATE accounts").WillReturnRowsAffected(1),
+				ExpectExec("INSERT INTO ledger").WillReturnRowsAffected(1),
+			},
+			[]Mock{
+				ExpectExec("UPDATE stock").WillReturnRowsAffected(1),
+				ExpectExec("INSERT INTO shipments").WillReturnRowsAffected(1),
+			},
+		)
+		ExpectExec("DELETE FROM carts").WillReturnRowsAffected(1)
+	}
+
+	lanes()
+	paid, shipped, done := make(chan error), make(chan error), make(chan error)
+	go func() {
+		_, err := db.Exec("UPDATE accounts SET balance = balance - 1")
+		paid <- err
+		if err = <-shipped; err == nil {
+			_, err = db.Exec("INSERT INTO ledger (amount) VALUES (1)")
+		}
+		done <- err
+	}()
+	go func() {
+		err := <-paid
+		if err == nil {
+			_, err = db.Exec("UPDATE stock SET count = count - 1")
+		}
+		if err == nil {
+			_, err = db.Exec("INSERT INTO shipments (item) VALUES (1)")
+		}
+		shipped <- err
+	}()
+	if err = <-done; err != nil {
+		t.Errorf("error '%s' was not expected while the workers interleave", err)
+	}
+	if _, err = db.Exec("DELETE FROM carts"); err != nil {
+		t.Errorf("error '%s' was not expected after both lanes were matched", err)
+	}
+	if err = ExpectationsWereMet(); err != nil {
+		t.Errorf("error '%s' was not expected, all lanes were matched", err)
+	}
+
+	lanes()
+	if _, err = db.Exec("INSERT INTO shipments (item) VALUES (1)"); err == nil {
+		t.Errorf("expected an error, since the stock is updated first in its lane")
+	}
+
+	if err = db.Close(); err == nil {
+		t.Errorf("expected an error, since the lanes were not matched")
+	}
+}
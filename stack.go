@@ -0,0 +1,60 @@
+package sqlmock
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// CallStacksOption makes the mock record the stack of every driver call,
+// trimmed to the given number of frames outside of the package and of
+// database/sql, in its History. Failure messages of calls, which do not
+// match the expectations, include it, so that it is clear which layer of
+// a deep stack made the call. Zero records no stacks
+func CallStacksOption(depth int) Option {
+	return func(m *MockDB) {
+		m.conn.stackDepth = depth
+	}
+}
+
+// the frames of the caller outside of the package sources and
+// database/sql, the innermost first, at most depth of them
+func callStack(depth int) (stack []string) {
+	for skip := 2; len(stack) < depth; skip++ {
+		pc, file, line, ok := runtime.Caller(skip)
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(file, packageDir+"/") && !strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		if strings.Contains(file, "/database/sql/") {
+			continue
+		}
+		name := "unknown"
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			name = fn.Name()
+			if i := strings.LastIndex(name, "/"); i != -1 {
+				name = name[i+1:]
+			}
+		}
+		stack = append(stack, fmt.Sprintf("%s at %s:%d", name, filepath.Base(file), line))
+	}
+	return stack
+}
+
+// the stack of the driver call being handled for
+// its failure message, if stacks are recorded
+func (c *conn) printStack() string {
+	if c.caller == nil || len(c.caller.stack) == 0 {
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString("\n  CALL STACK, the innermost frame first\n")
+	for _, frame := range c.caller.stack {
+		fmt.Fprintf(&buf, "  %s\n", frame)
+	}
+	return buf.String()
+}
@@ -0,0 +1,54 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCallStacks(t *testing.T) {
+	db, m, err := NewMock(CallStacksOption(2))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectExec("UPDATE users").WillReturnRowsAffected(1)
+	if _, err = db.Exec("UPDATE users SET name = ?", "gedi"); err != nil {
+		t.Errorf("error '%s' was not expected while updating", err)
+	}
+	_, err = db.Exec("DELETE FROM users")
+	if err == nil || !strings.Contains(err.Error(), "\n  CALL STACK, the innermost frame first\n") ||
+		!strings.Contains(err.Error(), ".TestCallStacks at stack_test.go:") {
+		t.Errorf("expected the error of the unexpected call to include its stack, but got: %v", err)
+	}
+
+	calls := m.History()
+	if len(calls) != 3 {
+		t.Fatalf("expected the ping, the update and the delete to be recorded, but got %+v", calls)
+	}
+	for _, call := range calls[1:] {
+		if len(call.Stack) != 2 || !strings.Contains(call.Stack[0], ".TestCallStacks at stack_test.go:") {
+			t.Errorf("expected the stack of %s to be recorded with 2 frames, the test first, but got %v", call.Call, call.Stack)
+		}
+	}
+	m.Reset()
+	db.Close()
+}
+
+func TestNoCallStacksByDefault(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	_, err = db.Exec("DELETE FROM users")
+	if err == nil || strings.Contains(err.Error(), "CALL STACK") {
+		t.Errorf("expected an error without a stack, but got: %v", err)
+	}
+	for _, call := range m.History() {
+		if call.Stack != nil {
+			t.Errorf("expected %s to be recorded without a stack, but got %v", call.Call, call.Stack)
+		}
+	}
+	m.Reset()
+	db.Close()
+}
@@ -2,25 +2,98 @@ package sqlmock
 
 import (
 	"database/sql/driver"
+	"fmt"
 )
 
 type statement struct {
-	conn  *conn
-	query string
+	conn    *session
+	query   string
+	created string // where the statement was prepared
+	closed  bool
 }
 
 func (stmt *statement) Close() error {
+	stmt.conn.mu.Lock()
+	defer stmt.conn.mu.Unlock()
+	stmt.closed = true
 	return nil
 }
 
+// NumInput counts the placeholders of the statement,
+// so database/sql validates the number of arguments
+// given, unless permissive placeholders are set
 func (stmt *statement) NumInput() int {
-	return -1
+	stmt.conn.mu.Lock()
+	defer stmt.conn.mu.Unlock()
+	return countPlaceholders(stmt.query, stmt.conn.placeholders)
+}
+
+// ColumnConverter implements driver.ColumnConverter, so that
+// arguments of statements are converted by the value converter
+// of the mock, which emulates the conversions of a driver
+func (stmt *statement) ColumnConverter(idx int) driver.ValueConverter {
+	stmt.conn.mu.Lock()
+	defer stmt.conn.mu.Unlock()
+	if stmt.conn.converter == nil {
+		return driver.DefaultParameterConverter
+	}
+	return stmt.conn.converter
 }
 
 func (stmt *statement) Exec(args []driver.Value) (driver.Result, error) {
+	defer stmt.conn.through(stmt)()
 	return stmt.conn.Exec(stmt.query, args)
 }
 
 func (stmt *statement) Query(args []driver.Value) (driver.Rows, error) {
+	defer stmt.conn.through(stmt)()
 	return stmt.conn.Query(stmt.query, args)
 }
+
+// marks the driver calls of the connection as made through
+// the statement, until the returned function is called.
+// database/sql never uses a connection concurrently
+func (s *session) through(stmt *statement) func() {
+	s.stmt = stmt
+	return func() { s.stmt = nil }
+}
+
+// the call fails, unless it is made through a statement prepared
+// by the prepare expectation it must be made through, if any
+func checkStatement(e expectation, s *session, call string) error {
+	ep := e.common().through
+	if ep == nil {
+		return nil
+	}
+	if s.stmt == nil {
+		return fmt.Errorf("%s was made directly, but %s must be matched through a statement prepared by %s, declared at %s", call, e, ep, ep.declared)
+	}
+	for _, stmt := range ep.prepared {
+		if stmt == s.stmt {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s was made through a statement prepared at %s, but %s must be matched through a statement prepared by %s, declared at %s", call, s.stmt.created, e, ep, ep.declared)
+}
+
+// verifies the calls matched by the expectation were made
+// through a single statement, which was prepared once
+func reused(e expectation) error {
+	common := e.common()
+	var stmts []*statement
+	seen := make(map[*statement]bool)
+	for i, stmt := range common.stmts {
+		if stmt == nil {
+			return fmt.Errorf("%s, declared at %s, must be matched through a prepared statement, but call %d was made directly", e, common.declared, i+1)
+		}
+		if !seen[stmt] {
+			seen[stmt] = true
+			stmts = append(stmts, stmt)
+		}
+	}
+	if len(stmts) > 1 {
+		return fmt.Errorf("%s, declared at %s, was matched through %d statements, but a single one must be prepared and reused, the first was prepared at %s and the second at %s",
+			e, common.declared, len(stmts), stmts[0].created, stmts[1].created)
+	}
+	return nil
+}
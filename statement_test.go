@@ -0,0 +1,111 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMustReuseStatement(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectExec("INSERT INTO events").Times(3).MustReuseStatement().WillReturnRowsAffected(1)
+	stmt, err := db.Prepare("INSERT INTO events (name) VALUES (?)")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if _, err = stmt.Exec(name); err != nil {
+			t.Errorf("error '%s' was not expected while inserting an event", err)
+		}
+	}
+	stmt.Close()
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("error '%s' was not expected, the statement was reused", err)
+	}
+
+	m.ExpectExec("INSERT INTO events").Times(2).MustReuseStatement().WillReturnRowsAffected(1)
+	for _, name := range []string{"a", "b"} {
+		stmt, err := db.Prepare("INSERT INTO events (name) VALUES (?)")
+		if err != nil {
+			t.Fatalf("error '%s' was not expected while preparing a statement", err)
+		}
+		if _, err = stmt.Exec(name); err != nil {
+			t.Errorf("error '%s' was not expected while inserting an event", err)
+		}
+		stmt.Close()
+	}
+	err = m.ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "was matched through 2 statements, but a single one must be prepared and reused") {
+		t.Errorf("expected an error, since the statement was prepared for every call, but got: %v", err)
+	}
+
+	m.Reset()
+	m.ExpectExec("INSERT INTO events").MustReuseStatement().WillReturnRowsAffected(1)
+	if _, err = db.Exec("INSERT INTO events (name) VALUES (?)", "a"); err != nil {
+		t.Errorf("error '%s' was not expected while inserting an event", err)
+	}
+	err = m.ExpectationsWereMet()
+	if err == nil || !strings.Contains(err.Error(), "must be matched through a prepared statement, but call 1 was made directly") {
+		t.Errorf("expected an error, since the insert was not prepared, but got: %v", err)
+	}
+	m.Reset()
+	db.Close()
+}
+
+func TestThroughStatement(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	prepare := m.ExpectPrepare()
+	m.ExpectExec("INSERT INTO events").Times(2).ThroughStatement(prepare).WillReturnRowsAffected(1)
+	stmt, err := db.Prepare("INSERT INTO events (name) VALUES (?)")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	for _, name := range []string{"a", "b"} {
+		if _, err = stmt.Exec(name); err != nil {
+			t.Errorf("error '%s' was not expected while inserting an event", err)
+		}
+	}
+	stmt.Close()
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("error '%s' was not expected, the insert was made through the prepared statement", err)
+	}
+
+	prepare = m.ExpectPrepare()
+	m.ExpectExec("INSERT INTO events").ThroughStatement(prepare).WillReturnRowsAffected(1)
+	if stmt, err = db.Prepare("INSERT INTO events (name) VALUES (?)"); err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	_, err = db.Exec("INSERT INTO events (name) VALUES (?)", "a")
+	if err == nil || !strings.Contains(err.Error(), "was made directly, but exec 'INSERT INTO events' must be matched through a statement prepared by prepare statement") {
+		t.Errorf("expected an error, since the insert was sent anew, but got: %v", err)
+	}
+	stmt.Close()
+
+	m.Reset()
+	prepare = m.ExpectPrepare()
+	m.ExpectPrepare()
+	m.ExpectExec("INSERT INTO events").ThroughStatement(prepare).WillReturnRowsAffected(1)
+	first, err := db.Prepare("INSERT INTO events (name) VALUES (?)")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	second, err := db.Prepare("INSERT INTO events (name) VALUES (?)")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	_, err = second.Exec("a")
+	if err == nil || !strings.Contains(err.Error(), "was made through a statement prepared at") {
+		t.Errorf("expected an error, since the insert was made through another statement, but got: %v", err)
+	}
+	first.Close()
+	second.Close()
+	m.Reset()
+	db.Close()
+}
@@ -0,0 +1,26 @@
+package sqlmock
+
+// CallStats counts driver calls made on the mock since it was last closed
+// or reset, whether they matched expectations or not. Allows to assert
+// performance relevant invariants, like the number of queries an
+// endpoint performs
+type CallStats struct {
+	Queries      int // queries, including the ones of prepared statements
+	Execs        int // execs, including the ones of prepared statements
+	Transactions int // transactions begun
+	Prepares     int // statements prepared
+	RowsReturned int // rows read from the results of queries
+}
+
+// Stats counts the driver calls made on the mock
+// connection since it was last closed or reset
+func Stats() CallStats {
+	return mock.Stats()
+}
+
+// Stats counts the driver calls made on the mock
+func (m *MockDB) Stats() CallStats {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	return m.conn.stats
+}
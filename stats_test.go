@@ -0,0 +1,59 @@
+package sqlmock
+
+import (
+	"testing"
+)
+
+func TestStatsCountDriverCalls(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectBegin()
+	m.ExpectQuery("SELECT id FROM articles").
+		WillReturnRows(NewRows([]string{"id"}).AddRow(1).AddRow(2).AddRow(3))
+	m.ExpectPrepare()
+	m.ExpectExec("UPDATE articles").WillReturnResult(NewResult(0, 1))
+	m.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while beginning a transaction", err)
+	}
+	rs, err := tx.Query("SELECT id FROM articles")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while querying articles", err)
+	}
+	for rs.Next() {
+	}
+	rs.Close()
+
+	stmt, err := tx.Prepare("UPDATE articles SET title = ? WHERE id = ?")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	if _, err = stmt.Exec("hello", 1); err != nil {
+		t.Errorf("error '%s' was not expected while updating articles", err)
+	}
+	stmt.Close()
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while committing a transaction", err)
+	}
+
+	// a call, which was not expected, is counted too
+	if _, err = db.Query("SELECT id FROM users"); err == nil {
+		t.Errorf("expected an error for a query, which was not expected")
+	}
+
+	expected := CallStats{Queries: 2, Execs: 1, Transactions: 1, Prepares: 1, RowsReturned: 3}
+	if stats := m.Stats(); stats != expected {
+		t.Errorf("expected stats %+v, but got %+v", expected, stats)
+	}
+
+	m.Reset()
+	if stats := m.Stats(); stats != (CallStats{}) {
+		t.Errorf("expected stats to be reset, but got %+v", stats)
+	}
+	db.Close()
+}
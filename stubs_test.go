@@ -25,8 +25,8 @@ func (ni *NullInt) Scan(value interface{}) (err error) {
 	}
 
 	switch v := value.(type) {
-	case int, int8, int16, int32, int64:
-		ni.Integer, ni.Valid = v.(int), true
+	case int64:
+		ni.Integer, ni.Valid = int(v), true
 		return
 	case []byte:
 		ni.Integer, err = strconv.Atoi(string(v))
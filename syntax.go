@@ -0,0 +1,276 @@
+package sqlmock
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SQLDialect defines the syntax statements are validated
+// against, when valid SQL is required by RequireValidSQL
+type SQLDialect int
+
+const (
+	// AnyDialect accepts the syntax of any of the dialects
+	AnyDialect SQLDialect = iota + 1
+	// MySQLDialect is the syntax of MySQL and MariaDB
+	MySQLDialect
+	// PostgresDialect is the syntax of PostgreSQL
+	PostgresDialect
+	// SQLiteDialect is the syntax of SQLite
+	SQLiteDialect
+	// SQLServerDialect is the syntax of Microsoft SQL Server
+	SQLServerDialect
+)
+
+func (d SQLDialect) String() string {
+	switch d {
+	case MySQLDialect:
+		return "MySQL"
+	case PostgresDialect:
+		return "PostgreSQL"
+	case SQLiteDialect:
+		return "SQLite"
+	case SQLServerDialect:
+		return "SQL Server"
+	}
+	return "SQL"
+}
+
+// words statements may start with
+var statementWords = map[string]bool{
+	"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true, "WITH": true,
+	"REPLACE": true, "MERGE": true, "UPSERT": true, "VALUES": true, "TABLE": true,
+	"CREATE": true, "ALTER": true, "DROP": true, "TRUNCATE": true, "RENAME": true,
+	"COMMENT": true, "GRANT": true, "REVOKE": true, "LOCK": true, "UNLOCK": true,
+	"BEGIN": true, "START": true, "COMMIT": true, "ROLLBACK": true, "END": true,
+	"SAVEPOINT": true, "RELEASE": true, "SET": true, "SHOW": true, "USE": true,
+	"EXPLAIN": true, "DESCRIBE": true, "DESC": true, "ANALYZE": true, "VACUUM": true,
+	"CALL": true, "EXEC": true, "EXECUTE": true, "PREPARE": true, "DEALLOCATE": true,
+	"DECLARE": true, "DO": true, "COPY": true, "LOAD": true, "PRAGMA": true,
+	"ATTACH": true, "DETACH": true, "REINDEX": true, "REFRESH": true, "CLUSTER": true,
+	"LISTEN": true, "NOTIFY": true, "UNLISTEN": true, "RESET": true, "DISCARD": true,
+	"OPTIMIZE": true, "CHECKPOINT": true, "IF": true, "PRINT": true, "RAISERROR": true,
+	"THROW": true, "WAITFOR": true,
+}
+
+// words which start a clause, they may not follow
+// where an expression or a name is still expected
+var clauseStarts = map[string]bool{
+	"FROM": true, "WHERE": true, "AND": true, "OR": true, "GROUP": true,
+	"ORDER": true, "HAVING": true, "LIMIT": true, "OFFSET": true, "SET": true,
+	"VALUES": true, "JOIN": true, "UNION": true, "INTERSECT": true, "EXCEPT": true,
+	"RETURNING": true,
+}
+
+// words after which an expression or a name is expected
+var operandExpected = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "AND": true, "OR": true,
+	"BY": true, "HAVING": true, "SET": true, "ON": true, "JOIN": true,
+	"INTO": true, "LIMIT": true, "OFFSET": true, "NOT": true,
+}
+
+// constructs of a single dialect, or of several
+var dialectConstructs = []struct {
+	words    []string
+	dialects []SQLDialect
+}{
+	{[]string{"ON", "DUPLICATE", "KEY"}, []SQLDialect{MySQLDialect}},
+	{[]string{"ON", "CONFLICT"}, []SQLDialect{PostgresDialect, SQLiteDialect}},
+	{[]string{"ILIKE"}, []SQLDialect{PostgresDialect}},
+	{[]string{"LIMIT"}, []SQLDialect{MySQLDialect, PostgresDialect, SQLiteDialect}},
+	{[]string{"SELECT", "TOP"}, []SQLDialect{SQLServerDialect}},
+	{[]string{"OUTPUT", "INSERTED"}, []SQLDialect{SQLServerDialect}},
+	{[]string{"RETURNING"}, []SQLDialect{PostgresDialect, SQLiteDialect, MySQLDialect}},
+}
+
+// validates the syntax of the statement, nothing is validated unless
+// a dialect is set. It is not a complete grammar, but catches mistakes
+// statements built of strings are prone to
+func (d SQLDialect) validate(query string) error {
+	if d == 0 {
+		return nil
+	}
+	err := d.validateQuoting(query, d == MySQLDialect)
+	if err != nil && d == AnyDialect {
+		err = d.validateQuoting(query, true)
+	}
+	if err != nil {
+		return fmt.Errorf("statement '%s' is not valid %s, %s", query, d, err)
+	}
+	for _, stmt := range statements(tokenize(query)) {
+		if err := d.validateTokens(stmt); err != nil {
+			return fmt.Errorf("statement '%s' is not valid %s, %s", query, d, err)
+		}
+	}
+	return nil
+}
+
+// checks that literals, quoted identifiers and comments are terminated
+// and quoted and placeholders the way the dialect quotes them. Whether
+// backslashes escape quotes of strings, like they do in MySQL, is given
+func (d SQLDialect) validateQuoting(q string, backslash bool) error {
+	for i := 0; i < len(q); i++ {
+		switch c := q[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			if c == '`' && (d == PostgresDialect || d == SQLServerDialect) {
+				return fmt.Errorf("identifiers are not quoted with backticks")
+			}
+			if i = skipLiteral(q, i, backslash && c != '`'); i == len(q) {
+				return fmt.Errorf("quote %c is not terminated", c)
+			}
+		case c == '[' && (d == SQLServerDialect || d == SQLiteDialect):
+			if i = skipQuoted(q, i, ']'); i == len(q) {
+				return fmt.Errorf("bracket [ is not terminated")
+			}
+		case c == '-' && strings.HasPrefix(q[i:], "--"):
+			if end := strings.IndexByte(q[i:], '\n'); end != -1 {
+				i += end
+			} else {
+				i = len(q)
+			}
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			end := strings.Index(q[i+2:], "*/")
+			if end == -1 {
+				return fmt.Errorf("comment /* is not terminated")
+			}
+			i += end + 3
+		case c == '$' && i+1 < len(q) && isDigit(q[i+1]) && (d == MySQLDialect || d == SQLServerDialect):
+			return fmt.Errorf("placeholders are not numbered with $")
+		}
+	}
+	return nil
+}
+
+// skips the literal or quoted identifier starting at i,
+// backslashes escape quotes of postgres E'...' strings as well
+func skipLiteral(q string, i int, backslash bool) int {
+	quote := q[i]
+	if !backslash && (quote != '\'' || i == 0 || q[i-1] != 'E' && q[i-1] != 'e' || i > 1 && isLetter(q[i-2])) {
+		return skipQuoted(q, i, quote)
+	}
+	for j := i + 1; j < len(q); j++ {
+		switch q[j] {
+		case '\\':
+			j++
+		case quote:
+			if j+1 < len(q) && q[j+1] == quote {
+				j++
+				continue
+			}
+			return j
+		}
+	}
+	return len(q)
+}
+
+// checks the structure of a single statement
+func (d SQLDialect) validateTokens(ts []token) error {
+	if first := ts[0]; first.kind != 'p' || first.text != "(" && first.text != "{" {
+		if first.kind != 'w' || !statementWords[strings.ToUpper(first.text)] {
+			return fmt.Errorf("it does not start with a statement, but with '%s'", first.text)
+		}
+	}
+
+	depth := 0
+	for i, t := range ts {
+		switch {
+		case t.isPunct("("):
+			depth++
+		case t.isPunct(")"):
+			if depth--; depth < 0 {
+				return fmt.Errorf("parenthesis ) at '%s' is not opened", join(ts[:i+1]))
+			}
+		}
+
+		expecting := t.isPunct(",") || t.isPunct("(") || t.isPunct("=") ||
+			t.kind == 'w' && operandExpected[strings.ToUpper(t.text)]
+		if !expecting {
+			continue
+		}
+		if i+1 == len(ts) {
+			return fmt.Errorf("it ends with '%s'", t.text)
+		}
+		next := ts[i+1]
+		if next.isPunct(",") || next.isPunct(")") && !t.isPunct("(") ||
+			next.kind == 'w' && clauseStarts[strings.ToUpper(next.text)] && !t.isPunct("=") {
+			return fmt.Errorf("'%s' follows '%s' at '%s'", next.text, t.text, join(ts[:i+2]))
+		}
+	}
+	if depth > 0 {
+		return fmt.Errorf("%d parentheses are not closed", depth)
+	}
+
+	if err := insertedValues(ts); err != nil {
+		return err
+	}
+	if d == AnyDialect {
+		return nil
+	}
+	for _, c := range dialectConstructs {
+		if find(ts, c.words...) != -1 && !hasDialect(c.dialects, d) {
+			return fmt.Errorf("%s is not supported", strings.Join(c.words, " "))
+		}
+	}
+	return nil
+}
+
+// checks the number of values inserted by every row matches the columns
+func insertedValues(ts []token) error {
+	i := find(ts, "INTO")
+	if i == -1 || !ts[0].is("INSERT") && !ts[0].is("REPLACE") {
+		return nil
+	}
+	_, i = qualifiedName(ts, i+1)
+	if i >= len(ts) || !ts[i].isPunct("(") {
+		return nil
+	}
+	columns := len(items(ts, i))
+	for i = closing(ts, i) + 1; i < len(ts) && (ts[i].is("VALUES") || ts[i].isPunct(",")); i = closing(ts, i) + 1 {
+		if i++; i >= len(ts) || !ts[i].isPunct("(") {
+			break
+		}
+		if values := len(items(ts, i)); values != columns {
+			return fmt.Errorf("%d values are inserted into %d columns", values, columns)
+		}
+	}
+	return nil
+}
+
+// the index of the parenthesis closing the one opened at i
+func closing(ts []token, i int) int {
+	depth := 0
+	for ; i < len(ts); i++ {
+		switch {
+		case ts[i].isPunct("("):
+			depth++
+		case ts[i].isPunct(")"):
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(ts)
+}
+
+// the index of the first occurrence of the words in sequence, or -1
+func find(ts []token, words ...string) int {
+next:
+	for i := 0; i+len(words) <= len(ts); i++ {
+		for j, w := range words {
+			if !ts[i+j].is(w) {
+				continue next
+			}
+		}
+		return i
+	}
+	return -1
+}
+
+func hasDialect(dialects []SQLDialect, d SQLDialect) bool {
+	for _, other := range dialects {
+		if other == d {
+			return true
+		}
+	}
+	return false
+}
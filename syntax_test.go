@@ -0,0 +1,109 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestValidSQL(t *testing.T) {
+	valid := map[SQLDialect][]string{
+		AnyDialect: {
+			"SELECT id, name FROM users WHERE id = ? AND status IN (1, 2) ORDER BY name",
+			"SELECT COUNT(*), NOW() FROM users u LEFT JOIN orders o ON o.user_id = u.id GROUP BY u.id",
+			"INSERT INTO users (name, email) VALUES (?, ?), ('it''s', lower(?))",
+			"UPDATE users SET name = ?, email = ? WHERE id = ?",
+			"SELECT * FROM users WHERE deleted_at IS NOT NULL FOR UPDATE",
+			"WITH ids AS (SELECT id FROM users) DELETE FROM orders WHERE user_id IN (SELECT id FROM ids)",
+			"CREATE TABLE IF NOT EXISTS users (id INT NOT NULL, PRIMARY KEY (id));",
+			"BEGIN; UPDATE users SET name = 'a\\'b' WHERE id = 1; COMMIT",
+			"{call refresh_stats(?)}",
+		},
+		MySQLDialect: {
+			"INSERT INTO `users` (`name`) VALUES (?) ON DUPLICATE KEY UPDATE name = VALUES(name)",
+			"SELECT * FROM users WHERE name = 'O\\'Brien' LIMIT 10",
+		},
+		PostgresDialect: {
+			`INSERT INTO "users" ("name") VALUES ($1) ON CONFLICT (name) DO UPDATE SET name = $1 RETURNING id`,
+			"SELECT * FROM users WHERE name ILIKE $1 AND note = E'it\\'s' LIMIT 10",
+		},
+		SQLServerDialect: {
+			"SELECT TOP 10 [id] FROM [dbo].[users] WHERE [name] = @p1",
+			"INSERT INTO users (name) OUTPUT INSERTED.id VALUES (@name)",
+		},
+	}
+	for dialect, queries := range valid {
+		for _, q := range queries {
+			if err := dialect.validate(q); err != nil {
+				t.Errorf("error '%s' was not expected for valid %s", err, dialect)
+			}
+		}
+	}
+
+	invalid := []struct {
+		dialect SQLDialect
+		query   string
+		err     string
+	}{
+		{AnyDialect, "SELECT id, FROM users", "'FROM' follows ','"},
+		{AnyDialect, "UPDATE users SET name = ?, WHERE id = ?", "'WHERE' follows ','"},
+		{AnyDialect, "SELECT * FROM users WHERE AND id = ?", "'AND' follows 'WHERE'"},
+		{AnyDialect, "SELECT * FROM users WHERE id = ? AND", "it ends with 'AND'"},
+		{AnyDialect, "SELECT * FROM users WHERE id IN (1, 2,)", "')' follows ','"},
+		{AnyDialect, "SELECT * FROM users WHERE (id = ?", "1 parentheses are not closed"},
+		{AnyDialect, "SELECT * FROM users WHERE id = ?)", "parenthesis ) at"},
+		{AnyDialect, "SELECT * FROM users WHERE name = 'gedi", "quote ' is not terminated"},
+		{AnyDialect, "SELECT * /* all FROM users", "comment /* is not terminated"},
+		{AnyDialect, "SELCT * FROM users", "it does not start with a statement, but with 'SELCT'"},
+		{AnyDialect, "INSERT INTO users (name, email) VALUES (?, ?), (?)", "1 values are inserted into 2 columns"},
+		{MySQLDialect, "SELECT * FROM users WHERE id = $1", "placeholders are not numbered with $"},
+		{MySQLDialect, "INSERT INTO users (name) VALUES (?) ON CONFLICT DO NOTHING", "ON CONFLICT is not supported"},
+		{PostgresDialect, "SELECT `id` FROM users", "identifiers are not quoted with backticks"},
+		{SQLServerDialect, "SELECT * FROM users LIMIT 10", "LIMIT is not supported"},
+		{SQLiteDialect, "SELECT * FROM [users", "bracket [ is not terminated"},
+	}
+	for _, c := range invalid {
+		err := c.dialect.validate(c.query)
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("expected an error containing '%s' for '%s' as %s, but got: %v", c.err, c.query, c.dialect, err)
+		}
+	}
+
+	if err := SQLDialect(0).validate("SELCT"); err != nil {
+		t.Errorf("error '%s' was not expected, when valid SQL is not required", err)
+	}
+}
+
+func TestRequireValidSQL(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	RequireValidSQL(PostgresDialect)
+	ExpectExec("UPDATE users SET").WillReturnRowsAffected(1)
+	ExpectExec("UPDATE users SET").WillReturnRowsAffected(1)
+
+	if _, err = db.Exec("UPDATE users SET name = $1 WHERE id = $2", "gedi", 1); err != nil {
+		t.Errorf("error '%s' was not expected, the statement is valid", err)
+	}
+	if _, err = db.Exec("UPDATE users SET name = $1, WHERE id = $2", "gedi", 1); err == nil {
+		t.Errorf("expected an error, since the statement is not valid, though the regex matches")
+	}
+
+	if err = db.Close(); err == nil || !strings.Contains(err.Error(), "is not valid PostgreSQL, 'WHERE' follows ','") {
+		t.Errorf("expected the violation to be reported on close, but got: %v", err)
+	}
+
+	db, err = sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	ExpectExec("SELCT").WillReturnRowsAffected(0)
+	if _, err = db.Exec("SELCT 1"); err != nil {
+		t.Errorf("error '%s' was not expected, since closing the connection cleared the policy", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
@@ -0,0 +1,68 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Params are the values a Template is instantiated with, by name
+type Params map[string]driver.Value
+
+// Template declares an interaction script, whose args and rows
+// depend on params, so that table driven tests may instantiate
+// the same script with different inputs:
+//
+//	var cancelOrder = sqlmock.NewTemplate(sqlmock.Params{"id": 1, "status": 0},
+//		func(s *sqlmock.ExpectationSet, p sqlmock.Params) {
+//			s.ExpectQuery("SELECT (.+) FROM orders").WithArgs(p.Args("id")...).
+//				WillReturnRows(p.Row("id", "status"))
+//			s.ExpectExec("UPDATE orders").WithArgs(p.Args("id")...).
+//				WillReturnResult(sqlmock.NewResult(0, 1))
+//		})
+//
+//	sqlmock.Apply(cancelOrder(sqlmock.Params{"status": 2}))
+type Template func(p Params) *ExpectationSet
+
+// NewTemplate creates a template, which declares its expectations
+// on a new set with the given params, or with the defaults for
+// params which are not given
+func NewTemplate(defaults Params, declare func(s *ExpectationSet, p Params)) Template {
+	return func(p Params) *ExpectationSet {
+		merged := make(Params, len(defaults)+len(p))
+		for name, v := range defaults {
+			merged[name] = v
+		}
+		for name, v := range p {
+			merged[name] = v
+		}
+		s := NewExpectationSet()
+		declare(s, merged)
+		return s
+	}
+}
+
+// Value returns the value of the param with the given name,
+// panics if there is none, since the template is mistaken
+func (p Params) Value(name string) driver.Value {
+	v, ok := p[name]
+	if !ok {
+		panic(fmt.Sprintf("there is no template param named %s", name))
+	}
+	return v
+}
+
+// Args returns the values of the named params in order,
+// to be expected with WithArgs
+func (p Params) Args(names ...string) []driver.Value {
+	args := make([]driver.Value, len(names))
+	for i, name := range names {
+		args[i] = p.Value(name)
+	}
+	return args
+}
+
+// Row returns rows of the given columns, with a single
+// row of the values of the params named like the columns
+func (p Params) Row(columns ...string) Rows {
+	return NewRows(columns).AddRow(p.Args(columns...)...)
+}
@@ -0,0 +1,64 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	lookup := NewTemplate(Params{"id": 1, "status": "new"}, func(s *ExpectationSet, p Params) {
+		s.ExpectQuery("SELECT (.+) FROM orders WHERE id = ?").
+			WithArgs(p.Args("id")...).
+			WillReturnRows(p.Row("id", "status"))
+	})
+
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	cases := []struct {
+		params Params
+		id     int
+		status string
+	}{
+		{nil, 1, "new"},
+		{Params{"status": "shipped"}, 1, "shipped"},
+		{Params{"id": 2, "status": "cancelled"}, 2, "cancelled"},
+	}
+	for _, c := range cases {
+		Apply(lookup(c.params))
+
+		var id int
+		var status string
+		if err = db.QueryRow("SELECT id, status FROM orders WHERE id = ?", c.id).Scan(&id, &status); err != nil {
+			t.Errorf("error '%s' was not expected while looking up the order with params %v", err, c.params)
+			continue
+		}
+		if id != c.id || status != c.status {
+			t.Errorf("expected order %d to be %s, but got %d with %s", c.id, c.status, id, status)
+		}
+	}
+
+	// params given to an instance do not change the defaults
+	Apply(lookup(nil))
+	if _, err = db.Query("SELECT id, status FROM orders WHERE id = ?", 2); err == nil {
+		t.Error("expected an error, since the default id is 1")
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
+
+func TestTemplateUnknownParam(t *testing.T) {
+	defer func() {
+		if r := recover(); r == nil {
+			t.Error("expected a panic, since there is no such param")
+		}
+	}()
+	tmpl := NewTemplate(Params{"id": 1}, func(s *ExpectationSet, p Params) {
+		s.ExpectExec("DELETE FROM orders").WithArgs(p.Args("order_id")...)
+	})
+	tmpl(nil)
+}
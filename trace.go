@@ -0,0 +1,52 @@
+package sqlmock
+
+import (
+	"database/sql/driver"
+	"time"
+)
+
+// Span describes a driver call made on a mock, shaped like the span
+// a tracing middleware, like the one of OpenTelemetry, creates around
+// it. Allows to test such middleware without a real database
+type Span struct {
+	Name      string         // of the operation, like sql.query or sql.commit
+	Statement string         // the query as given, if any
+	Args      []driver.Value // of the query
+	Start     time.Time
+	End       time.Time
+	Err       error // returned by the call, if any
+}
+
+// TraceOption makes the mock report every driver call made
+// on it to the tracer, once the call returns. Calls are reported
+// whether they matched expectations or not
+func TraceOption(tracer func(Span)) Option {
+	return func(m *MockDB) {
+		m.conn.tracer = tracer
+	}
+}
+
+// counts the driver call and starts a span of it, the returned function
+// ends it with the error and the result of the call and records it in
+// the history. It must be deferred before the mock is locked, so that
+// the tracer is called without the lock
+func (s *session) trace(name, query string, args []driver.Value) func(err *error, result interface{}) {
+	start := time.Now()
+	s.mu.Lock()
+	s.calls++
+	s.stack = nil
+	if s.stackDepth > 0 {
+		s.stack = callStack(s.stackDepth)
+	}
+	stack := s.stack
+	s.mu.Unlock()
+	return func(err *error, result interface{}) {
+		s.record(name, query, args, result, *err, stack)
+		s.mu.Lock()
+		tracer := s.tracer
+		s.mu.Unlock()
+		if tracer != nil {
+			tracer(Span{Name: name, Statement: query, Args: args, Start: start, End: time.Now(), Err: *err})
+		}
+	}
+}
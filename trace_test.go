@@ -0,0 +1,57 @@
+package sqlmock
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestTracedDriverCalls(t *testing.T) {
+	var mu sync.Mutex
+	var spans []Span
+	db, m, err := NewMock(TraceOption(func(s Span) {
+		mu.Lock()
+		defer mu.Unlock()
+		spans = append(spans, s)
+	}))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectBegin()
+	m.ExpectExec("UPDATE articles").WillReturnError(fmt.Errorf("deadlock"))
+	m.ExpectRollback()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while beginning a transaction", err)
+	}
+	if _, err = tx.Exec("UPDATE articles SET title = ?", "hello"); err == nil {
+		t.Errorf("expected an error of the exec")
+	}
+	if err = tx.Rollback(); err != nil {
+		t.Errorf("error '%s' was not expected while rolling back a transaction", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	var names []string
+	for _, s := range spans {
+		names = append(names, s.Name)
+		if s.End.Before(s.Start) {
+			t.Errorf("expected span %s to end after it started", s.Name)
+		}
+	}
+	// the database is pinged as it is opened
+	if fmt.Sprint(names) != "[sql.ping sql.begin sql.exec sql.rollback]" {
+		t.Fatalf("expected spans of ping, begin, exec and rollback, but got %v", names)
+	}
+	exec := spans[2]
+	if exec.Statement != "UPDATE articles SET title = ?" || len(exec.Args) != 1 || exec.Args[0] != "hello" {
+		t.Errorf("expected the span of the exec to describe its query, but got %+v", exec)
+	}
+	if exec.Err == nil || exec.Err.Error() != "deadlock" {
+		t.Errorf("expected the span of the exec to carry its error, but got %v", exec.Err)
+	}
+	db.Close()
+}
@@ -5,33 +5,103 @@ import (
 )
 
 type transaction struct {
-	conn *conn
+	conn  *session
+	begun string   // where the transaction was begun
+	ctx   canceler // the transaction was begun with, if any
+	done  bool
 }
 
-func (tx *transaction) Commit() error {
-	e := tx.conn.next()
+func (tx *transaction) Commit() (err error) {
+	defer tx.conn.trace("sql.commit", "", nil)(&err, nil)
+	tx.conn.wait()
+	tx.conn.lock()
+	defer tx.conn.mu.Unlock()
+
+	call := "call to commit transaction"
+	tx.done = true // database/sql ends the transaction even if it fails
+	if tx.conn.permissive != nil {
+		return nil
+	}
+	if err := tx.conn.checkInteraction(call); err != nil {
+		return err
+	}
+
+	if err := tx.conn.interrupt(call); err != nil {
+		return err
+	}
+
+	e := tx.conn.next(ofType(&expectedCommit{}))
 	if e == nil {
-		return fmt.Errorf("all expectations were already fulfilled, call to commit transaction was not expected")
+		return tx.conn.unexpected("call to commit transaction")
 	}
 
 	etc, ok := e.(*expectedCommit)
 	if !ok {
 		return fmt.Errorf("call to commit transaction, was not expected, next expectation was %v", e)
 	}
-	etc.triggered = true
-	return etc.err
+	trigger(etc, call)
+	if err := tx.conn.delay(etc, tx.ctx); err != nil {
+		return err
+	}
+	if etc.err != nil {
+		return tx.conn.badConn(etc.err)
+	}
+	return tx.conn.badConn(tx.conn.chaos.fail())
 }
 
-func (tx *transaction) Rollback() error {
-	e := tx.conn.next()
+func (tx *transaction) Rollback() (err error) {
+	defer tx.conn.trace("sql.rollback", "", nil)(&err, nil)
+	tx.conn.wait()
+	tx.conn.lock()
+	defer tx.conn.mu.Unlock()
+
+	call := "call to rollback transaction"
+	tx.done = true // database/sql ends the transaction even if it fails
+	if tx.conn.permissive != nil {
+		return nil
+	}
+	if err := tx.conn.checkInteraction(call); err != nil {
+		return err
+	}
+
+	if err := tx.conn.interrupt(call); err != nil {
+		return err
+	}
+
+	e := tx.conn.next(ofType(&expectedRollback{}))
 	if e == nil {
-		return fmt.Errorf("all expectations were already fulfilled, call to rollback transaction was not expected")
+		return tx.conn.unexpected("call to rollback transaction")
 	}
 
 	etr, ok := e.(*expectedRollback)
 	if !ok {
 		return fmt.Errorf("call to rollback transaction, was not expected, next expectation was %v", e)
 	}
-	etr.triggered = true
-	return etr.err
+	trigger(etr, call)
+	if err := tx.conn.delay(etr, tx.ctx); err != nil {
+		return err
+	}
+	if etr.err != nil {
+		return tx.conn.badConn(etr.err)
+	}
+	return tx.conn.badConn(tx.conn.chaos.fail())
+}
+
+// whether a transaction is open on the connection
+func (s *session) inTransaction() bool {
+	for _, tx := range s.transactions {
+		if tx.conn == s && !tx.done {
+			return true
+		}
+	}
+	return false
+}
+
+// fails the call matched by e, if e must be matched within
+// a transaction, but the call was made outside of one
+func checkTransaction(e expectation, s *session, call string) error {
+	if e.common().inTx && !s.inTransaction() {
+		return fmt.Errorf("%s was made outside of a transaction, but %s must be matched within one", call, e)
+	}
+	return nil
 }
@@ -0,0 +1,62 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestStatementRebindingWithinTransaction(t *testing.T) {
+	db, m, err := NewMock()
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	m.ExpectBegin()
+	m.ExpectPrepare()
+	m.ExpectTxPrepare()
+	m.ExpectExec("UPDATE stock").InTransaction().WillReturnRowsAffected(1)
+	m.ExpectExec("UPDATE stock").InTransaction().WillReturnRowsAffected(1)
+	m.ExpectCommit()
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while beginning a transaction", err)
+	}
+	// prepared on another connection, since the transaction holds its own
+	stmt, err := db.Prepare("UPDATE stock SET qty = qty - 1 WHERE id = ?")
+	if err != nil {
+		t.Fatalf("error '%s' was not expected while preparing a statement", err)
+	}
+	if _, err = tx.Stmt(stmt).Exec(1); err != nil {
+		t.Errorf("error '%s' was not expected, the statement was bound to the transaction", err)
+	}
+	_, err = stmt.Exec(2)
+	if err == nil || !strings.Contains(err.Error(), "was made outside of a transaction, but exec 'UPDATE stock' must be matched within one") {
+		t.Errorf("expected an error, since the statement was not bound to the transaction, but got: %v", err)
+	}
+	if err = tx.Commit(); err != nil {
+		t.Errorf("error '%s' was not expected while committing", err)
+	}
+	if err = m.ExpectationsWereMet(); err != nil {
+		t.Errorf("there were unfulfilled expectations: %s", err)
+	}
+	db.Close()
+}
+
+func TestTxPrepareOutsideOfTransaction(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectTxPrepare()
+	_, err = db.Prepare("SELECT id FROM users")
+	if err == nil || err.Error() != "call to prepare 'SELECT id FROM users' was made outside of a transaction, but prepare statement within a transaction must be matched within one" {
+		t.Errorf("expected an error, since the statement was prepared outside of a transaction, but got: %v", err)
+	}
+
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+}
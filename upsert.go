@@ -0,0 +1,125 @@
+package sqlmock
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// the expected conflict handling of an upsert, parts
+// which are not set match any statement handling conflicts
+type upsert struct {
+	target, set       []string
+	hasTarget, hasSet bool
+}
+
+// the conflict handling of a statement, parsed from ON CONFLICT ...
+// DO UPDATE SET or DO NOTHING of postgres and sqlite, or from ON
+// DUPLICATE KEY UPDATE of mysql, which has no conflict target
+type conflictHandling struct {
+	target    []string // columns, or the name of the constraint
+	hasTarget bool
+	set       []string // updated columns, none if nothing is done
+}
+
+// checks the conflict handling of the statement, an upsert
+// which is not set is met by any statement
+func (u *upsert) check(query string) error {
+	if u == nil {
+		return nil
+	}
+	h := parseConflictHandling(tokenize(query))
+	if h == nil {
+		return fmt.Errorf("it does not handle conflicts, but ON CONFLICT or ON DUPLICATE KEY UPDATE was expected")
+	}
+	if u.hasTarget {
+		if !h.hasTarget {
+			return fmt.Errorf("it has no conflict target, but %v was expected", u.target)
+		}
+		if !sameNames(h.target, u.target) {
+			return fmt.Errorf("conflict target %v does not match expected %v", h.target, u.target)
+		}
+	}
+	if u.hasSet && !sameNames(h.set, u.set) {
+		return fmt.Errorf("update set %v does not match expected %v", h.set, u.set)
+	}
+	return nil
+}
+
+// parses the conflict handling of the statement, nil if it has none
+func parseConflictHandling(ts []token) *conflictHandling {
+	if i := find(ts, "ON", "DUPLICATE", "KEY", "UPDATE"); i != -1 {
+		return &conflictHandling{set: assignedColumns(ts, i+4)}
+	}
+	i := find(ts, "ON", "CONFLICT")
+	if i == -1 {
+		return nil
+	}
+	h := &conflictHandling{}
+	switch i += 2; {
+	case i < len(ts) && ts[i].isPunct("("):
+		h.hasTarget = true
+		for _, item := range items(ts, i) {
+			if name, next := qualifiedName(item, 0); next == len(item) {
+				h.target = append(h.target, name)
+			} else {
+				h.target = append(h.target, join(item)) // an expression
+			}
+		}
+	case find(ts[i:], "ON", "CONSTRAINT") == 0 && i+2 < len(ts):
+		h.hasTarget = true
+		h.target = []string{strings.ToLower(ts[i+2].text)}
+	}
+	if j := find(ts[i:], "DO", "UPDATE", "SET"); j != -1 {
+		h.set = assignedColumns(ts, i+j+3)
+	}
+	return h
+}
+
+// the columns assigned by the SET list starting at i
+func assignedColumns(ts []token, i int) (columns []string) {
+	end := i
+	for depth := 0; end < len(ts); end++ {
+		t := ts[end]
+		if t.isPunct("(") {
+			depth++
+		} else if t.isPunct(")") {
+			depth--
+		}
+		if depth == 0 && (t.is("WHERE") || t.is("RETURNING") || t.isPunct(";")) {
+			break
+		}
+	}
+	for _, assignment := range split(ts[i:end]) {
+		if assignment[0].isPunct("(") { // (a, b) = (...)
+			for _, item := range items(assignment, 0) {
+				name, _ := qualifiedName(item, 0)
+				columns = append(columns, name)
+			}
+			continue
+		}
+		name, _ := qualifiedName(assignment, 0)
+		columns = append(columns, name)
+	}
+	return columns
+}
+
+// whether the names are the same regardless of order and case
+func sameNames(names, expected []string) bool {
+	if len(names) != len(expected) {
+		return false
+	}
+	a := make([]string, len(names))
+	b := make([]string, len(expected))
+	for i := range names {
+		a[i], b[i] = strings.ToLower(names[i]), strings.ToLower(expected[i])
+	}
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,63 @@
+package sqlmock
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+)
+
+func TestUpsertCheck(t *testing.T) {
+	cases := []struct {
+		u     upsert
+		query string
+		err   string
+	}{
+		{upsert{target: []string{"email"}, hasTarget: true}, `INSERT INTO users (email) VALUES ($1) ON CONFLICT ("email") DO NOTHING`, ""},
+		{upsert{target: []string{"tenant_id", "email"}, hasTarget: true}, "INSERT INTO users VALUES (?) ON CONFLICT (email, tenant_id) WHERE deleted_at IS NULL DO UPDATE SET name = excluded.name", ""},
+		{upsert{target: []string{"users_email_key"}, hasTarget: true}, "INSERT INTO users VALUES (?) ON CONFLICT ON CONSTRAINT users_email_key DO NOTHING", ""},
+		{upsert{target: []string{"email"}, hasTarget: true}, "INSERT INTO users VALUES (?) ON CONFLICT (lower(email)) DO NOTHING", "conflict target [lower ( email )] does not match expected [email]"},
+		{upsert{target: []string{"email"}, hasTarget: true}, "INSERT INTO users VALUES (?) ON DUPLICATE KEY UPDATE name = VALUES(name)", "it has no conflict target, but [email] was expected"},
+		{upsert{set: []string{"updated_at", "name"}, hasSet: true}, "INSERT INTO users VALUES (?) ON DUPLICATE KEY UPDATE `name` = VALUES(name), updated_at = NOW()", ""},
+		{upsert{set: []string{"name", "updated_at"}, hasSet: true}, "INSERT INTO users VALUES (?) ON CONFLICT (id) DO UPDATE SET name = excluded.name, updated_at = coalesce(excluded.updated_at, now()) RETURNING id", ""},
+		{upsert{set: []string{"name"}, hasSet: true}, "INSERT INTO users VALUES (?) ON CONFLICT (id) DO UPDATE SET name = excluded.name, email = excluded.email", "update set [name email] does not match expected [name]"},
+		{upsert{set: []string{}, hasSet: true}, "INSERT INTO users VALUES (?) ON CONFLICT DO NOTHING", ""},
+		{upsert{set: []string{"name"}, hasSet: true}, "INSERT INTO users VALUES (?)", "it does not handle conflicts"},
+	}
+	for _, c := range cases {
+		err := c.u.check(c.query)
+		if c.err == "" && err != nil {
+			t.Errorf("error '%s' was not expected for '%s'", err, c.query)
+		}
+		if c.err != "" && (err == nil || !strings.Contains(err.Error(), c.err)) {
+			t.Errorf("expected an error containing '%s' for '%s', but got: %v", c.err, c.query, err)
+		}
+	}
+}
+
+func TestExpectUpsert(t *testing.T) {
+	db, err := sql.Open("mock", "")
+	if err != nil {
+		t.Errorf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+
+	ExpectExec("INSERT INTO users").WithConflictTarget("email").WithUpdateSet("name").WillReturnRowsAffected(1)
+	ExpectExec("INSERT INTO users").WithConflictTarget("email").WithUpdateSet("name").WillReturnRowsAffected(1)
+
+	if _, err = db.Exec("INSERT INTO users (email, name) VALUES (?, ?) ON CONFLICT (email) DO UPDATE SET name = excluded.name", "a@b.c", "gedi"); err != nil {
+		t.Errorf("error '%s' was not expected while upserting", err)
+	}
+	_, err = db.Exec("INSERT INTO users (email, name) VALUES (?, ?) ON CONFLICT (email) DO NOTHING", "a@b.c", "gedi")
+	if err == nil || !strings.Contains(err.Error(), "update set [] does not match expected [name]") {
+		t.Errorf("expected an error, since nothing is updated on a conflict, but got: %v", err)
+	}
+	if err = db.Close(); err != nil {
+		t.Errorf("error '%s' was not expected while closing the database", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected a panic, since a begin is not an upsert")
+		}
+	}()
+	ExpectBegin().WithUpdateSet("name")
+}
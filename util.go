@@ -1,10 +1,34 @@
 package sqlmock
 
 import (
+	"fmt"
+	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
 )
 
+// PlaceholderStyle defines which kinds of statement placeholders
+// are counted when database/sql asks for the number of inputs of
+// a prepared statement. Styles may be combined
+type PlaceholderStyle int
+
+const (
+	// PermissivePlaceholders does not count placeholders,
+	// so statements accept any number of arguments
+	PermissivePlaceholders PlaceholderStyle = 0
+	// QuestionPlaceholders counts every ? placeholder
+	QuestionPlaceholders PlaceholderStyle = 1 << iota
+	// DollarPlaceholders counts $1, $2 ... placeholders by the highest ordinal
+	DollarPlaceholders
+	// ColonPlaceholders counts distinct :name or :1 placeholders
+	ColonPlaceholders
+	// AtPlaceholders counts distinct @name placeholders
+	AtPlaceholders
+	// AnyPlaceholders counts ?, $n and :name placeholders
+	AnyPlaceholders = QuestionPlaceholders | DollarPlaceholders | ColonPlaceholders
+)
+
 var re *regexp.Regexp
 
 func init() {
@@ -15,3 +39,135 @@ func init() {
 func stripQuery(q string) (s string) {
 	return strings.TrimSpace(re.ReplaceAllString(q, " "))
 }
+
+// builds a regex which matches the invocation of a stored
+// procedure in CALL, EXEC, ODBC escape and PL/SQL block syntax
+func procedureRegex(name string) *regexp.Regexp {
+	return regexp.MustCompile(`(?i)^(\{\s*(\?\s*=\s*)?call|call|exec|execute|begin)\s+(@\w+\s*=\s*)?` + regexp.QuoteMeta(name) + `\s*(\(|;|\}|$|\s)`)
+}
+
+// counts placeholders of the given style in query, skipping
+// string literals, quoted identifiers and comments.
+// Returns -1 if placeholders are not counted
+func countPlaceholders(q string, style PlaceholderStyle) int {
+	if style == PermissivePlaceholders {
+		return -1
+	}
+	positional, named := scanPlaceholders(q, style)
+	return positional + len(named)
+}
+
+// scans query for placeholders of the given style, returns the number
+// of positional ones and the distinct named ones, like :name or @name
+func scanPlaceholders(q string, style PlaceholderStyle) (positional int, named map[string]bool) {
+	var question, dollar int
+	named = make(map[string]bool)
+	for i := 0; i < len(q); i++ {
+		switch c := q[i]; {
+		case c == '\'' || c == '"' || c == '`':
+			i = skipQuoted(q, i, c)
+		case c == '[' && style&AtPlaceholders != 0:
+			i = skipQuoted(q, i, ']')
+		case c == '-' && strings.HasPrefix(q[i:], "--"):
+			if end := strings.IndexByte(q[i:], '\n'); end != -1 {
+				i += end
+			} else {
+				i = len(q)
+			}
+		case c == '/' && strings.HasPrefix(q[i:], "/*"):
+			if end := strings.Index(q[i+2:], "*/"); end != -1 {
+				i += end + 3
+			} else {
+				i = len(q)
+			}
+		case c == '?' && style&QuestionPlaceholders != 0:
+			question++
+		case c == '$' && i+1 < len(q) && isDigit(q[i+1]):
+			n, j := 0, i+1
+			for ; j < len(q) && isDigit(q[j]); j++ {
+				n = n*10 + int(q[j]-'0')
+			}
+			if style&DollarPlaceholders != 0 && n > dollar {
+				dollar = n
+			}
+			i = j - 1
+		case c == '$':
+			// dollar quoted string, like $$text$$ or $tag$text$tag$
+			if end := strings.IndexByte(q[i+1:], '$'); end != -1 && isIdent(q[i+1:i+1+end]) {
+				tag := q[i : i+end+2]
+				if close := strings.Index(q[i+len(tag):], tag); close != -1 {
+					i += len(tag) + close + len(tag) - 1
+				} else {
+					i = len(q)
+				}
+			}
+		case c == ':' && i+1 < len(q) && q[i+1] == ':':
+			i++ // type cast
+		case (c == ':' && style&ColonPlaceholders != 0) || (c == '@' && style&AtPlaceholders != 0):
+			j := i + 1
+			for ; j < len(q) && (isDigit(q[j]) || isLetter(q[j])); j++ {
+			}
+			if j > i+1 {
+				named[q[i:j]] = true
+			}
+			i = j - 1
+		}
+	}
+	return question + dollar, named
+}
+
+// returns the position of the closing quote of a
+// quoted string or identifier starting at i
+func skipQuoted(q string, i int, quote byte) int {
+	for j := i + 1; j < len(q); j++ {
+		if q[j] == quote {
+			if j+1 < len(q) && q[j+1] == quote && quote != ']' {
+				j++ // escaped quote
+				continue
+			}
+			return j
+		}
+	}
+	return len(q)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_'
+}
+
+func isIdent(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !isLetter(s[i]) && !(i > 0 && isDigit(s[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// directory of the package sources
+var packageDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return filepath.Dir(file)
+}()
+
+// file and line of the first caller outside of the package
+// sources and database/sql, like an expectation declaration
+func callSite() string {
+	for skip := 2; ; skip++ {
+		_, file, line, ok := runtime.Caller(skip)
+		if !ok {
+			return "unknown location"
+		}
+		if strings.HasPrefix(file, packageDir+"/") && !strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		if strings.Contains(file, "/database/sql/") {
+			continue
+		}
+		return fmt.Sprintf("%s:%d", filepath.Base(file), line)
+	}
+}
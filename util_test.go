@@ -19,3 +19,51 @@ func TestQueryStringStripping(t *testing.T) {
 `, "SELECT c FROM D")
 	assert("UPDATE  (.+) SET  ", "UPDATE (.+) SET")
 }
+
+func TestProcedureRegex(t *testing.T) {
+	re := procedureRegex("get_user")
+	for _, q := range []string{
+		"CALL get_user(?)",
+		"call get_user()",
+		"EXEC get_user @id = 1",
+		"EXECUTE get_user",
+		"EXEC @ret = get_user 1",
+		"{call get_user(?)}",
+		"{? = call get_user(?)}",
+		"BEGIN get_user(:1); END;",
+	} {
+		if !re.MatchString(q) {
+			t.Errorf("expected '%s' to match a call to get_user", q)
+		}
+	}
+
+	for _, q := range []string{
+		"CALL get_users(?)",
+		"SELECT get_user(?)",
+		"CALL other(get_user)",
+	} {
+		if re.MatchString(q) {
+			t.Errorf("expected '%s' not to match a call to get_user", q)
+		}
+	}
+}
+
+func TestPlaceholderCounting(t *testing.T) {
+	assert := func(query string, style PlaceholderStyle, expected int) {
+		if n := countPlaceholders(query, style); n != expected {
+			t.Errorf("expected %d placeholders in '%s', but got %d", expected, query, n)
+		}
+	}
+
+	assert("SELECT * FROM users WHERE id = ? AND name = ?", AnyPlaceholders, 2)
+	assert("SELECT * FROM users WHERE id = $1 OR parent_id = $1 AND name = $2", AnyPlaceholders, 2)
+	assert("SELECT * FROM users WHERE id = :id OR parent_id = :id AND name = :name", AnyPlaceholders, 2)
+	assert("SELECT * FROM users WHERE name = '?' AND note = 'it''s ?' AND id = ?", AnyPlaceholders, 1)
+	assert(`SELECT "what?", `+"`col?`"+` FROM t -- really?
+	WHERE id = ? /* or ? */`, AnyPlaceholders, 1)
+	assert("SELECT created::date, '10:30' FROM t WHERE id = :1", AnyPlaceholders, 1)
+	assert("SELECT $$it costs $1$$, $tag$ ? $tag$ FROM t WHERE id = $1", AnyPlaceholders, 1)
+	assert("SELECT * FROM t WHERE id = @id AND [col@x] = @name", AtPlaceholders, 2)
+	assert("SELECT data ?| array['a'] FROM t WHERE id = $1", DollarPlaceholders, 1)
+	assert("SELECT * FROM t WHERE id = ?", PermissivePlaceholders, -1)
+}
@@ -0,0 +1,113 @@
+package sqlmock
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+)
+
+// Verbosity defines how much context failure messages of
+// calls, which do not match the expectations, include
+type Verbosity int
+
+const (
+	// NormalOutput prints the call with all of its args and
+	// a preview of the query with the args interpolated
+	NormalOutput Verbosity = iota
+	// TerseOutput cuts long args and long lists of them and leaves
+	// the preview out, so that very large fixtures, like documents
+	// or batches of values, do not make failures unreadable
+	TerseOutput
+	// VerboseOutput prints what NormalOutput does, followed by the
+	// listing of the expectations, the rows the query was expected
+	// to return and the most recent calls made on the mock
+	VerboseOutput
+)
+
+const (
+	terseArgs      = 3  // printed, the rest are counted
+	terseArgLength = 32 // of a printed arg, longer ones are cut
+	recentCalls    = 10 // printed by VerboseOutput
+)
+
+// SetVerbosity sets how much context failure messages of calls, which
+// do not match the expectations, include. TerseOutput suits tests with
+// very large fixtures, VerboseOutput debugging a failure
+func SetVerbosity(v Verbosity) {
+	mock.SetVerbosity(v)
+}
+
+// SetVerbosity sets how much context failure messages include
+func (m *MockDB) SetVerbosity(v Verbosity) {
+	m.conn.mu.Lock()
+	defer m.conn.mu.Unlock()
+	m.conn.verbosity = v
+}
+
+// prints the args of a call for a failure message
+func (c *conn) printArgs(args []driver.Value) string {
+	if c.verbosity != TerseOutput {
+		return fmt.Sprintf("%+v", args)
+	}
+	var buf bytes.Buffer
+	buf.WriteString("[")
+	for i, arg := range args {
+		if i == terseArgs {
+			fmt.Fprintf(&buf, " and %d more", len(args)-i)
+			break
+		}
+		if i > 0 {
+			buf.WriteString(" ")
+		}
+		s := fmt.Sprintf("%+v", arg)
+		if len(s) > terseArgLength {
+			s = s[:terseArgLength] + "..."
+		}
+		buf.WriteString(s)
+	}
+	buf.WriteString("]")
+	return buf.String()
+}
+
+// the context VerboseOutput adds to the failure message of a call,
+// e is the expectation the call was compared with, if any
+func (c *conn) details(e expectation) string {
+	if c.verbosity != VerboseOutput {
+		if stack := c.printStack(); stack != "" {
+			return "\n" + stack
+		}
+		return ""
+	}
+	var buf bytes.Buffer
+	buf.WriteString("\n\n")
+	buf.WriteString(c.listing())
+
+	if eq, ok := e.(*expectedQuery); ok {
+		if rs, ok := eq.rows.(*rows); ok {
+			fmt.Fprintf(&buf, "\n  ROWS of %s\n  %v\n", eq, rs.cols)
+			for _, row := range rs.rows {
+				fmt.Fprintf(&buf, "  %+v\n", row)
+			}
+		}
+	}
+
+	history := c.history
+	if len(history) > recentCalls {
+		history = history[len(history)-recentCalls:]
+	}
+	if len(history) > 0 {
+		buf.WriteString("\n  RECENT CALLS, the most recent last\n")
+		for _, call := range history {
+			fmt.Fprintf(&buf, "  %s", call.Call)
+			if call.Query != "" {
+				fmt.Fprintf(&buf, " '%s' with args %+v", call.Query, call.Args)
+			}
+			if call.Error != "" {
+				fmt.Fprintf(&buf, ", failed with: %s", call.Error)
+			}
+			buf.WriteString("\n")
+		}
+	}
+	buf.WriteString(c.printStack())
+	return buf.String()
+}
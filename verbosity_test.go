@@ -0,0 +1,55 @@
+package sqlmock
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTerseOutput(t *testing.T) {
+	db, m, err := NewMock(VerbosityOption(TerseOutput))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	m.ExpectExec("INSERT INTO documents").WithArgs(1).WillReturnRowsAffected(1)
+
+	doc := strings.Repeat("x", 1000)
+	_, err = db.Exec("INSERT INTO documents (id, body, a, b) VALUES (?, ?, ?, ?)", 2, doc, 3, 4)
+	expected := "exec query 'INSERT INTO documents (id, body, a, b) VALUES (?, ?, ?, ?)', args [2 " + strings.Repeat("x", 32) + "... 3 and 1 more] does not match expected [1]"
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected the args to be cut and the preview left out, but got: %v", err)
+	}
+	m.Reset()
+	db.Close()
+}
+
+func TestVerboseOutput(t *testing.T) {
+	db, m, err := NewMock(VerbosityOption(VerboseOutput))
+	if err != nil {
+		t.Fatalf("an error '%s' was not expected when opening a stub database connection", err)
+	}
+	m.ExpectExec("UPDATE users").WillReturnRowsAffected(1)
+	m.ExpectQuery("SELECT (.+) FROM users").WithArgs(1).
+		WillReturnRows(NewRows([]string{"id", "name"}).AddRow(1, "gedi"))
+
+	if _, err = db.Exec("UPDATE users SET name = ?", "gedi"); err != nil {
+		t.Errorf("error '%s' was not expected while updating users", err)
+	}
+	_, err = db.Query("SELECT id, name FROM users WHERE id = ?", 2)
+	if err == nil {
+		t.Fatal("expected an error for a query, which does not match the expected args")
+	}
+	for _, part := range []string{
+		"args [2] (previewed with args interpolated as 'SELECT id, name FROM users WHERE id = 2') does not match expected [1]",
+		"FULFILLED",
+		"exec 'UPDATE users', matched once",
+		"ROWS of query 'SELECT (.+) FROM users' with args [1]\n  [id name]\n  [1 gedi]\n",
+		"RECENT CALLS, the most recent last\n",
+		"\n  sql.exec 'UPDATE users SET name = ?' with args [gedi]\n",
+	} {
+		if !strings.Contains(err.Error(), part) {
+			t.Errorf("expected the error to contain '%s', but got:\n%s", part, err)
+		}
+	}
+	m.Reset()
+	db.Close()
+}